@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"minichain/blockchain"
+	"minichain/crypto"
+	"minichain/i18n"
+	"minichain/utils"
+	"os"
+	"time"
+)
+
+// BenchResult son las medidas de rendimiento que se comparan entre versiones
+type BenchResult struct {
+	Blocks          int     `json:"blocks"`
+	TxsPerBlock     int     `json:"txsPerBlock"`
+	ImportBlocksSec float64 `json:"importBlocksPerSec"` // Más es mejor
+	StateRootMs     float64 `json:"stateRootMs"`        // Menos es mejor
+}
+
+// runBench mide la importación de bloques y el cálculo del StateRoot
+//
+// Con --baseline compara contra un resultado guardado antes (con --save) y falla
+// si alguna medida empeora más de --tolerance por ciento.
+func runBench(args []string) error {
+	flags := flag.NewFlagSet("bench", flag.ExitOnError)
+	blocks := flags.Int("blocks", 100, "bloques que se importan")
+	txsPerBlock := flags.Int("txs", 100, "transacciones por bloque")
+	baseline := flags.String("baseline", "", "resultado anterior contra el que comparar")
+	save := flags.String("save", "", "guardar el resultado en este archivo")
+	tolerance := flags.Float64("tolerance", 10, "empeoramiento máximo permitido (%)")
+	lang := addLangFlag(flags)
+	flags.Parse(args)
+	if err := i18n.SetLanguage(*lang); err != nil {
+		return err
+	}
+
+	fmt.Printf("⏱️  Preparando %d bloques de %d transacciones...\n", *blocks, *txsPerBlock)
+
+	var result *BenchResult
+	var err error
+	quiet(func() {
+		result, err = benchImport(*blocks, *txsPerBlock)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n📦 Importación: %.1f bloques/s\n", result.ImportBlocksSec)
+	fmt.Printf("🌳 StateRoot:   %.3f ms\n", result.StateRootMs)
+
+	if *save != "" {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		if err := os.WriteFile(*save, data, 0644); err != nil {
+			return fmt.Errorf("no se pudo guardar %s: %v", *save, err)
+		}
+		fmt.Printf("💾 Resultado guardado en %s\n", *save)
+	}
+
+	if *baseline != "" {
+		return compareBench(result, *baseline, *tolerance)
+	}
+
+	return nil
+}
+
+// benchImport mina una cadena de prueba y mide cuánto tarda otra en importarla
+func benchImport(blocks, txsPerBlock int) (*BenchResult, error) {
+	genesis, mined, err := benchFixture(blocks, txsPerBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	target, err := importBlocks(genesis, mined)
+	if err != nil {
+		return nil, err
+	}
+	importTime := time.Since(start)
+
+	const rootRuns = 1000 // Cada cálculo es de décimas de ms: con pocas vueltas domina el ruido
+	start = time.Now()
+	for i := 0; i < rootRuns; i++ {
+		target.StateRoot()
+	}
+	rootTime := time.Since(start) / rootRuns
+
+	return &BenchResult{
+		Blocks:          blocks,
+		TxsPerBlock:     txsPerBlock,
+		ImportBlocksSec: float64(blocks) / importTime.Seconds(),
+		StateRootMs:     float64(rootTime.Microseconds()) / 1000,
+	}, nil
+}
+
+// benchFixture mina blocks bloques de txsPerBlock transferencias sobre un
+// génesis nuevo y devuelve el génesis y los bloques (sin el #0)
+func benchFixture(blocks, txsPerBlock int) (*blockchain.Genesis, []*blockchain.Block, error) {
+	// Un remitente por transacción del bloque, con saldo de sobra
+	senders := make([]*crypto.KeyPair, txsPerBlock)
+	for i := range senders {
+		keyPair, err := crypto.GenerateKeyPair()
+		if err != nil {
+			return nil, nil, err
+		}
+		senders[i] = keyPair
+	}
+
+	// Las dos cadenas salen del mismo génesis, así que tienen el mismo bloque #0
+	genesis := &blockchain.Genesis{
+		ChainID:    blockchain.DefaultChainID,
+		Difficulty: 1,
+		Timestamp:  time.Now().Unix(),
+		Alloc:      make(map[string]blockchain.GenesisAccount),
+	}
+	for _, keyPair := range senders {
+		genesis.Alloc[keyPair.GetAddress()] = blockchain.GenesisAccount{Balance: utils.MTC(1000000)}
+	}
+
+	source, err := blockchain.NewBlockchainFromGenesis(genesis)
+	if err != nil {
+		return nil, nil, err
+	}
+	source.MinerThreads = 1
+	source.Coinbase = senders[0].GetAddress()
+
+	recipient := "00000000000000000000000000000000000be7c4"
+	for b := 0; b < blocks; b++ {
+		txs := make([]*blockchain.Transaction, len(senders))
+		for i, keyPair := range senders {
+			address := keyPair.GetAddress()
+			txs[i] = blockchain.NewTransaction(address, recipient, utils.MTC(1), source.PendingNonce(address))
+			if err := txs[i].Sign(keyPair, source.ChainID); err != nil {
+				return nil, nil, err
+			}
+		}
+		for _, err := range source.AddTransactions(txs) {
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		source.MineBlock()
+	}
+
+	return genesis, source.Blocks[1:], nil
+}
+
+// importBlocks crea una cadena desde el génesis e importa los bloques en orden
+func importBlocks(genesis *blockchain.Genesis, blocks []*blockchain.Block) (*blockchain.Blockchain, error) {
+	target, err := blockchain.NewBlockchainFromGenesis(genesis)
+	if err != nil {
+		return nil, err
+	}
+	for _, block := range blocks {
+		if err := target.ImportBlock(block); err != nil {
+			return nil, fmt.Errorf("importando bloque %d: %v", block.Index, err)
+		}
+	}
+	return target, nil
+}
+
+// compareBench falla si el resultado es peor que la referencia más allá de la tolerancia
+func compareBench(result *BenchResult, path string, tolerance float64) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("no se pudo leer %s: %v", path, err)
+	}
+
+	var base BenchResult
+	if err := json.Unmarshal(data, &base); err != nil {
+		return fmt.Errorf("referencia inválida en %s: %v", path, err)
+	}
+
+	if base.Blocks != result.Blocks || base.TxsPerBlock != result.TxsPerBlock {
+		return fmt.Errorf("la referencia usa %d bloques de %d transacciones: no es comparable",
+			base.Blocks, base.TxsPerBlock)
+	}
+
+	importChange := (base.ImportBlocksSec - result.ImportBlocksSec) / base.ImportBlocksSec * 100
+	rootChange := (result.StateRootMs - base.StateRootMs) / base.StateRootMs * 100
+
+	fmt.Printf("\n📊 Frente a %s: importación %+.1f%%, StateRoot %+.1f%%\n", path, -importChange, rootChange)
+
+	if importChange > tolerance {
+		return fmt.Errorf("regresión: la importación es un %.1f%% más lenta (máximo %.0f%%)", importChange, tolerance)
+	}
+	if rootChange > tolerance {
+		return fmt.Errorf("regresión: el StateRoot es un %.1f%% más lento (máximo %.0f%%)", rootChange, tolerance)
+	}
+
+	fmt.Println("✅ Sin regresiones")
+	return nil
+}
+
+// quiet ejecuta fn sin la salida por consola de la blockchain
+func quiet(fn func()) {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		fn()
+		return
+	}
+	defer devNull.Close()
+
+	stdout := os.Stdout
+	os.Stdout = devNull
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+}
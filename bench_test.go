@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"testing"
+
+	"minichain/blockchain"
+)
+
+// syncBlocks es el tamaño de la cadena de BenchmarkInitialSync
+// La cadena se mina al empezar en lugar de leerla de un archivo guardado: con
+// dificultad 1 y una transacción por bloque son un par de segundos
+var syncBlocks = flag.Int("sync.blocks", 10000, "bloques de la cadena que importa BenchmarkInitialSync")
+
+// fixtures guarda las cadenas ya minadas: go test repite cada benchmark con
+// b.N crecientes y minarlas es mucho más lento que lo que se mide
+var fixtures = map[[2]int]struct {
+	genesis *blockchain.Genesis
+	blocks  []*blockchain.Block
+}{}
+
+// fixture devuelve (minándola la primera vez) una cadena de blocks bloques
+// con txsPerBlock transferencias cada uno
+func fixture(b *testing.B, blocks, txsPerBlock int) (*blockchain.Genesis, []*blockchain.Block) {
+	b.Helper()
+	key := [2]int{blocks, txsPerBlock}
+	if cached, ok := fixtures[key]; ok {
+		return cached.genesis, cached.blocks
+	}
+
+	var genesis *blockchain.Genesis
+	var mined []*blockchain.Block
+	var err error
+	quiet(func() {
+		genesis, mined, err = benchFixture(blocks, txsPerBlock)
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	fixtures[key] = struct {
+		genesis *blockchain.Genesis
+		blocks  []*blockchain.Block
+	}{genesis, mined}
+	return genesis, mined
+}
+
+// benchmarkImport importa la cadena entera en cada iteración y da bloques/s
+func benchmarkImport(b *testing.B, blocks, txsPerBlock int) {
+	genesis, mined := fixture(b, blocks, txsPerBlock)
+
+	b.ResetTimer()
+	quiet(func() {
+		for i := 0; i < b.N; i++ {
+			if _, err := importBlocks(genesis, mined); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.ReportMetric(float64(blocks*b.N)/b.Elapsed().Seconds(), "blocks/s")
+}
+
+// BenchmarkImportBlocks: importación de bloques de 100 transacciones
+func BenchmarkImportBlocks(b *testing.B) {
+	benchmarkImport(b, 10, 100)
+}
+
+// BenchmarkInitialSync: sincronización inicial de una cadena larga (ver -sync.blocks)
+func BenchmarkInitialSync(b *testing.B) {
+	benchmarkImport(b, *syncBlocks, 1)
+}
+
+// BenchmarkStateRoot: cálculo del StateRoot tras la importación
+func BenchmarkStateRoot(b *testing.B) {
+	genesis, mined := fixture(b, 10, 100)
+	var target *blockchain.Blockchain
+	var err error
+	quiet(func() {
+		target, err = importBlocks(genesis, mined)
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		target.StateRoot()
+	}
+}
+
+// Los bloques del fixture son válidos: si no, los benchmarks medirían rechazos
+func TestBenchFixtureImports(t *testing.T) {
+	var err error
+	quiet(func() {
+		var genesis *blockchain.Genesis
+		var mined []*blockchain.Block
+		if genesis, mined, err = benchFixture(2, 3); err == nil {
+			_, err = importBlocks(genesis, mined)
+		}
+	})
+	if err != nil {
+		t.Fatalf("fixture: %v", err)
+	}
+}
@@ -2,24 +2,32 @@ package blockchain
 
 import (
 	"fmt"
+	"math/big"
+	"minichain/utils"
 )
 
 // Account representa una cuenta con saldo
+//
+// Las cantidades van en unidades base (ver utils.Decimals). Los *big.Int del
+// estado nunca se modifican en sitio: cada cambio guarda un valor nuevo, así
+// que los snapshots pueden compartirlos sin copiarlos.
 type Account struct {
-	Address string  // Dirección de la cuenta
-	Balance float64 // Saldo en la cuenta
-	Nonce   int     // Contador de transacciones (previene replay attacks)
+	Address string   // Dirección de la cuenta
+	Balance *big.Int // Saldo en la cuenta
+	Nonce   int      // Contador de transacciones (previene replay attacks)
 }
 
 // AccountState mantiene el estado global de todas las cuentas
 type AccountState struct {
 	Accounts map[string]*Account // address -> Account
+	Stakes   map[string]*big.Int // address -> unidades bloqueadas en stake (PoS)
 }
 
 // NewAccountState crea un nuevo estado de cuentas vacío
 func NewAccountState() *AccountState {
 	return &AccountState{
 		Accounts: make(map[string]*Account),
+		Stakes:   make(map[string]*big.Int),
 	}
 }
 
@@ -30,7 +38,7 @@ func (as *AccountState) GetAccount(address string) *Account {
 		// Crear cuenta nueva con saldo 0
 		account = &Account{
 			Address: address,
-			Balance: 0,
+			Balance: new(big.Int),
 			Nonce:   0,
 		}
 		as.Accounts[address] = account
@@ -38,24 +46,25 @@ func (as *AccountState) GetAccount(address string) *Account {
 	return account
 }
 
-// GetBalance obtiene el saldo de una cuenta
-func (as *AccountState) GetBalance(address string) float64 {
-	return as.GetAccount(address).Balance
+// GetBalance obtiene el saldo de una cuenta (una copia: se puede modificar)
+func (as *AccountState) GetBalance(address string) *big.Int {
+	return new(big.Int).Set(as.GetAccount(address).Balance)
 }
 
 // AddBalance añade saldo a una cuenta
-func (as *AccountState) AddBalance(address string, amount float64) {
+func (as *AccountState) AddBalance(address string, amount *big.Int) {
 	account := as.GetAccount(address)
-	account.Balance += amount
+	account.Balance = new(big.Int).Add(account.Balance, amount)
 }
 
 // SubtractBalance resta saldo de una cuenta
-func (as *AccountState) SubtractBalance(address string, amount float64) error {
+func (as *AccountState) SubtractBalance(address string, amount *big.Int) error {
 	account := as.GetAccount(address)
-	if account.Balance < amount {
-		return fmt.Errorf("saldo insuficiente: tiene %.2f, necesita %.2f", account.Balance, amount)
+	if account.Balance.Cmp(amount) < 0 {
+		return fmt.Errorf("saldo insuficiente: tiene %s, necesita %s",
+			utils.FormatMTC(account.Balance), utils.FormatMTC(amount))
 	}
-	account.Balance -= amount
+	account.Balance = new(big.Int).Sub(account.Balance, amount)
 	return nil
 }
 
@@ -68,12 +77,18 @@ func (as *AccountState) IncrementNonce(address string) {
 // StateSnapshot guarda un snapshot del estado de cuentas
 type StateSnapshot struct {
 	Accounts map[string]*Account
+	Stakes   map[string]*big.Int
 }
 
 // CreateSnapshot crea un snapshot del estado actual
 func (as *AccountState) CreateSnapshot() *StateSnapshot {
 	snapshot := &StateSnapshot{
 		Accounts: make(map[string]*Account),
+		Stakes:   make(map[string]*big.Int),
+	}
+
+	for address, stake := range as.Stakes {
+		snapshot.Stakes[address] = stake
 	}
 
 	// Copiar todas las cuentas
@@ -90,7 +105,14 @@ func (as *AccountState) CreateSnapshot() *StateSnapshot {
 
 // RevertToSnapshot revierte el estado a un snapshot
 func (as *AccountState) RevertToSnapshot(snapshot *StateSnapshot) {
-	// Restaurar cuentas
+	// Restaurar stakes
+	as.Stakes = make(map[string]*big.Int)
+	for address, stake := range snapshot.Stakes {
+		as.Stakes[address] = stake
+	}
+
+	// Restaurar cuentas (las creadas después del snapshot desaparecen)
+	as.Accounts = make(map[string]*Account)
 	for address, account := range snapshot.Accounts {
 		as.Accounts[address] = &Account{
 			Address: account.Address,
@@ -113,7 +135,10 @@ func (as *AccountState) Print() {
 
 	for address, account := range as.Accounts {
 		fmt.Printf("\n📍 %s\n", address)
-		fmt.Printf("   💰 Saldo: %.2f MTC\n", account.Balance)
+		fmt.Printf("   💰 Saldo: %s MTC\n", utils.FormatMTC(account.Balance))
 		fmt.Printf("   🔢 Nonce: %d\n", account.Nonce)
+		if stake := as.GetStake(address); stake.Sign() > 0 {
+			fmt.Printf("   🔒 Stake: %s MTC\n", utils.FormatMTC(stake))
+		}
 	}
 }
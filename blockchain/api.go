@@ -0,0 +1,262 @@
+package blockchain
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"strconv"
+)
+
+// APISchemaVersion identifica la forma del JSON de bloques y transacciones
+// Hay que incrementarlo al renombrar o cambiar el tipo de cualquier campo
+const APISchemaVersion = "2"
+
+// TransactionJSON es la representación estable de una transacción en la API
+// Los números que pueden crecer (nonce, gas, índices, cantidades) van en
+// hexadecimal "0x..."; las cantidades, en unidades base (1 MTC = 10^18)
+type TransactionJSON struct {
+	Hash            string                       `json:"hash"`
+	ChainID         string                       `json:"chainId"`
+	From            string                       `json:"from"`
+	To              string                       `json:"to"`
+	Amount          string                       `json:"amount"`
+	Nonce           string                       `json:"nonce"`
+	Data            string                       `json:"data"`
+	GasPrice        string                       `json:"gasPrice"`
+	GasTipCap       string                       `json:"maxPriorityFeePerGas,omitempty"`
+	GasLimit        string                       `json:"gasLimit"`
+	Signature       string                       `json:"signature"`
+	PublicKeyX      string                       `json:"publicKeyX,omitempty"`
+	PublicKeyY      string                       `json:"publicKeyY,omitempty"`
+	Batch           []*TransferAuthorizationJSON `json:"batch,omitempty"`
+	Multisig        *MultisigJSON                `json:"multisig,omitempty"`
+	ContractAddress string                       `json:"contractAddress,omitempty"`
+	GasUsed         string                       `json:"gasUsed"`
+	Fee             string                       `json:"fee"`
+	Burned          string                       `json:"burned"`
+}
+
+// TransferAuthorizationJSON es la representación de una autorización de un lote
+type TransferAuthorizationJSON struct {
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Amount     string `json:"amount"`
+	Nonce      string `json:"nonce"`
+	ChainID    string `json:"chainId"`
+	Signature  string `json:"signature"`
+	PublicKeyX string `json:"publicKeyX,omitempty"`
+	PublicKeyY string `json:"publicKeyY,omitempty"`
+}
+
+// MultisigJSON es la configuración y las firmas de una transacción multifirma
+type MultisigJSON struct {
+	Threshold  string                   `json:"threshold"`
+	Owners     []string                 `json:"owners"`
+	Signatures []*MultisigSignatureJSON `json:"signatures"`
+}
+
+// MultisigSignatureJSON es la firma de un titular de una multifirma
+type MultisigSignatureJSON struct {
+	PublicKeyX string `json:"publicKeyX"`
+	PublicKeyY string `json:"publicKeyY"`
+	Signature  string `json:"signature"`
+}
+
+// BlockJSON es la representación estable de un bloque en la API
+type BlockJSON struct {
+	Number       string             `json:"number"`
+	Timestamp    string             `json:"timestamp"` // Segundos Unix en hex
+	Hash         string             `json:"hash"`
+	ParentHash   string             `json:"parentHash"`
+	Nonce        string             `json:"nonce"`
+	Bits         string             `json:"bits"`
+	TxRoot       string             `json:"transactionsRoot"`
+	StateRoot    string             `json:"stateRoot"`
+	ReceiptRoot  string             `json:"receiptsRoot"`
+	GasUsed      string             `json:"gasUsed"`
+	TotalFees    string             `json:"totalFees"`
+	TotalRefunds string             `json:"totalRefunds"`
+	BaseFee      string             `json:"baseFeePerGas,omitempty"`
+	TotalBurned  string             `json:"totalBurned"`
+	Transactions []*TransactionJSON `json:"transactions"`
+}
+
+// ReceiptJSON es la representación estable de un recibo en la API
+type ReceiptJSON struct {
+	TransactionHash   string     `json:"transactionHash"`
+	BlockHash         string     `json:"blockHash"`
+	BlockNumber       string     `json:"blockNumber"`
+	TransactionIndex  string     `json:"transactionIndex"`
+	Status            string     `json:"status"` // "0x1" éxito, "0x0" fallida
+	GasUsed           string     `json:"gasUsed"`
+	CumulativeGasUsed string     `json:"cumulativeGasUsed"`
+	ContractAddress   string     `json:"contractAddress,omitempty"`
+	RevertReason      string     `json:"revertReason,omitempty"`
+	ReturnData        string     `json:"returnData,omitempty"`
+	Logs              []*LogJSON `json:"logs"`
+}
+
+// LogJSON es la representación de un evento de contrato en la API
+type LogJSON struct {
+	Address          string   `json:"address"`
+	Topics           []string `json:"topics"`
+	Data             string   `json:"data"`
+	BlockNumber      string   `json:"blockNumber"`
+	BlockHash        string   `json:"blockHash"`
+	TransactionHash  string   `json:"transactionHash"`
+	TransactionIndex string   `json:"transactionIndex"`
+	LogIndex         string   `json:"logIndex"`
+}
+
+// toHex codifica un número en el formato "0x..." de la API
+func toHex(n uint64) string {
+	return "0x" + strconv.FormatUint(n, 16)
+}
+
+// bigToHex codifica un big.Int en hexadecimal ("" si es nil)
+func bigToHex(n *big.Int) string {
+	if n == nil {
+		return ""
+	}
+	return "0x" + n.Text(16)
+}
+
+// amountToHex codifica una cantidad en hexadecimal ("0x0" si es nil)
+func amountToHex(n *big.Int) string {
+	if n == nil {
+		return "0x0"
+	}
+	return bigToHex(n)
+}
+
+// ToAPI convierte la transacción a su representación de la API
+func (tx *Transaction) ToAPI() *TransactionJSON {
+	out := &TransactionJSON{
+		Hash:            tx.Hash(),
+		ChainID:         toHex(tx.ChainID),
+		From:            tx.From,
+		To:              tx.To,
+		Amount:          amountToHex(tx.Amount),
+		Nonce:           toHex(uint64(tx.Nonce)),
+		Data:            "0x" + hex.EncodeToString(tx.Data),
+		GasPrice:        amountToHex(tx.GasPrice),
+		GasTipCap:       bigToHex(tx.GasTipCap),
+		GasLimit:        toHex(tx.GasLimit),
+		Signature:       tx.Signature,
+		PublicKeyX:      bigToHex(tx.PublicKeyX),
+		PublicKeyY:      bigToHex(tx.PublicKeyY),
+		ContractAddress: tx.ContractAddress,
+		GasUsed:         toHex(tx.GasUsed),
+		Fee:             amountToHex(tx.Fee),
+		Burned:          amountToHex(tx.Burned),
+	}
+
+	for _, auth := range tx.Batch {
+		out.Batch = append(out.Batch, &TransferAuthorizationJSON{
+			From:       auth.From,
+			To:         auth.To,
+			Amount:     amountToHex(auth.Amount),
+			Nonce:      toHex(uint64(auth.Nonce)),
+			ChainID:    toHex(auth.ChainID),
+			Signature:  auth.Signature,
+			PublicKeyX: bigToHex(auth.PublicKeyX),
+			PublicKeyY: bigToHex(auth.PublicKeyY),
+		})
+	}
+
+	if tx.IsMultisig() {
+		out.Multisig = &MultisigJSON{
+			Threshold:  toHex(uint64(tx.Multisig.Threshold)),
+			Owners:     tx.Multisig.Owners,
+			Signatures: []*MultisigSignatureJSON{},
+		}
+		for _, sig := range tx.Multisig.Signatures {
+			out.Multisig.Signatures = append(out.Multisig.Signatures, &MultisigSignatureJSON{
+				PublicKeyX: bigToHex(sig.PublicKeyX),
+				PublicKeyY: bigToHex(sig.PublicKeyY),
+				Signature:  sig.Signature,
+			})
+		}
+	}
+
+	return out
+}
+
+// ToAPI convierte el bloque a su representación de la API
+func (b *Block) ToAPI() *BlockJSON {
+	out := &BlockJSON{
+		Number:       toHex(uint64(b.Index)),
+		Timestamp:    toHex(uint64(b.Timestamp.Unix())),
+		Hash:         b.Hash,
+		ParentHash:   b.PreviousHash,
+		Nonce:        toHex(uint64(b.Nonce)),
+		Bits:         toHex(uint64(b.Bits)),
+		TxRoot:       b.TxRoot,
+		StateRoot:    b.StateRoot,
+		ReceiptRoot:  b.ReceiptRoot,
+		GasUsed:      toHex(b.GasUsed),
+		TotalFees:    amountToHex(b.TotalFees),
+		TotalRefunds: amountToHex(b.TotalRefunds),
+		BaseFee:      bigToHex(b.BaseFee),
+		TotalBurned:  amountToHex(b.TotalBurned),
+		Transactions: []*TransactionJSON{},
+	}
+
+	for _, tx := range b.Transactions {
+		out.Transactions = append(out.Transactions, tx.ToAPI())
+	}
+
+	return out
+}
+
+// ToJSON convierte el bloque a JSON con el esquema estable de la API
+func (b *Block) ToJSON() (string, error) {
+	data, err := json.Marshal(b.ToAPI())
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ToAPI convierte el recibo a su representación de la API
+func (r *Receipt) ToAPI() *ReceiptJSON {
+	out := &ReceiptJSON{
+		TransactionHash:   r.TxHash,
+		BlockHash:         r.BlockHash,
+		BlockNumber:       toHex(uint64(r.BlockNumber)),
+		TransactionIndex:  toHex(uint64(r.TxIndex)),
+		Status:            toHex(r.Status),
+		GasUsed:           toHex(r.GasUsed),
+		CumulativeGasUsed: toHex(r.CumulativeGasUsed),
+		ContractAddress:   r.ContractAddress,
+		RevertReason:      r.RevertReason,
+		Logs:              []*LogJSON{},
+	}
+	if r.ReturnData != nil {
+		out.ReturnData = "0x" + hex.EncodeToString(r.ReturnData)
+	}
+
+	for _, log := range r.Logs {
+		out.Logs = append(out.Logs, log.ToAPI())
+	}
+
+	return out
+}
+
+// ToAPI convierte el log a su representación de la API
+func (l *Log) ToAPI() *LogJSON {
+	topics := l.Topics
+	if topics == nil {
+		topics = []string{}
+	}
+	return &LogJSON{
+		Address:          l.Address,
+		Topics:           topics,
+		Data:             "0x" + hex.EncodeToString(l.Data),
+		BlockNumber:      toHex(uint64(l.BlockNumber)),
+		BlockHash:        l.BlockHash,
+		TransactionHash:  l.TxHash,
+		TransactionIndex: toHex(uint64(l.TxIndex)),
+		LogIndex:         toHex(uint64(l.Index)),
+	}
+}
@@ -0,0 +1,68 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Parámetros del base fee (como EIP-1559)
+const (
+	InitialBaseFee           = 100_000_000_000 // Base fee del primer bloque: 10^11 unidades por gas
+	baseFeeChangeDenominator = 8               // Cambia como mucho un 12,5% por bloque
+	elasticityMultiplier     = 2               // Objetivo de gas: la mitad del límite del bloque
+)
+
+// CalcBaseFee calcula el base fee del bloque que sigue a parent
+//
+// El base fee es el precio mínimo por gas de un bloque y se quema: no lo cobra
+// nadie. Sube si parent gastó más gas que el objetivo y baja si gastó menos, así
+// que el precio se ajusta solo a la demanda. Devuelve nil si el cambio no está
+// activo en ese bloque.
+func (bc *Blockchain) CalcBaseFee(parent *Block) *big.Int {
+	if !bc.Config.IsBaseFee(parent.Index + 1) {
+		return nil
+	}
+	if parent.BaseFee == nil {
+		return big.NewInt(InitialBaseFee) // Primer bloque con base fee
+	}
+
+	target := bc.GasLimit / elasticityMultiplier
+	if parent.GasUsed == target || target == 0 {
+		return new(big.Int).Set(parent.BaseFee)
+	}
+
+	// delta = base fee × |gas usado - objetivo| / objetivo / 8
+	var diff uint64
+	if parent.GasUsed > target {
+		diff = parent.GasUsed - target
+	} else {
+		diff = target - parent.GasUsed
+	}
+	delta := new(big.Int).Mul(parent.BaseFee, new(big.Int).SetUint64(diff))
+	delta.Div(delta, new(big.Int).SetUint64(target))
+	delta.Div(delta, big.NewInt(baseFeeChangeDenominator))
+
+	if parent.GasUsed > target {
+		if delta.Sign() == 0 {
+			delta.SetInt64(1) // Con bloques llenos siempre sube
+		}
+		return delta.Add(parent.BaseFee, delta)
+	}
+	return delta.Sub(parent.BaseFee, delta)
+}
+
+// BaseFee devuelve el base fee del próximo bloque (nil si el cambio no está activo)
+func (bc *Blockchain) BaseFee() *big.Int {
+	return bc.CalcBaseFee(bc.Blocks[len(bc.Blocks)-1])
+}
+
+// checkBaseFee verifica que un bloque declara el base fee que le corresponde
+func (bc *Blockchain) checkBaseFee(block *Block) error {
+	expected := bc.BaseFee()
+	if (expected == nil) != (block.BaseFee == nil) ||
+		(expected != nil && expected.Cmp(block.BaseFee) != 0) {
+		return fmt.Errorf("base fee incorrecto en bloque %d: cabecera %v, esperado %v",
+			block.Index, block.BaseFee, expected)
+	}
+	return nil
+}
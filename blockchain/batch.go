@@ -0,0 +1,171 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+	"minichain/crypto"
+	"minichain/utils"
+)
+
+// Gas por cada autorización incluida en un lote (verificar firma + mover fondos)
+const batchTransferGas = 5000
+
+// TransferAuthorization es una transferencia firmada fuera de la cadena (off-chain)
+// El titular la firma con su clave y cualquiera puede incluirla en un lote
+type TransferAuthorization struct {
+	From       string
+	To         string
+	Amount     *big.Int // Unidades base
+	Nonce      int      // Debe coincidir con el nonce de From al aplicarse
+	ChainID    uint64   // Red para la que se firmó (la misma que la del lote)
+	Signature  string
+	PublicKeyX *big.Int
+	PublicKeyY *big.Int
+}
+
+// NewTransferAuthorization crea una autorización de transferencia (sin firmar)
+func NewTransferAuthorization(from, to string, amount *big.Int, nonce int) *TransferAuthorization {
+	return &TransferAuthorization{
+		From:   from,
+		To:     to,
+		Amount: amount,
+		Nonce:  nonce,
+	}
+}
+
+// getDataToSign obtiene los datos que firma el titular
+// Lleva el prefijo "batch" para que no se pueda confundir con una transacción normal
+func (auth *TransferAuthorization) getDataToSign() []byte {
+	data := fmt.Sprintf("batch:%d:%s:%s:%s:%d", auth.ChainID, auth.From, auth.To, auth.Amount, auth.Nonce)
+	return []byte(data)
+}
+
+// Sign firma la autorización con el par de claves del titular para la red chainID
+func (auth *TransferAuthorization) Sign(keyPair *crypto.KeyPair, chainID uint64) error {
+	if auth.From != keyPair.GetAddress() {
+		return fmt.Errorf("la dirección From no coincide con el par de claves")
+	}
+
+	auth.ChainID = chainID
+	auth.PublicKeyX = keyPair.PublicKey.X
+	auth.PublicKeyY = keyPair.PublicKey.Y
+
+	signature, err := keyPair.SignData(auth.getDataToSign())
+	if err != nil {
+		return fmt.Errorf("error firmando autorización: %v", err)
+	}
+
+	auth.Signature = signature
+	return nil
+}
+
+// VerifySignature verifica la firma y que la clave pública corresponda a From
+func (auth *TransferAuthorization) VerifySignature() bool {
+	if auth.Signature == "" || auth.PublicKeyX == nil || auth.PublicKeyY == nil {
+		return false
+	}
+
+	// Sin esta comprobación cualquiera podría firmar en nombre de otra dirección
+	if crypto.PublicKeyToAddress(auth.PublicKeyX, auth.PublicKeyY) != auth.From {
+		return false
+	}
+
+	return crypto.VerifySignature(auth.PublicKeyX, auth.PublicKeyY, auth.getDataToSign(), auth.Signature)
+}
+
+// Validate comprueba la autorización de forma aislada (sin mirar el estado)
+func (auth *TransferAuthorization) Validate() error {
+	if auth.Amount == nil || auth.Amount.Sign() <= 0 {
+		return fmt.Errorf("monto inválido en autorización: %s", utils.FormatMTC(auth.Amount))
+	}
+	if auth.To == "" || auth.From == auth.To {
+		return fmt.Errorf("destinatario inválido en autorización de %s", auth.From)
+	}
+	if !auth.VerifySignature() {
+		return fmt.Errorf("firma inválida en autorización de %s", auth.From)
+	}
+	return nil
+}
+
+// NewBatchTx crea una transacción de sistema que incluye un lote de autorizaciones
+// El remitente (agregador) paga el gas; los fondos se mueven entre los titulares
+func NewBatchTx(from string, batch []*TransferAuthorization, nonce int) *Transaction {
+	return &Transaction{
+		From:     from,
+		To:       "",
+		Amount:   new(big.Int),
+		Nonce:    nonce,
+		Batch:    batch,
+		GasPrice: big.NewInt(DefaultGasPrice),
+		GasLimit: txGas + uint64(len(batch))*batchTransferGas,
+	}
+}
+
+// IsBatch verifica si la transacción es un lote de transferencias off-chain
+func (tx *Transaction) IsBatch() bool {
+	return len(tx.Batch) > 0
+}
+
+// validateBatch verifica todas las autorizaciones contra una copia del estado
+// Si una sola falla, el lote completo se rechaza
+func (tx *Transaction) validateBatch(state *AccountState) error {
+	balances := make(map[string]*big.Int)
+	nonces := make(map[string]int)
+
+	for i, auth := range tx.Batch {
+		if err := auth.Validate(); err != nil {
+			return fmt.Errorf("autorización %d: %v", i, err)
+		}
+
+		if auth.ChainID != tx.ChainID {
+			return fmt.Errorf("autorización %d: firmada para la red %d", i, auth.ChainID)
+		}
+
+		// El nonce del agregador ya se consume con la propia transacción
+		if auth.From == tx.From {
+			return fmt.Errorf("autorización %d: el agregador no puede incluir transferencias propias", i)
+		}
+
+		if _, seen := balances[auth.From]; !seen {
+			balances[auth.From] = state.GetBalance(auth.From)
+			nonces[auth.From] = state.GetAccount(auth.From).Nonce
+		}
+		if _, seen := balances[auth.To]; !seen {
+			balances[auth.To] = state.GetBalance(auth.To)
+			nonces[auth.To] = state.GetAccount(auth.To).Nonce
+		}
+
+		if auth.Nonce != nonces[auth.From] {
+			return fmt.Errorf("autorización %d: nonce incorrecto: esperado %d, recibido %d",
+				i, nonces[auth.From], auth.Nonce)
+		}
+		if balances[auth.From].Cmp(auth.Amount) < 0 {
+			return fmt.Errorf("autorización %d: saldo insuficiente: %s < %s",
+				i, utils.FormatMTC(balances[auth.From]), utils.FormatMTC(auth.Amount))
+		}
+
+		balances[auth.From].Sub(balances[auth.From], auth.Amount) // Copias de GetBalance
+		balances[auth.To].Add(balances[auth.To], auth.Amount)
+		nonces[auth.From]++
+	}
+
+	return nil
+}
+
+// applyBatch aplica todas las autorizaciones del lote
+// Se llama dentro de Execute, que revierte el estado completo si algo falla
+func (tx *Transaction) applyBatch(state *AccountState) error {
+	if err := tx.validateBatch(state); err != nil {
+		return err
+	}
+
+	for _, auth := range tx.Batch {
+		if err := state.SubtractBalance(auth.From, auth.Amount); err != nil {
+			return err
+		}
+		state.AddBalance(auth.To, auth.Amount)
+		state.IncrementNonce(auth.From)
+	}
+
+	return nil
+}
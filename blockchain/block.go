@@ -2,9 +2,11 @@ package blockchain
 
 import (
 	"fmt"
+	"math/big"
 	"minichain/utils"
 	"strconv"
-	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -14,8 +16,24 @@ type Block struct {
 	Timestamp    time.Time      // Cuándo se creó el bloque
 	Transactions []*Transaction // Lista de transacciones en el bloque
 	PreviousHash string         // Hash del bloque anterior (esto crea la "cadena")
+	TxRoot       string         // Raíz de Merkle de sus transacciones (ver CalculateTxRoot)
 	Hash         string         // Hash de ESTE bloque (su huella digital única)
 	Nonce        int            // Número que se va probando hasta encontrar un hash válido
+	Bits         uint32         // Objetivo de PoW en formato compacto (hash <= objetivo)
+	StateRoot    string         // Raíz de Merkle del estado tras ejecutar el bloque
+	ReceiptRoot  string         // Raíz de Merkle de los recibos de sus transacciones
+	Extra        string         // Datos libres (en el génesis: hash de la especificación)
+	GasUsed      uint64         // Gas consumido por todas las transacciones
+	TotalFees    *big.Int       // Propinas cobradas (van a la coinbase)
+	TotalRefunds *big.Int       // Gas reservado y devuelto a los remitentes
+	BaseFee      *big.Int       // Precio mínimo por gas en este bloque (nil antes del fork)
+	TotalBurned  *big.Int       // Base fee quemado (gas usado × base fee)
+
+	// Solo en Proof of Stake: quién produjo el bloque y su firma sobre el hash
+	Validator     string
+	ValidatorSig  string
+	ValidatorKeyX *big.Int
+	ValidatorKeyY *big.Int
 }
 
 // NewBlock crea un nuevo bloque (sin minar todavía)
@@ -26,7 +44,11 @@ func NewBlock(index int, transactions []*Transaction, previousHash string) *Bloc
 		Transactions: transactions,
 		PreviousHash: previousHash,
 		Nonce:        0, // Empieza en 0, se incrementará al minar
+		TotalFees:    new(big.Int),
+		TotalRefunds: new(big.Int),
+		TotalBurned:  new(big.Int),
 	}
+	block.TxRoot = block.CalculateTxRoot()
 	return block
 }
 
@@ -37,64 +59,81 @@ func NewGenesisBlock() *Block {
 		Timestamp:    time.Now(),
 		Transactions: []*Transaction{}, // Sin transacciones
 		PreviousHash: "0",
+		TxRoot:       utils.EmptyRoot,
 		Nonce:        0,
+		TotalFees:    new(big.Int),
+		TotalRefunds: new(big.Int),
+		TotalBurned:  new(big.Int),
 	}
 }
 
-// getTransactionsData convierte las transacciones a string para el hash
-func (b *Block) getTransactionsData() string {
-	if len(b.Transactions) == 0 {
-		return ""
+// CalculateTxRoot calcula la raíz de Merkle de las transacciones del bloque
+// Las hojas son las transacciones en formato raw, que cubre todos los campos
+// que las definen (también la firma), en el orden del bloque
+func (b *Block) CalculateTxRoot() string {
+	leaves := make([][]byte, len(b.Transactions))
+	for i, tx := range b.Transactions {
+		leaves[i] = tx.EncodeRaw()
 	}
+	return utils.MerkleRoot(leaves)
+}
 
-	// Serializar transacciones a JSON para el hash
-	var txData []string
-	for _, tx := range b.Transactions {
-		// Incluir TODOS los campos que definen la transacción
-		txStr := fmt.Sprintf("from=%s|to=%s|amount=%.2f|nonce=%d|data=%x|sig=%s",
-			tx.From,
-			tx.To,
-			tx.Amount,
-			tx.Nonce,
-			tx.Data,
-			tx.Signature,
-		)
-		txData = append(txData, txStr)
+// checkTxRoot verifica que las transacciones son las que compromete la cabecera
+func (b *Block) checkTxRoot() error {
+	if root := b.CalculateTxRoot(); root != b.TxRoot {
+		return fmt.Errorf("tx root incorrecto en bloque %d: cabecera %s, calculado %s",
+			b.Index, b.TxRoot, root)
 	}
-
-	return strings.Join(txData, "||")
+	return nil
 }
 
 // CalculateBlockHash calcula el hash del bloque
 // Combina TODOS los datos del bloque en un solo string y hace hash
 func (b *Block) CalculateBlockHash() string {
-	// Concatenamos todos los datos del bloque
-	record := strconv.Itoa(b.Index) +
-		b.Timestamp.String() +
-		b.getTransactionsData() +
-		b.PreviousHash +
-		strconv.Itoa(b.Nonce)
+	// Calculamos el hash SHA-256 de la cabecera seguida del nonce
+	return utils.CalculateHash(b.SealingData() + strconv.Itoa(b.Nonce))
+}
 
-	// Calculamos el hash SHA-256 de todo eso
-	return utils.CalculateHash(record)
+// SealingData son todos los datos de la cabecera salvo el nonce
+// El hash es SHA-256(SealingData + nonce en decimal), así que un minero externo
+// puede buscar el nonce con solo este texto y el objetivo
+func (b *Block) SealingData() string {
+	return strconv.Itoa(b.Index) +
+		strconv.FormatInt(b.Timestamp.UnixNano(), 10) + // No depende de la zona horaria
+		b.TxRoot +
+		b.PreviousHash +
+		b.StateRoot +
+		b.ReceiptRoot +
+		b.Extra +
+		fmt.Sprintf("%d|%s|%s|%s|%s", b.GasUsed, b.TotalFees, b.TotalRefunds, b.BaseFee, b.TotalBurned) +
+		strconv.FormatUint(uint64(b.Bits), 16) +
+		b.Validator
 }
 
 // MineBlock realiza el "Proof of Work" - encuentra un hash válido
-// difficulty = cuántos ceros debe tener al inicio el hash
-func (b *Block) MineBlock(difficulty int) {
-	fmt.Printf("\n⛏️  Minando bloque %d (dificultad: %d, %d transacciones)...\n",
-		b.Index, difficulty, len(b.Transactions))
+// El hash, leído como número de 256 bits, debe ser <= al objetivo de b.Bits
+// workers = cuántas goroutines reparten el espacio de nonces (1 = secuencial)
+// Devuelve cuántos hashes se calcularon hasta encontrar la solución
+func (b *Block) MineBlock(workers int) int64 {
+	target := utils.CompactToTarget(b.Bits)
+
+	fmt.Printf("\n⛏️  Minando bloque %d (bits: %08x, dificultad: %.0f, %d transacciones, %d hilos)...\n",
+		b.Index, b.Bits, utils.TargetDifficulty(b.Bits), len(b.Transactions), max(workers, 1))
+
+	if workers > 1 {
+		return b.mineParallel(target, workers)
+	}
 
 	// Probamos diferentes valores de Nonce hasta encontrar un hash válido
 	for {
 		// Calculamos el hash con el Nonce actual
 		b.Hash = b.CalculateBlockHash()
 
-		// ¿Cumple con la dificultad? (¿empieza con suficientes ceros?)
-		if utils.MeetsTarget(b.Hash, difficulty) {
+		// ¿Cumple con el objetivo? (¿es el hash numéricamente menor?)
+		if utils.MeetsNumericTarget(b.Hash, target) {
 			// ¡Encontrado! Este bloque es válido
 			fmt.Printf("✅ Bloque minado! Hash: %s (intentos: %d)\n", b.Hash, b.Nonce)
-			break
+			return int64(b.Nonce) + 1
 		}
 
 		// No funcionó, probamos con el siguiente número
@@ -107,15 +146,67 @@ func (b *Block) MineBlock(difficulty int) {
 	}
 }
 
-// IsValid verifica si el bloque es válido
-func (b *Block) IsValid(difficulty int) bool {
+// mineParallel reparte los nonces entre workers: el worker i prueba i, i+n, i+2n...
+// El primero que encuentra una solución gana y los demás se cancelan
+func (b *Block) mineParallel(target *big.Int, workers int) int64 {
+	found := make(chan *Block, workers)
+	done := make(chan struct{})
+	var attempts atomic.Int64
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(start int) {
+			defer wg.Done()
+
+			// Cada worker trabaja sobre su propia copia de la cabecera
+			candidate := *b
+			candidate.Nonce = start
+
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				candidate.Hash = candidate.CalculateBlockHash()
+				n := attempts.Add(1)
+				if utils.MeetsNumericTarget(candidate.Hash, target) {
+					found <- &candidate
+					return
+				}
+
+				candidate.Nonce += workers
+
+				// Mostrar progreso cada 100,000 intentos (entre todos los workers)
+				if n%100000 == 0 {
+					fmt.Printf("   Intentando... %d intentos\n", n)
+				}
+			}
+		}(i)
+	}
+
+	winner := <-found
+	close(done)
+	wg.Wait()
+
+	b.Nonce = winner.Nonce
+	b.Hash = winner.Hash
+
+	fmt.Printf("✅ Bloque minado! Hash: %s (nonce: %d, intentos: %d)\n", b.Hash, b.Nonce, attempts.Load())
+	return attempts.Load()
+}
+
+// IsValid verifica si el bloque es válido según su propio objetivo (Bits)
+func (b *Block) IsValid() bool {
 	// Recalculamos el hash
 	calculatedHash := b.CalculateBlockHash()
 
 	// Verificamos que:
 	// 1. El hash almacenado coincide con el calculado
-	// 2. El hash cumple con la dificultad
-	return b.Hash == calculatedHash && utils.MeetsTarget(b.Hash, difficulty)
+	// 2. El hash cumple con el objetivo de la cabecera
+	return b.Hash == calculatedHash && utils.MeetsNumericTarget(b.Hash, utils.CompactToTarget(b.Bits))
 }
 
 // Print muestra el bloque de forma bonita
@@ -133,15 +224,19 @@ func (b *Block) Print() {
 			for i, tx := range b.Transactions {
 				fmt.Printf("\n📝 Transacción %d:\n", i+1)
 
-				// From (siempre existe)
-				if len(tx.From) >= 16 {
+				// From (la coinbase no tiene remitente)
+				if tx.IsCoinbase() {
+					fmt.Println("   From: (COINBASE - recompensa)")
+				} else if len(tx.From) >= 16 {
 					fmt.Printf("   From: %s\n", tx.From[:16]+"...")
 				} else {
 					fmt.Printf("   From: %s\n", tx.From)
 				}
 
 				// To (depende del tipo)
-				if tx.IsContractDeployment() {
+				if tx.IsBatch() {
+					fmt.Printf("   Tipo: LOTE (%d transferencias off-chain)\n", len(tx.Batch))
+				} else if tx.IsContractDeployment() {
 					fmt.Println("   To: (CONTRATO - DEPLOYMENT)")
 					if tx.ContractAddress != "" && len(tx.ContractAddress) >= 16 {
 						fmt.Printf("   Contrato desplegado: %s\n", tx.ContractAddress[:16]+"...")
@@ -160,7 +255,7 @@ func (b *Block) Print() {
 				}
 
 				// Resto de info
-				fmt.Printf("   Monto: %.2f MTC\n", tx.Amount)
+				fmt.Printf("   Monto: %s MTC\n", utils.FormatMTC(tx.Amount))
 				fmt.Printf("   Nonce: %d\n", tx.Nonce)
 
 				if tx.GasUsed > 0 {
@@ -189,5 +284,30 @@ func (b *Block) Print() {
 	}
 
 	fmt.Printf("🎲 Nonce:         %d\n", b.Nonce)
+	if b.GasUsed > 0 {
+		fmt.Printf("⛽ Gas usado:     %d\n", b.GasUsed)
+		fmt.Printf("💸 Comisiones:    %s MTC\n", utils.FormatMTC(b.TotalFees))
+		fmt.Printf("💰 Devoluciones:  %s MTC\n", utils.FormatMTC(b.TotalRefunds))
+		if b.BaseFee != nil {
+			fmt.Printf("🔥 Quemado:       %s MTC\n", utils.FormatMTC(b.TotalBurned))
+		}
+	}
+	if b.BaseFee != nil {
+		fmt.Printf("📉 Base fee:      %s MTC/gas\n", utils.FormatMTC(b.BaseFee))
+	}
+	if len(b.StateRoot) > 16 {
+		fmt.Printf("🌳 State Root:    %s...\n", b.StateRoot[:16])
+	}
+	if len(b.TxRoot) > 16 {
+		fmt.Printf("📜 Tx Root:       %s...\n", b.TxRoot[:16])
+	}
+	if len(b.ReceiptRoot) > 16 {
+		fmt.Printf("🧾 Receipt Root:  %s...\n", b.ReceiptRoot[:16])
+	}
+	if b.Validator != "" {
+		fmt.Printf("✍️  Validador:     %s\n", b.Validator[:16]+"...")
+	} else {
+		fmt.Printf("🎯 Bits:          %08x\n", b.Bits)
+	}
 	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 }
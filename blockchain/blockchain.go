@@ -1,117 +1,208 @@
 package blockchain
 
 import (
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"math/big"
 	"minichain/evm"
+	"minichain/mempool"
+	"minichain/utils"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Blockchain es la cadena completa de bloques
 type Blockchain struct {
-	Blocks       []*Block                 // Array de bloques
-	Difficulty   int                      // Dificultad del minado (ej: 3 = "000...")
-	AccountState *AccountState            // Estado de todas las cuentas
-	PendingTxs   []*Transaction           // Transacciones pendientes (mempool)
-	Contracts    map[string]*evm.Contract // Contratos desplegados
+	Blocks        []*Block                 // Array de bloques
+	Difficulty    int                      // Dificultad inicial (ej: 3 = "000...")
+	Bits          uint32                   // Objetivo numérico actual en formato compacto
+	ChainID       uint64                   // Identificador de la red (del génesis)
+	AccountState  *AccountState            // Estado de todas las cuentas
+	Mempool       *mempool.Pool            // Transacciones pendientes de minar
+	Contracts     map[string]*evm.Contract // Contratos desplegados
+	MinerThreads  int                      // Goroutines que buscan el nonce en paralelo
+	Coinbase      string                   // Dirección que cobra la recompensa ("" = sin recompensa)
+	GasLimit      uint64                   // Gas máximo que caben en un bloque
+	MinerGasLimit uint64                   // Gas máximo que este nodo mete en sus bloques (0 = GasLimit)
+	Engine        ConsensusEngine          // Motor de consenso (PoW por defecto)
+	Checkpoints   map[int]string           // altura -> hash que no se puede reescribir
+	Config        *ChainConfig             // Alturas de activación de los cambios del protocolo
+
+	nonceMu        sync.Mutex                   // Protege las reservas de nonces
+	reservedNonces map[string]map[int]time.Time // address -> nonce reservado -> caducidad
+
+	hashrate atomic.Uint64 // Hashes por segundo (bits de un float64, ver Hashrate)
+
+	executing *Block // Bloque cuyas transacciones se están ejecutando (ver NewBlockContext)
+
+	head    atomic.Int64 // Altura de la cabeza, legible sin tocar Blocks (ver SyncProgress)
+	syncMu  sync.Mutex   // Protege syncing
+	syncing *syncState   // Importación en curso (nil si no hay)
+
+	workMu      sync.Mutex        // Protege los trabajos de mineros externos
+	pendingWork map[string]*Block // Work.ID -> bloque candidato (ver GetWork)
+	workOrder   []string          // IDs de pendingWork, del más antiguo al más reciente
+
+	receipts     map[string][]*Receipt   // hash de bloque -> recibos de sus transacciones
+	addressIndex map[string][]TxLocation // dirección -> transacciones en las que aparece
+	txLookup     map[string]TxLocation   // hash de transacción -> dónde se confirmó
+	blockNumbers map[string]int          // hash de bloque -> altura
 }
 
-// NewBlockchain crea una nueva blockchain con el bloque génesis
-func NewBlockchain(difficulty int) *Blockchain {
-	// Crear el bloque génesis (bloque #0)
-	genesisBlock := NewGenesisBlock()
-
-	// Minar el bloque génesis
-	genesisBlock.MineBlock(difficulty)
+// DefaultBlockGasLimit permite unas 10 llamadas a contrato por bloque
+const DefaultBlockGasLimit = 10000000
 
-	// Crear la blockchain
-	bc := &Blockchain{
-		Blocks:       []*Block{genesisBlock},
-		Difficulty:   difficulty,
-		AccountState: NewAccountState(),
-		PendingTxs:   []*Transaction{},
-		Contracts:    make(map[string]*evm.Contract),
+// NewBlockchain crea una nueva blockchain con un génesis vacío
+// El génesis lleva la hora actual, así que cada llamada crea una red distinta
+func NewBlockchain(difficulty int) *Blockchain {
+	bc, err := NewBlockchainFromGenesis(&Genesis{
+		ChainID:    DefaultChainID,
+		Difficulty: difficulty,
+		Timestamp:  time.Now().Unix(),
+	})
+	if err != nil {
+		panic(err) // Solo falla con una dificultad inválida: error de programación
 	}
-
 	return bc
 }
 
 // AddTransaction añade una transacción al mempool (pendientes)
+// El mempool aplica las reglas de admisión (ver mempool.Pool.Add)
 func (bc *Blockchain) AddTransaction(tx *Transaction) error {
-	// Validar la transacción
-	if err := tx.Validate(bc.AccountState, bc); err != nil {
+	if err := bc.Mempool.Add(tx); err != nil {
 		return err
 	}
 
-	// Añadir al mempool
-	bc.PendingTxs = append(bc.PendingTxs, tx)
-
-	fmt.Printf("✅ Transacción añadida al mempool (total: %d pendientes)\n", len(bc.PendingTxs))
+	fmt.Printf("✅ Transacción añadida al mempool (total: %d pendientes)\n", bc.Mempool.Len())
 
 	return nil
 }
 
+// AddTransactions añade varias transacciones al mempool de una vez (ver mempool.Pool.AddBatch)
+// Devuelve un error por transacción (nil si entró), en el mismo orden
+func (bc *Blockchain) AddTransactions(txs []*Transaction) []error {
+	batch := make([]mempool.Tx, len(txs))
+	for i, tx := range txs {
+		batch[i] = tx
+	}
+
+	errs := bc.Mempool.AddBatch(batch)
+
+	added := 0
+	for _, err := range errs {
+		if err == nil {
+			added++
+		}
+	}
+	fmt.Printf("✅ %d de %d transacciones añadidas al mempool (total: %d pendientes)\n",
+		added, len(txs), bc.Mempool.Len())
+
+	return errs
+}
+
 // MineBlock mina un nuevo bloque con las transacciones pendientes
 func (bc *Blockchain) MineBlock() {
-	if len(bc.PendingTxs) == 0 {
+	if len(bc.Mempool.Pending()) == 0 {
 		fmt.Println("\n⚠️  No hay transacciones pendientes para minar")
 		return
 	}
 
+	newBlock, snapshot, err := bc.prepareBlock()
+	if err != nil {
+		fmt.Printf("\n❌ No se pudo preparar el bloque: %v\n", err)
+		return
+	}
+
+	// Sellar el bloque con el motor de consenso (minar o firmar)
+	// Si no se puede sellar, el bloque no existe: deshacer su ejecución
+	if err := bc.Engine.Seal(bc, newBlock); err != nil {
+		bc.revertState(snapshot)
+		fmt.Printf("\n❌ No se pudo sellar el bloque: %v\n", err)
+		return
+	}
+
+	// Añadir bloque a la cadena
+	bc.appendBlock(newBlock)
+
+	// Quitar del mempool solo las transacciones incluidas y revalidar el resto
+	bc.reconcileMempool(newBlock)
+	if pending := len(bc.Mempool.Pending()); pending > 0 {
+		fmt.Printf("   ⏳ %d transacciones no cabían y siguen pendientes\n", pending)
+	}
+
+	fmt.Printf("\n✅ Bloque %d minado exitosamente!\n", newBlock.Index)
+	fmt.Printf("   Hash: %s\n", newBlock.Hash)
+}
+
+// appendBlock añade un bloque ya ejecutado y validado a la cabeza de la cadena
+// y actualiza lo que se deriva de él (recibos e índices)
+func (bc *Blockchain) appendBlock(block *Block) {
+	bc.Blocks = append(bc.Blocks, block)
+	bc.head.Store(int64(block.Index))
+	bc.storeReceipts(block)
+	bc.indexBlock(block)
+}
+
+// prepareBlock arma el siguiente bloque con las transacciones pendientes y lo ejecuta
+// Devuelve el bloque sin sellar y el snapshot del estado anterior, para poder
+// deshacer la ejecución si no llega a sellarse. Falla (sin ejecutar nada) si el
+// motor de consenso no deja producir el bloque a este nodo.
+func (bc *Blockchain) prepareBlock() (*Block, *chainStateSnapshot, error) {
 	prevBlock := bc.Blocks[len(bc.Blocks)-1]
 
+	// Elegir las transacciones que más pagan hasta llenar el bloque
+	selected := bc.selectTransactions(bc.PendingTransactions())
+
+	// La coinbase va siempre en primer lugar
+	transactions := selected
+	if bc.Coinbase != "" {
+		coinbase := NewCoinbaseTx(bc.Coinbase, BlockReward, len(bc.Blocks))
+		transactions = append([]*Transaction{coinbase}, selected...)
+	}
+
 	// Crear nuevo bloque
 	newBlock := &Block{
 		Index:        len(bc.Blocks),
 		Timestamp:    time.Now(),
-		Transactions: bc.PendingTxs,
+		Transactions: transactions,
 		PreviousHash: prevBlock.Hash,
 		Nonce:        0,
+		BaseFee:      bc.BaseFee(),
 	}
+	newBlock.TxRoot = newBlock.CalculateTxRoot()
 
-	// Minar el bloque
-	fmt.Printf("\n⛏️  Minando bloque %d (dificultad: %d, %d transacciones)...\n",
-		newBlock.Index, bc.Difficulty, len(bc.PendingTxs))
-
-	newBlock.MineBlock(bc.Difficulty)
-
-	// EJECUTAR TRANSACCIONES (incluye contratos)
-	fmt.Println("\n💼 Ejecutando transacciones del bloque...")
-	for i, tx := range bc.PendingTxs {
-		fmt.Printf("\n📝 Transacción %d/%d:\n", i+1, len(bc.PendingTxs))
-
-		// Mostrar tipo de transacción
-		if tx.IsContractDeployment() {
-			fmt.Println("   Tipo: DESPLIEGUE DE CONTRATO")
-		} else if tx.IsContractCall(bc) {
-			fmt.Println("   Tipo: LLAMADA A CONTRATO")
-		} else {
-			fmt.Printf("   Tipo: TRANSFERENCIA (%s → %s: %.2f MTC)\n",
-				tx.From[:16]+"...", tx.To[:16]+"...", tx.Amount)
-		}
-
-		// Ejecutar (incluye contratos si aplica)
-		if err := tx.Execute(bc.AccountState, bc); err != nil {
-			fmt.Printf("   ❌ Error: %v\n", err)
-			continue
-		}
+	// El motor completa la cabecera con el estado del padre, antes de ejecutar
+	if err := bc.Engine.Prepare(bc, newBlock); err != nil {
+		return nil, nil, err
+	}
 
-		if tx.Amount > 0 {
-			fmt.Printf("   ✅ Fondos transferidos\n")
+	// Ejecutar las transacciones (incluye contratos) y fijar el estado resultante
+	// Si la ejecución hace panic, el estado no se queda a medias
+	snapshot := bc.snapshotState()
+	defer func() {
+		if r := recover(); r != nil {
+			bc.revertState(snapshot)
+			panic(r)
 		}
+	}()
+	totals, failed := bc.applyBlock(newBlock)
+	totals.setOn(newBlock)
+
+	// Las que no se pudieron ejecutar no cambiaron el estado: fuera del bloque
+	if len(failed) > 0 {
+		newBlock.dropTransactions(failed)
+		newBlock.TxRoot = newBlock.CalculateTxRoot()
 	}
+	newBlock.StateRoot = bc.StateRoot()
+	newBlock.ReceiptRoot = receiptRoot(newBlock)
 
-	// Añadir bloque a la cadena
-	bc.Blocks = append(bc.Blocks, newBlock)
-
-	// Limpiar transacciones pendientes
-	bc.PendingTxs = []*Transaction{}
-
-	fmt.Printf("\n✅ Bloque %d minado exitosamente!\n", newBlock.Index)
-	fmt.Printf("   Hash: %s\n", newBlock.Hash)
+	return newBlock, snapshot, nil
 }
 
 // GetBalance obtiene el saldo de una cuenta
-func (bc *Blockchain) GetBalance(address string) float64 {
+func (bc *Blockchain) GetBalance(address string) *big.Int {
 	return bc.AccountState.GetBalance(address)
 }
 
@@ -120,15 +211,29 @@ func (bc *Blockchain) GetNonce(address string) int {
 	return bc.AccountState.GetAccount(address).Nonce
 }
 
+// GetTransaction busca una transacción confirmada por su hash
+// Devuelve también el bloque que la contiene
+func (bc *Blockchain) GetTransaction(hash string) (*Transaction, *Block, error) {
+	location, ok := bc.ReadTxLookupEntry(hash)
+	if !ok {
+		return nil, nil, fmt.Errorf("transacción %s no encontrada", hash)
+	}
+	return bc.TransactionAt(location), bc.Blocks[location.BlockNumber], nil
+}
+
 // IsValid verifica que toda la blockchain sea válida
 func (bc *Blockchain) IsValid() bool {
 	// Primero verificar el bloque génesis (índice 0)
 	if len(bc.Blocks) > 0 {
 		genesisBlock := bc.Blocks[0]
-		if !genesisBlock.IsValid(bc.Difficulty) {
+		if !genesisBlock.IsValid() {
 			fmt.Printf("❌ Bloque génesis (#0) es inválido\n")
 			return false
 		}
+		if err := bc.checkCheckpoint(genesisBlock); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return false
+		}
 	}
 
 	// Luego verificar el resto de bloques y sus enlaces
@@ -136,19 +241,35 @@ func (bc *Blockchain) IsValid() bool {
 		currentBlock := bc.Blocks[i]
 		previousBlock := bc.Blocks[i-1]
 
-		// 1. Verificar que el bloque en sí sea válido
-		if !currentBlock.IsValid(bc.Difficulty) {
-			fmt.Printf("❌ Bloque #%d es inválido\n", i)
+		// 1. Verificar el sello del bloque según el motor de consenso
+		if err := bc.Engine.VerifySeal(bc, currentBlock); err != nil {
+			fmt.Printf("❌ Bloque #%d es inválido: %v\n", i, err)
+			return false
+		}
+
+		// 2. Verificar la recompensa del bloque y que las transacciones son las de la cabecera
+		if err := currentBlock.checkTxRoot(); err != nil {
+			fmt.Printf("❌ Bloque #%d: %v\n", i, err)
+			return false
+		}
+		if err := validateCoinbase(currentBlock); err != nil {
+			fmt.Printf("❌ Bloque #%d: %v\n", i, err)
 			return false
 		}
 
-		// 2. Verificar que el hash anterior coincida
+		// 3. Verificar que el hash anterior coincida
 		if currentBlock.PreviousHash != previousBlock.Hash {
 			fmt.Printf("❌ Cadena rota en bloque #%d\n", i)
 			fmt.Printf("   PreviousHash del bloque: %s\n", currentBlock.PreviousHash)
 			fmt.Printf("   Hash del bloque anterior: %s\n", previousBlock.Hash)
 			return false
 		}
+
+		// 4. Verificar que no contradiga ningún checkpoint
+		if err := bc.checkCheckpoint(currentBlock); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return false
+		}
 	}
 
 	return true
@@ -157,7 +278,7 @@ func (bc *Blockchain) IsValid() bool {
 // Print muestra toda la blockchain
 func (bc *Blockchain) Print() {
 	fmt.Println("\n" + "╔════════════════════════════════════════╗")
-	fmt.Printf("║      BLOCKCHAIN (Dificultad: %d)       ║\n", bc.Difficulty)
+	fmt.Printf("║      BLOCKCHAIN (Bits: %08x)         ║\n", bc.Bits)
 	fmt.Printf("║      Total bloques: %d                  ║\n", len(bc.Blocks))
 	fmt.Println("╚════════════════════════════════════════╝")
 
@@ -172,35 +293,101 @@ func (bc *Blockchain) PrintPendingTransactions() {
 	fmt.Println("║      TRANSACCIONES PENDIENTES          ║")
 	fmt.Println("╚════════════════════════════════════════╝")
 
-	if len(bc.PendingTxs) == 0 {
+	pending := bc.PendingTransactions()
+	if len(pending) == 0 {
 		fmt.Println("\n   (No hay transacciones pendientes)")
 		return
 	}
 
-	for i, tx := range bc.PendingTxs {
+	for i, tx := range pending {
 		fmt.Printf("\n%d. From: %s\n", i+1, tx.From[:16]+"...")
 
 		// Determinar tipo de transacción
-		if tx.IsContractDeployment() {
+		if tx.IsBatch() {
+			fmt.Printf("   Lote: %d transferencias off-chain\n", len(tx.Batch))
+		} else if tx.IsContractDeployment() {
 			fmt.Println("   To: (CONTRATO - DEPLOYMENT)")
-			fmt.Printf("   Monto: %.2f MTC\n", tx.Amount)
+			fmt.Printf("   Monto: %s MTC\n", utils.FormatMTC(tx.Amount))
 			fmt.Printf("   Data: %d bytes\n", len(tx.Data))
 		} else if tx.To == "" {
 			fmt.Println("   To: (Sin destinatario)")
 		} else if len(tx.To) >= 8 {
 			fmt.Printf("   To: %s\n", tx.To[:16]+"...")
-			fmt.Printf("   Monto: %.2f MTC\n", tx.Amount)
+			fmt.Printf("   Monto: %s MTC\n", utils.FormatMTC(tx.Amount))
 			if len(tx.Data) > 0 {
 				fmt.Printf("   Data: %d bytes (LLAMADA A CONTRATO)\n", len(tx.Data))
 			}
 		} else {
 			fmt.Printf("   To: %s\n", tx.To)
-			fmt.Printf("   Monto: %.2f MTC\n", tx.Amount)
+			fmt.Printf("   Monto: %s MTC\n", utils.FormatMTC(tx.Amount))
 		}
 
 		fmt.Printf("   Nonce: %d\n", tx.Nonce)
 		fmt.Printf("   Firmada: %v\n", tx.Signature != "")
+		fmt.Printf("   Hash: %s\n", tx.Hash()[:16]+"...")
+	}
+}
+
+// randomBeaconDepth es cuántos bloques recientes se mezclan en el beacon
+const randomBeaconDepth = 8
+
+// RandomBeacon deriva un valor pseudoaleatorio determinista de los últimos hashes
+// Todos los nodos obtienen el mismo valor, pero un minero puede influir en él
+// descartando bloques, así que no sirve para loterías con mucho valor en juego
+func (bc *Blockchain) RandomBeacon() *big.Int {
+	start := len(bc.Blocks) - randomBeaconDepth
+	if start < 0 {
+		start = 0
+	}
+
+	mix := ""
+	for _, block := range bc.Blocks[start:] {
+		mix += block.Hash
+	}
+
+	hash, _ := hex.DecodeString(utils.CalculateHash(mix))
+	return new(big.Int).SetBytes(hash)
+}
+
+// NewBlockContext crea el contexto de bloque que ve la EVM al ejecutar
+// Siempre es el del próximo bloque: el que se está construyendo o ejecutando.
+// Fuera de un bloque (eth_call) la fecha es la actual y la coinbase la del nodo.
+func (bc *Blockchain) NewBlockContext() *evm.BlockContext {
+	number := len(bc.Blocks)
+	ctx := &evm.BlockContext{
+		Number:     number,
+		Timestamp:  time.Now().Unix(),
+		Coinbase:   bc.Coinbase,
+		Difficulty: bc.difficulty(),
+		Disabled:   bc.Config.DisabledOpcodes(number),
+		GetHash:    bc.blockHash,
 	}
+	if block := bc.executing; block != nil {
+		ctx.Timestamp = block.Timestamp.Unix()
+		ctx.Coinbase = blockCoinbase(block)
+	}
+	if bc.Config.IsPrevRandao(number) {
+		ctx.Random = bc.RandomBeacon()
+	}
+	return ctx
+}
+
+// difficulty es la dificultad que ve DIFFICULTY: cuántas veces más difícil es
+// el objetivo actual que el más fácil posible (0 en Proof of Stake, donde no se mina)
+func (bc *Blockchain) difficulty() *big.Int {
+	target := utils.CompactToTarget(bc.Bits)
+	if _, ok := bc.Engine.(*ProofOfWork); !ok || target.Sign() == 0 {
+		return new(big.Int)
+	}
+	return new(big.Int).Quo(utils.MaxTarget, target)
+}
+
+// blockHash devuelve el hash del bloque number ("" si no existe)
+func (bc *Blockchain) blockHash(number int) string {
+	if number < 0 || number >= len(bc.Blocks) {
+		return ""
+	}
+	return bc.Blocks[number].Hash
 }
 
 // DeployContract despliega un contrato en la blockchain
@@ -225,6 +412,15 @@ func (bc *Blockchain) GetContract(address string) (*evm.Contract, error) {
 	return contract, nil
 }
 
+// GetCode obtiene el bytecode desplegado en una dirección
+func (bc *Blockchain) GetCode(address string) ([]byte, error) {
+	contract, err := bc.GetContract(address)
+	if err != nil {
+		return nil, err
+	}
+	return contract.Bytecode, nil
+}
+
 // ExecuteContract ejecuta un contrato
 func (bc *Blockchain) ExecuteContract(address string, gas uint64) error {
 	contract, err := bc.GetContract(address)
@@ -234,16 +430,57 @@ func (bc *Blockchain) ExecuteContract(address string, gas uint64) error {
 
 	fmt.Printf("\n⚙️  Ejecutando contrato %s...\n", address[:16]+"...")
 
-	remainingGas, err := contract.Execute(gas)
+	returnData, remainingGas, err := contract.Execute(gas, bc.NewBlockContext())
 	if err != nil {
 		return fmt.Errorf("error ejecutando contrato: %v", err)
 	}
 
 	fmt.Printf("✅ Contrato ejecutado. Gas usado: %d\n", gas-remainingGas)
+	if returnData != nil {
+		fmt.Printf("   Devuelve: 0x%x\n", returnData)
+	}
 
 	return nil
 }
 
+// CallResult es el resultado de simular una llamada a un contrato
+type CallResult struct {
+	GasUsed      uint64
+	ReturnData   []byte // Lo que devolvió RETURN, o los datos del REVERT
+	RevertReason string // Vacío si la ejecución terminó bien
+}
+
+// Call ejecuta un contrato con calldata sin crear transacción (como eth_call)
+//
+// El estado se deshace al terminar, así que sirve para consultar y para ver
+// por qué fallaría una llamada antes de firmarla. Solo devuelve error si la
+// llamada no se puede hacer; un fallo del contrato va en RevertReason. from es
+// quien llama (CALLER y ORIGIN); puede ser "".
+func (bc *Blockchain) Call(from, address string, calldata []byte, gas uint64) (*CallResult, error) {
+	contract, err := bc.GetContract(address)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := bc.snapshotState()
+	defer bc.revertState(snapshot)
+
+	result := &CallResult{GasUsed: gas} // Si falla, se consume todo (como en Execute)
+	msg := &evm.Message{Caller: from, Origin: from, Data: calldata, Gas: gas}
+	returnData, remainingGas, err := contract.CallWith(msg, bc.newEVMHost(), bc.NewBlockContext())
+	if errors.Is(err, evm.ErrExecutionReverted) {
+		result.GasUsed = gas - remainingGas
+		result.ReturnData = returnData
+		result.RevertReason = revertError(returnData).Error()
+	} else if err != nil {
+		result.RevertReason = fmt.Sprintf("error ejecutando contrato: %v", err)
+	} else {
+		result.GasUsed = gas - remainingGas
+		result.ReturnData = returnData
+	}
+	return result, nil
+}
+
 // ListContracts muestra todos los contratos desplegados
 func (bc *Blockchain) ListContracts() {
 	fmt.Println("\n╔════════════════════════════════════════╗")
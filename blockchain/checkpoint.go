@@ -0,0 +1,37 @@
+package blockchain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AddCheckpoint fija el hash que debe tener el bloque de una altura
+// Ningún bloque que contradiga un checkpoint se acepta, por mucho trabajo que tenga
+func (bc *Blockchain) AddCheckpoint(height int, hash string) {
+	bc.Checkpoints[height] = hash
+}
+
+// ParseCheckpoint interpreta un checkpoint escrito como "altura:hash"
+func ParseCheckpoint(value string) (int, string, error) {
+	heightStr, hash, ok := strings.Cut(value, ":")
+	if !ok || hash == "" {
+		return 0, "", fmt.Errorf("checkpoint %q inválido (formato: altura:hash)", value)
+	}
+
+	height, err := strconv.Atoi(heightStr)
+	if err != nil || height < 0 {
+		return 0, "", fmt.Errorf("altura de checkpoint inválida: %q", heightStr)
+	}
+
+	return height, hash, nil
+}
+
+// checkCheckpoint verifica que el bloque no contradiga un checkpoint conocido
+func (bc *Blockchain) checkCheckpoint(block *Block) error {
+	hash, exists := bc.Checkpoints[block.Index]
+	if exists && hash != block.Hash {
+		return fmt.Errorf("el bloque %d contradice el checkpoint (esperado %s)", block.Index, hash)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+	"minichain/utils"
+)
+
+// BlockReward es la recompensa que recibe quien mina un bloque (50 MTC)
+// Es la única forma de crear moneda nueva en la cadena. No se debe modificar.
+var BlockReward = utils.MTC(50)
+
+// NewCoinbaseTx crea la transacción de recompensa del bloque
+// No tiene remitente ni firma: la valida la propia regla de consenso
+// El nonce guarda el índice del bloque para que cada coinbase sea única
+func NewCoinbaseTx(miner string, amount *big.Int, blockIndex int) *Transaction {
+	tx := &Transaction{
+		From:   "",
+		To:     miner,
+		Amount: amount,
+		Nonce:  blockIndex,
+	}
+	tx.TxHash = tx.calculateHash()
+	return tx
+}
+
+// IsCoinbase verifica si es la transacción de recompensa del bloque
+func (tx *Transaction) IsCoinbase() bool {
+	return tx.From == "" && tx.To != ""
+}
+
+// validateCoinbase comprueba las reglas de la coinbase de un bloque:
+// como mucho una, siempre la primera, y por el importe exacto de la recompensa
+func validateCoinbase(block *Block) error {
+	for i, tx := range block.Transactions {
+		if !tx.IsCoinbase() {
+			continue
+		}
+		if i != 0 {
+			return fmt.Errorf("coinbase en posición %d (debe ser la primera)", i)
+		}
+		if tx.Amount == nil || tx.Amount.Cmp(BlockReward) != 0 {
+			return fmt.Errorf("recompensa incorrecta: %s (esperada %s)",
+				utils.FormatMTC(tx.Amount), utils.FormatMTC(BlockReward))
+		}
+		if tx.Nonce != block.Index {
+			return fmt.Errorf("coinbase del bloque %d incluida en el bloque %d", tx.Nonce, block.Index)
+		}
+		if tx.Signature != "" || len(tx.Data) > 0 {
+			return fmt.Errorf("la coinbase no puede llevar firma ni datos")
+		}
+		if tx.TxHash != tx.calculateHash() {
+			return fmt.Errorf("hash de la coinbase incorrecto")
+		}
+	}
+	return nil
+}
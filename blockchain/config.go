@@ -0,0 +1,70 @@
+package blockchain
+
+import (
+	"fmt"
+	"minichain/evm"
+)
+
+// ChainConfig fija desde qué altura entra en vigor cada cambio del protocolo
+//
+// Así la red se actualiza sin que todos los nodos tengan que reiniciar a la vez:
+// los nodos nuevos aplican las reglas viejas hasta la altura de activación.
+// Un valor negativo deja el cambio desactivado.
+type ChainConfig struct {
+	PrevRandaoBlock int `json:"prevRandaoBlock"` // Opcode PREVRANDAO en la EVM (antes, DIFFICULTY)
+	BatchBlock      int `json:"batchBlock"`      // Transacciones de lote (transferencias firmadas off-chain)
+	BaseFeeBlock    int `json:"baseFeeBlock"`    // Base fee por bloque que se quema (EIP-1559)
+	MultisigBlock   int `json:"multisigBlock"`   // Transacciones desde cuentas multifirma m-de-n
+}
+
+// DefaultChainConfig activa todos los cambios desde el génesis
+func DefaultChainConfig() *ChainConfig {
+	return &ChainConfig{
+		PrevRandaoBlock: 0,
+		BatchBlock:      0,
+		BaseFeeBlock:    0,
+		MultisigBlock:   0,
+	}
+}
+
+// isForked indica si un cambio activado en forkBlock rige en el bloque number
+func isForked(forkBlock, number int) bool {
+	return forkBlock >= 0 && number >= forkBlock
+}
+
+// IsPrevRandao indica si PREVRANDAO existe en el bloque number
+func (c *ChainConfig) IsPrevRandao(number int) bool {
+	return isForked(c.PrevRandaoBlock, number)
+}
+
+// IsBatch indica si se aceptan transacciones de lote en el bloque number
+func (c *ChainConfig) IsBatch(number int) bool {
+	return isForked(c.BatchBlock, number)
+}
+
+// IsBaseFee indica si el bloque number tiene base fee
+func (c *ChainConfig) IsBaseFee(number int) bool {
+	return isForked(c.BaseFeeBlock, number)
+}
+
+// IsMultisig indica si se aceptan transacciones multifirma en el bloque number
+func (c *ChainConfig) IsMultisig(number int) bool {
+	return isForked(c.MultisigBlock, number)
+}
+
+// DisabledOpcodes devuelve los opcodes que aún no existen en el bloque number
+// PREVRANDAO no está: antes de su fork el opcode es DIFFICULTY (ver NewBlockContext)
+func (c *ChainConfig) DisabledOpcodes(number int) map[evm.OpCode]bool {
+	return make(map[evm.OpCode]bool)
+}
+
+// checkForks rechaza transacciones que usan cambios aún no activos en el bloque number
+func (c *ChainConfig) checkForks(tx *Transaction, number int) error {
+	if tx.IsBatch() && !c.IsBatch(number) {
+		return fmt.Errorf("%w: los lotes se activan en el bloque %d", ErrNotActive, c.BatchBlock)
+	}
+	if tx.IsMultisig() && !c.IsMultisig(number) {
+		return fmt.Errorf("%w: las multifirmas se activan en el bloque %d", ErrNotActive, c.MultisigBlock)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package blockchain
+
+import (
+	"fmt"
+	"minichain/utils"
+	"time"
+)
+
+// ConsensusEngine decide quién puede producir un bloque y cómo se comprueba
+// Permite cambiar Proof of Work por otros mecanismos sin tocar la cadena
+type ConsensusEngine interface {
+	// Name devuelve el nombre del motor (para mostrarlo)
+	Name() string
+
+	// Prepare completa la cabecera antes de ejecutar el bloque, con el estado
+	// del bloque padre (PoS: elige al productor). Falla si este nodo no puede
+	// producir el bloque.
+	Prepare(bc *Blockchain, block *Block) error
+
+	// Seal sella un bloque ya construido (PoW: busca el nonce; PoS: lo firma)
+	Seal(bc *Blockchain, block *Block) error
+
+	// VerifySeal comprueba que el sello de un bloque es válido
+	VerifySeal(bc *Blockchain, block *Block) error
+}
+
+// ProofOfWork es el motor de consenso clásico basado en minado
+type ProofOfWork struct{}
+
+// NewProofOfWork crea el motor de Proof of Work
+func NewProofOfWork() *ProofOfWork {
+	return &ProofOfWork{}
+}
+
+// Name devuelve el nombre del motor
+func (pow *ProofOfWork) Name() string {
+	return "Proof of Work"
+}
+
+// Prepare no hace nada: en PoW cualquiera puede minar
+func (pow *ProofOfWork) Prepare(bc *Blockchain, block *Block) error {
+	return nil
+}
+
+// Seal mina el bloque con el objetivo actual de la cadena
+func (pow *ProofOfWork) Seal(bc *Blockchain, block *Block) error {
+	block.Bits = bc.Bits
+
+	start := time.Now()
+	attempts := block.MineBlock(bc.MinerThreads)
+	bc.recordHashrate(attempts, time.Since(start))
+	fmt.Printf("⚡ Hashrate: %.0f H/s\n", bc.Hashrate())
+
+	return nil
+}
+
+// VerifySeal comprueba el hash, el PoW y que el objetivo no sea más fácil que el de la cadena
+func (pow *ProofOfWork) VerifySeal(bc *Blockchain, block *Block) error {
+	if !block.IsValid() {
+		return fmt.Errorf("hash o proof of work inválido")
+	}
+
+	if utils.CompactToTarget(block.Bits).Cmp(utils.CompactToTarget(bc.Bits)) > 0 {
+		return fmt.Errorf("usa un objetivo más fácil que el permitido (bits %08x)", block.Bits)
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"minichain/evm"
+)
+
+// Motivos por los que una transacción no se admite (ver Transaction.Validate)
+//
+// Los errores de validación los envuelven con %w, así que quien los recibe (la
+// consola, la API) puede distinguir el motivo con errors.Is sin leer el texto.
+var (
+	ErrNotSigned         = errors.New("transacción no firmada")
+	ErrInvalidSignature  = errors.New("firma inválida")
+	ErrWrongChain        = errors.New("transacción firmada para otra red")
+	ErrInvalidHash       = errors.New("hash de transacción incorrecto")
+	ErrMalformed         = errors.New("transacción mal formada")
+	ErrInvalidAmount     = errors.New("monto inválido")
+	ErrNoPurpose         = errors.New("transacción sin propósito")
+	ErrNonceTooLow       = errors.New("nonce ya usado")
+	ErrNonceTooHigh      = errors.New("nonce demasiado alto")
+	ErrInsufficientFunds = errors.New("saldo insuficiente")
+	ErrInvalidGasPrice   = errors.New("precio del gas inválido")
+	ErrIntrinsicGas      = errors.New("límite de gas insuficiente")
+	ErrGasLimit          = errors.New("límite de gas mayor que el del bloque")
+	ErrNotActive         = errors.New("tipo de transacción aún no activo")
+	ErrInvalidBatch      = errors.New("lote inválido")
+)
+
+// revertError es el error de una llamada que terminó en REVERT, con su motivo
+// Se distingue de otros fallos con errors.Is(err, evm.ErrExecutionReverted)
+func revertError(data []byte) error {
+	if reason := evm.RevertReason(data); reason != "" {
+		return fmt.Errorf("%w: %s", evm.ErrExecutionReverted, reason)
+	}
+	return evm.ErrExecutionReverted
+}
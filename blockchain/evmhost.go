@@ -0,0 +1,77 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+	"minichain/evm"
+)
+
+// evmHost da a la EVM acceso al estado de la cadena durante una ejecución
+// Cada snapshot guarda cuentas y storage de todos los contratos (ver snapshotState)
+type evmHost struct {
+	bc        *Blockchain
+	snapshots []*chainStateSnapshot
+	logs      []*evm.Log // Eventos emitidos, en orden
+	logMarks  []int      // len(logs) en cada snapshot
+}
+
+// newEVMHost crea el acceso al estado para una ejecución
+func (bc *Blockchain) newEVMHost() *evmHost {
+	return &evmHost{bc: bc}
+}
+
+func (h *evmHost) Contract(address string) *evm.Contract {
+	return h.bc.Contracts[address]
+}
+
+// Balance no usa AccountState.GetBalance: consultar el saldo (BALANCE) no
+// debe crear cuentas vacías en el estado
+func (h *evmHost) Balance(address string) *big.Int {
+	account, exists := h.bc.AccountState.Accounts[address]
+	if !exists {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(account.Balance)
+}
+
+func (h *evmHost) Transfer(from, to string, amount *big.Int) error {
+	if err := h.bc.AccountState.SubtractBalance(from, amount); err != nil {
+		return err
+	}
+	h.bc.AccountState.AddBalance(to, amount)
+	return nil
+}
+
+func (h *evmHost) AddLog(log *evm.Log) {
+	h.logs = append(h.logs, log)
+}
+
+func (h *evmHost) Snapshot() int {
+	h.snapshots = append(h.snapshots, h.bc.snapshotState())
+	h.logMarks = append(h.logMarks, len(h.logs))
+	return len(h.snapshots) - 1
+}
+
+func (h *evmHost) RevertToSnapshot(id int) {
+	h.bc.revertState(h.snapshots[id])
+	h.logs = h.logs[:h.logMarks[id]]
+	h.snapshots = h.snapshots[:id]
+	h.logMarks = h.logMarks[:id]
+}
+
+// chainLogs devuelve los eventos emitidos en el formato de los recibos
+// Los topics van como palabras de 32 bytes en hex con 0x (como en eth_getLogs)
+func (h *evmHost) chainLogs() []*Log {
+	if len(h.logs) == 0 {
+		return nil
+	}
+	logs := make([]*Log, len(h.logs))
+	for i, log := range h.logs {
+		topics := make([]string, len(log.Topics))
+		for j, topic := range log.Topics {
+			topics[j] = fmt.Sprintf("0x%064x", topic)
+		}
+		logs[i] = &Log{Address: log.Address, Topics: topics, Data: log.Data}
+	}
+	return logs
+}
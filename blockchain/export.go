@@ -0,0 +1,94 @@
+package blockchain
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// chainFileFormat identifica los archivos de exportación de cadena
+const chainFileFormat = "minichain-chain"
+
+// chainFileHeader es la primera línea de un archivo exportado
+// Un archivo solo se puede importar sobre el mismo génesis del que salió
+type chainFileHeader struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+	ChainID uint64 `json:"chainId"`
+	Genesis string `json:"genesis"` // Hash del bloque #0
+	Blocks  int    `json:"blocks"`  // Bloques que siguen (sin contar el génesis)
+}
+
+// ExportChain escribe todos los bloques posteriores al génesis, uno por línea (JSON)
+// El génesis no se exporta: quien importa lo crea desde la misma especificación
+func (bc *Blockchain) ExportChain(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+
+	header := chainFileHeader{
+		Format:  chainFileFormat,
+		Version: 1,
+		ChainID: bc.ChainID,
+		Genesis: bc.Blocks[0].Hash,
+		Blocks:  len(bc.Blocks) - 1,
+	}
+	if err := encoder.Encode(header); err != nil {
+		return fmt.Errorf("error escribiendo cabecera: %v", err)
+	}
+
+	for _, block := range bc.Blocks[1:] {
+		if err := encoder.Encode(block); err != nil {
+			return fmt.Errorf("error escribiendo bloque %d: %v", block.Index, err)
+		}
+	}
+
+	return nil
+}
+
+// ImportChain lee un archivo exportado y añade sus bloques con ImportBlock
+//
+// Cada bloque se vuelve a validar y ejecutar, así que el estado final es el mismo
+// que el del nodo que exportó. Los bloques que la cadena ya tiene se comprueban
+// y se saltan. Devuelve cuántos bloques nuevos se añadieron.
+func (bc *Blockchain) ImportChain(r io.Reader) (int, error) {
+	decoder := json.NewDecoder(bufio.NewReader(r))
+
+	var header chainFileHeader
+	if err := decoder.Decode(&header); err != nil {
+		return 0, fmt.Errorf("cabecera inválida: %v", err)
+	}
+	if header.Format != chainFileFormat || header.Version != 1 {
+		return 0, fmt.Errorf("formato desconocido: %s v%d", header.Format, header.Version)
+	}
+	if header.ChainID != bc.ChainID {
+		return 0, fmt.Errorf("el archivo es de la red %d y esta es la %d", header.ChainID, bc.ChainID)
+	}
+	if header.Genesis != bc.Blocks[0].Hash {
+		return 0, fmt.Errorf("el archivo sale de otro génesis (%s)", header.Genesis)
+	}
+
+	bc.startSync(header.Blocks)
+	defer bc.endSync()
+
+	imported := 0
+	for i := 0; i < header.Blocks; i++ {
+		var block Block
+		if err := decoder.Decode(&block); err != nil {
+			return imported, fmt.Errorf("bloque %d ilegible: %v", i+1, err)
+		}
+
+		if block.Index < len(bc.Blocks) {
+			if bc.Blocks[block.Index].Hash != block.Hash {
+				return imported, fmt.Errorf("el bloque %d no coincide con el de esta cadena", block.Index)
+			}
+			continue
+		}
+
+		if err := bc.ImportBlock(&block); err != nil {
+			return imported, err
+		}
+		imported++
+	}
+
+	return imported, nil
+}
@@ -0,0 +1,72 @@
+package blockchain
+
+import "fmt"
+
+// MaxFilterBlocks es el rango máximo de bloques de una consulta de logs
+const MaxFilterBlocks = 10000
+
+// FilterQuery selecciona logs por rango de bloques, contrato y topics
+//
+// Topics se compara por posición: en cada una vale cualquiera de los topics de
+// la lista, y una lista vacía vale cualquiera (como en eth_getLogs). Un log con
+// menos topics que posiciones con condición no coincide.
+type FilterQuery struct {
+	FromBlock int
+	ToBlock   int
+	Addresses []string   // Contratos que los emitieron (vacío = cualquiera)
+	Topics    [][]string // Condición por posición
+}
+
+// FilterLogs devuelve los logs de la cadena que cumplen la consulta, en orden
+// Recorre los recibos guardados de cada bloque del rango
+func (bc *Blockchain) FilterLogs(q FilterQuery) ([]*Log, error) {
+	if q.FromBlock < 0 || q.FromBlock > q.ToBlock {
+		return nil, fmt.Errorf("rango de bloques inválido: %d-%d", q.FromBlock, q.ToBlock)
+	}
+	if q.ToBlock-q.FromBlock >= MaxFilterBlocks {
+		return nil, fmt.Errorf("rango de bloques demasiado grande: %d (máximo %d)",
+			q.ToBlock-q.FromBlock+1, MaxFilterBlocks)
+	}
+	if q.ToBlock >= len(bc.Blocks) {
+		q.ToBlock = len(bc.Blocks) - 1
+	}
+
+	logs := []*Log{}
+	for number := q.FromBlock; number <= q.ToBlock; number++ {
+		for _, receipt := range bc.receipts[bc.Blocks[number].Hash] {
+			for _, log := range receipt.Logs {
+				if q.matches(log) {
+					logs = append(logs, log)
+				}
+			}
+		}
+	}
+	return logs, nil
+}
+
+// matches dice si un log cumple las condiciones de dirección y topics
+func (q *FilterQuery) matches(log *Log) bool {
+	if len(q.Addresses) > 0 && !contains(q.Addresses, log.Address) {
+		return false
+	}
+
+	for i, wanted := range q.Topics {
+		if len(wanted) == 0 {
+			continue
+		}
+		if i >= len(log.Topics) || !contains(wanted, log.Topics[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// contains dice si value está en list
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
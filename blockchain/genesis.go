@@ -0,0 +1,159 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"minichain/evm"
+	"minichain/mempool"
+	"minichain/utils"
+	"os"
+	"regexp"
+	"runtime"
+	"time"
+)
+
+// DefaultChainID identifica a las cadenas creadas sin archivo de génesis
+const DefaultChainID = 1337
+
+// GenesisAccount es el reparto inicial de una cuenta
+// Las cantidades van en unidades base (1 MTC = 10^18), como números JSON enteros
+type GenesisAccount struct {
+	Balance *big.Int `json:"balance"`
+	Stake   *big.Int `json:"stake,omitempty"` // Bloqueado desde el inicio (PoS)
+}
+
+// Genesis es la especificación de una red: de ella sale el bloque #0
+//
+// Dos nodos con la misma especificación obtienen exactamente el mismo génesis
+// (mismo hash), así que pueden intercambiar bloques. El hash de la especificación
+// va en la cabecera del génesis: cualquier cambio produce otra red.
+type Genesis struct {
+	ChainID    uint64                    `json:"chainId"`
+	Difficulty int                       `json:"difficulty"`
+	GasLimit   uint64                    `json:"gasLimit,omitempty"` // 0 = DefaultBlockGasLimit
+	Timestamp  int64                     `json:"timestamp"`          // Segundos Unix
+	Config     *ChainConfig              `json:"config,omitempty"`   // nil = todo activo desde el génesis
+	Alloc      map[string]GenesisAccount `json:"alloc"`
+}
+
+// addressPattern son las direcciones válidas: 40 caracteres hexadecimales
+var addressPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// LoadGenesis lee y valida una especificación de génesis en JSON
+func LoadGenesis(path string) (*Genesis, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer %s: %v", path, err)
+	}
+
+	var genesis Genesis
+	if err := json.Unmarshal(data, &genesis); err != nil {
+		return nil, fmt.Errorf("génesis inválido en %s: %v", path, err)
+	}
+
+	if err := genesis.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &genesis, nil
+}
+
+// Validate comprueba que la especificación tenga sentido
+func (g *Genesis) Validate() error {
+	if g.ChainID == 0 {
+		return fmt.Errorf("génesis sin chainId")
+	}
+	if g.Difficulty < 1 || g.Difficulty > utils.MaxDifficulty {
+		return fmt.Errorf("dificultad inválida: %d (de 1 a %d)", g.Difficulty, utils.MaxDifficulty)
+	}
+
+	for address, account := range g.Alloc {
+		if !addressPattern.MatchString(address) {
+			return fmt.Errorf("dirección inválida en alloc: %q", address)
+		}
+		if account.Balance == nil {
+			return fmt.Errorf("alloc sin saldo para %s", address)
+		}
+		if account.Balance.Sign() < 0 || (account.Stake != nil && account.Stake.Sign() < 0) {
+			return fmt.Errorf("saldo negativo en alloc para %s", address)
+		}
+	}
+
+	return nil
+}
+
+// Hash identifica la especificación (encoding/json ordena las claves de los maps)
+func (g *Genesis) Hash() string {
+	data, _ := json.Marshal(g)
+	return utils.CalculateHash(string(data))
+}
+
+// NewBlockchainFromGenesis crea una blockchain cuyo bloque #0 sale de la especificación
+func NewBlockchainFromGenesis(g *Genesis) (*Blockchain, error) {
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+
+	config := g.Config
+	if config == nil {
+		config = DefaultChainConfig()
+	}
+
+	gasLimit := g.GasLimit
+	if gasLimit == 0 {
+		gasLimit = DefaultBlockGasLimit
+	}
+
+	// Traducir la dificultad en ceros a un objetivo numérico
+	bits := utils.TargetToCompact(utils.TargetFromDifficulty(g.Difficulty))
+
+	bc := &Blockchain{
+		Difficulty:   g.Difficulty,
+		Bits:         bits,
+		ChainID:      g.ChainID,
+		AccountState: NewAccountState(),
+		Contracts:    make(map[string]*evm.Contract),
+		Checkpoints:  make(map[int]string),
+		Config:       config,
+		MinerThreads: runtime.NumCPU(),
+		GasLimit:     gasLimit,
+		Engine:       NewProofOfWork(),
+
+		reservedNonces: make(map[string]map[int]time.Time),
+		pendingWork:    make(map[string]*Block),
+		receipts:       make(map[string][]*Receipt),
+		addressIndex:   make(map[string][]TxLocation),
+		txLookup:       make(map[string]TxLocation),
+		blockNumbers:   make(map[string]int),
+	}
+
+	bc.Mempool = mempool.New(poolState{bc})
+
+	// Reparto inicial (el stake queda en la dirección de staking)
+	for address, account := range g.Alloc {
+		bc.AccountState.AddBalance(address, account.Balance)
+		if account.Stake != nil && account.Stake.Sign() > 0 {
+			bc.AccountState.AddBalance(StakingAddress, account.Stake)
+			bc.AccountState.AddStake(address, account.Stake)
+		}
+	}
+
+	// El génesis fija el estado inicial y la especificación de la que sale
+	genesisBlock := NewGenesisBlock()
+	genesisBlock.Timestamp = time.Unix(g.Timestamp, 0).UTC()
+	genesisBlock.Bits = bits
+	genesisBlock.Extra = g.Hash()
+	if config.IsBaseFee(0) {
+		genesisBlock.BaseFee = big.NewInt(InitialBaseFee)
+	}
+	genesisBlock.StateRoot = bc.StateRoot()
+	genesisBlock.ReceiptRoot = utils.EmptyRoot // El génesis no tiene transacciones
+
+	// Minado secuencial: con varios hilos el nonce ganador podría variar entre nodos
+	genesisBlock.MineBlock(1)
+
+	bc.appendBlock(genesisBlock)
+
+	return bc, nil
+}
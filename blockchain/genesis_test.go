@@ -0,0 +1,36 @@
+package blockchain
+
+import (
+	"testing"
+
+	"minichain/utils"
+)
+
+// TestGenesisDifficultyRange: fuera de 1..63 el objetivo sería 0 (nada lo cumple)
+// o el desplazamiento se saldría de rango
+func TestGenesisDifficultyRange(t *testing.T) {
+	for _, difficulty := range []int{-1, 0, 64, 65, 1000} {
+		genesis := &Genesis{ChainID: 7, Difficulty: difficulty}
+		if err := genesis.Validate(); err == nil {
+			t.Errorf("dificultad %d aceptada", difficulty)
+		}
+	}
+	for _, difficulty := range []int{1, utils.MaxDifficulty} {
+		genesis := &Genesis{ChainID: 7, Difficulty: difficulty}
+		if err := genesis.Validate(); err != nil {
+			t.Errorf("dificultad %d: %v", difficulty, err)
+		}
+	}
+}
+
+func TestTargetFromDifficultyClamps(t *testing.T) {
+	floor := utils.TargetFromDifficulty(utils.MaxDifficulty)
+	if floor.Sign() <= 0 {
+		t.Fatalf("objetivo %s con la dificultad máxima", floor)
+	}
+	for _, difficulty := range []int{64, 65, 1000} {
+		if target := utils.TargetFromDifficulty(difficulty); target.Cmp(floor) != 0 {
+			t.Errorf("dificultad %d: objetivo %s, esperaba %s", difficulty, target, floor)
+		}
+	}
+}
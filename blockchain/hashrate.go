@@ -0,0 +1,32 @@
+package blockchain
+
+import (
+	"math"
+	"time"
+)
+
+// hashrateSmoothing es el peso de la última medida en la media exponencial
+// Más alto = reacciona antes a cambios, pero oscila más entre bloques
+const hashrateSmoothing = 0.3
+
+// recordHashrate añade la medida de un bloque minado a la media suavizada
+func (bc *Blockchain) recordHashrate(attempts int64, elapsed time.Duration) {
+	if elapsed <= 0 {
+		return
+	}
+
+	sample := float64(attempts) / elapsed.Seconds()
+
+	previous := bc.Hashrate()
+	if previous > 0 {
+		sample = hashrateSmoothing*sample + (1-hashrateSmoothing)*previous
+	}
+
+	bc.hashrate.Store(math.Float64bits(sample))
+}
+
+// Hashrate devuelve los hashes por segundo de este nodo (media exponencial)
+// Es 0 hasta que se mina el primer bloque
+func (bc *Blockchain) Hashrate() float64 {
+	return math.Float64frombits(bc.hashrate.Load())
+}
@@ -0,0 +1,252 @@
+package blockchain
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"minichain/crypto"
+	"minichain/mempool"
+	"minichain/utils"
+)
+
+// trustingPool admite cualquier transacción: simula un nodo que produce
+// bloques sin validar lo que mete en ellos
+type trustingPool struct{ poolState }
+
+func (trustingPool) Validate(mempool.Tx, int) error { return nil }
+
+// importPair crea dos cadenas con el mismo génesis: la que produce el bloque
+// (sin validar su mempool) y la que lo importa
+func importPair(t *testing.T, funded string) (source, target *Blockchain) {
+	t.Helper()
+	genesis := &Genesis{
+		ChainID:    7,
+		Difficulty: 1,
+		Timestamp:  1_700_000_000,
+		Alloc:      map[string]GenesisAccount{funded: {Balance: utils.MTC(10)}},
+	}
+	var err error
+	if source, err = NewBlockchainFromGenesis(genesis); err != nil {
+		t.Fatal(err)
+	}
+	if target, err = NewBlockchainFromGenesis(genesis); err != nil {
+		t.Fatal(err)
+	}
+	source.Mempool = mempool.New(trustingPool{poolState{source}})
+	return source, target
+}
+
+// mineWith sella en source un bloque con tx y devuelve el bloque
+func mineWith(t *testing.T, source *Blockchain, tx *Transaction) *Block {
+	t.Helper()
+	if err := source.AddTransaction(tx); err != nil {
+		t.Fatal(err)
+	}
+	source.MineBlock()
+	return source.Blocks[len(source.Blocks)-1]
+}
+
+func newKey(t *testing.T) *crypto.KeyPair {
+	t.Helper()
+	keyPair, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyPair
+}
+
+func TestImportBlockValidTransfer(t *testing.T) {
+	victim := newKey(t)
+	source, target := importPair(t, victim.GetAddress())
+
+	tx := NewTransaction(victim.GetAddress(), newKey(t).GetAddress(), utils.MTC(1), 0)
+	if err := tx.Sign(victim, source.ChainID); err != nil {
+		t.Fatal(err)
+	}
+	if err := target.ImportBlock(mineWith(t, source, tx)); err != nil {
+		t.Fatalf("ImportBlock: %v", err)
+	}
+}
+
+func TestImportBlockRejectsUnsigned(t *testing.T) {
+	victim := newKey(t)
+	source, target := importPair(t, victim.GetAddress())
+
+	tx := NewTransaction(victim.GetAddress(), newKey(t).GetAddress(), utils.MTC(5), 0)
+	tx.ChainID = source.ChainID
+	tx.TxHash = tx.calculateHash()
+
+	expectRejected(t, target, mineWith(t, source, tx), victim.GetAddress(), ErrNotSigned)
+}
+
+func TestImportBlockRejectsWrongSigner(t *testing.T) {
+	victim, attacker := newKey(t), newKey(t)
+	source, target := importPair(t, victim.GetAddress())
+
+	// Firmada por el atacante pero a nombre de la víctima
+	tx := NewTransaction(attacker.GetAddress(), attacker.GetAddress(), utils.MTC(5), 0)
+	if err := tx.Sign(attacker, source.ChainID); err != nil {
+		t.Fatal(err)
+	}
+	tx.From = victim.GetAddress()
+	tx.TxHash = tx.calculateHash()
+
+	expectRejected(t, target, mineWith(t, source, tx), victim.GetAddress(), ErrInvalidSignature)
+}
+
+// expectRejected comprueba que target rechaza block con want (cualquier error
+// si want es nil) sin tocar la cadena ni el estado
+func expectRejected(t *testing.T, target *Blockchain, block *Block, victim string, want error) {
+	t.Helper()
+	before := new(big.Int).Set(target.GetBalance(victim))
+	height := len(target.Blocks)
+
+	err := target.ImportBlock(block)
+	if err == nil || (want != nil && !errors.Is(err, want)) {
+		t.Fatalf("ImportBlock = %v, esperaba %v", err, want)
+	}
+	if len(target.Blocks) != height {
+		t.Fatalf("la cadena tiene %d bloques, esperaba %d", len(target.Blocks), height)
+	}
+	if after := target.GetBalance(victim); after.Cmp(before) != 0 {
+		t.Fatalf("saldo de la víctima %s, esperaba %s", after, before)
+	}
+}
+
+// TestImportBlockRejectsNegativeValues: valores negativos (posibles en un
+// archivo JSON) se rechazan en lugar de hacer panic al codificar en RLP
+func TestImportBlockRejectsNegativeValues(t *testing.T) {
+	cases := map[string]func(tx *Transaction){
+		"amount":    func(tx *Transaction) { tx.Amount = big.NewInt(-1) },
+		"gasPrice":  func(tx *Transaction) { tx.GasPrice = big.NewInt(-1) },
+		"gasTipCap": func(tx *Transaction) { tx.GasTipCap = big.NewInt(-1) },
+		"nonce":     func(tx *Transaction) { tx.Nonce = -1 },
+		"batch":     func(tx *Transaction) { tx.Batch = []*TransferAuthorization{{Amount: big.NewInt(-1)}} },
+	}
+	for name, corrupt := range cases {
+		t.Run(name, func(t *testing.T) {
+			sender := newKey(t)
+			source, target := importPair(t, sender.GetAddress())
+
+			tx := NewTransaction(sender.GetAddress(), newKey(t).GetAddress(), utils.MTC(1), 0)
+			if err := tx.Sign(sender, source.ChainID); err != nil {
+				t.Fatal(err)
+			}
+			block := mineWith(t, source, tx)
+			corrupt(block.Transactions[len(block.Transactions)-1])
+
+			expectRejected(t, target, block, sender.GetAddress(), ErrMalformed)
+		})
+	}
+}
+
+// forgeBlock sella sobre la cabeza de bc un bloque con txs aunque alguna no se
+// pueda ejecutar, como haría un nodo que no comprueba lo que mete en sus
+// bloques. La cabecera (contadores y roots) es la que da ejecutarlo; el estado
+// de bc queda como estaba.
+func forgeBlock(t *testing.T, bc *Blockchain, txs ...*Transaction) *Block {
+	t.Helper()
+	head := bc.Blocks[len(bc.Blocks)-1]
+	block := &Block{
+		Index:        head.Index + 1,
+		Timestamp:    head.Timestamp.Add(time.Second),
+		Transactions: txs,
+		PreviousHash: head.Hash,
+		BaseFee:      bc.BaseFee(),
+	}
+	block.TxRoot = block.CalculateTxRoot()
+
+	snapshot := bc.snapshotState()
+	totals, _ := bc.applyBlock(block)
+	totals.setOn(block)
+	block.StateRoot = bc.StateRoot()
+	block.ReceiptRoot = receiptRoot(block)
+	bc.revertState(snapshot)
+
+	if err := bc.Engine.Seal(bc, block); err != nil {
+		t.Fatal(err)
+	}
+	return block
+}
+
+func TestImportBlockRejectsReplayedTransaction(t *testing.T) {
+	sender := newKey(t)
+	source, target := importPair(t, sender.GetAddress())
+
+	tx := NewTransaction(sender.GetAddress(), newKey(t).GetAddress(), utils.MTC(1), 0)
+	if err := tx.Sign(sender, source.ChainID); err != nil {
+		t.Fatal(err)
+	}
+	if err := target.ImportBlock(mineWith(t, source, tx)); err != nil {
+		t.Fatal(err)
+	}
+
+	// La misma transacción otra vez: el índice por hash pasaría al bloque nuevo
+	expectRejected(t, target, forgeBlock(t, target, tx), sender.GetAddress(), nil)
+	if location, _ := target.ReadTxLookupEntry(tx.Hash()); location.BlockNumber != 1 {
+		t.Fatalf("la transacción apunta al bloque %d, esperaba 1", location.BlockNumber)
+	}
+
+	// Otra transacción con el nonce ya usado
+	stale := NewTransaction(sender.GetAddress(), newKey(t).GetAddress(), utils.MTC(2), 0)
+	if err := stale.Sign(sender, source.ChainID); err != nil {
+		t.Fatal(err)
+	}
+	expectRejected(t, target, forgeBlock(t, target, stale), sender.GetAddress(), nil)
+}
+
+func TestImportBlockRejectsInsufficientBalance(t *testing.T) {
+	sender := newKey(t)
+	source, target := importPair(t, sender.GetAddress())
+
+	// Dos transacciones válidas por separado; la segunda ya no tiene saldo
+	first := NewTransaction(sender.GetAddress(), newKey(t).GetAddress(), utils.MTC(6), 0)
+	second := NewTransaction(sender.GetAddress(), newKey(t).GetAddress(), utils.MTC(6), 1)
+	for _, tx := range []*Transaction{first, second} {
+		if err := tx.Sign(sender, source.ChainID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Ni siquiera la primera se queda: el bloque entero se descarta
+	expectRejected(t, target, forgeBlock(t, target, first, second), sender.GetAddress(), nil)
+	if nonce := target.GetNonce(sender.GetAddress()); nonce != 0 {
+		t.Fatalf("nonce %d tras el rechazo, esperaba 0", nonce)
+	}
+}
+
+// TestMineBlockDropsFailedTransactions: lo que no se puede ejecutar no entra en
+// el bloque, así que los demás nodos lo pueden importar
+func TestMineBlockDropsFailedTransactions(t *testing.T) {
+	sender := newKey(t)
+	source, target := importPair(t, sender.GetAddress())
+
+	valid := NewTransaction(sender.GetAddress(), newKey(t).GetAddress(), utils.MTC(1), 0)
+	overdraft := NewTransaction(sender.GetAddress(), newKey(t).GetAddress(), utils.MTC(6), 1)
+	for _, tx := range []*Transaction{valid, overdraft} {
+		if err := tx.Sign(sender, source.ChainID); err != nil {
+			t.Fatal(err)
+		}
+		if err := source.AddTransaction(tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Con el saldo que queda, la segunda ya no se puede pagar
+	for _, bc := range []*Blockchain{source, target} {
+		if err := bc.AccountState.SubtractBalance(sender.GetAddress(), utils.MTC(3)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	source.MineBlock()
+
+	block := source.Blocks[len(source.Blocks)-1]
+	if len(block.Transactions) != 1 || block.Transactions[0].Hash() != valid.Hash() {
+		t.Fatalf("el bloque lleva %d transacciones, esperaba solo la válida", len(block.Transactions))
+	}
+	if err := target.ImportBlock(block); err != nil {
+		t.Fatalf("ImportBlock: %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package blockchain
+
+// TxLocation ubica una transacción confirmada: bloque y posición dentro de él
+type TxLocation struct {
+	BlockNumber int
+	TxIndex     int
+}
+
+// txAddresses devuelve las direcciones que aparecen en una transacción (sin repetir)
+// Remitente, destinatario, contrato creado y las cuentas de un lote
+func txAddresses(tx *Transaction) []string {
+	seen := make(map[string]bool)
+	var addresses []string
+	add := func(address string) {
+		if address != "" && !seen[address] {
+			seen[address] = true
+			addresses = append(addresses, address)
+		}
+	}
+
+	if !tx.IsCoinbase() {
+		add(tx.From)
+	}
+	add(tx.To)
+	add(tx.ContractAddress)
+	for _, auth := range tx.Batch {
+		add(auth.From)
+		add(auth.To)
+	}
+
+	return addresses
+}
+
+// indexBlock añade el bloque al índice por hash y sus transacciones a los
+// índices por hash y por dirección
+// Los bloques se indexan en orden, así que cada lista queda ordenada
+func (bc *Blockchain) indexBlock(block *Block) {
+	bc.WriteHeaderNumber(block.Hash, block.Index)
+	for i, tx := range block.Transactions {
+		location := TxLocation{BlockNumber: block.Index, TxIndex: i}
+		bc.WriteTxLookupEntry(tx.Hash(), location)
+		for _, address := range txAddresses(tx) {
+			bc.addressIndex[address] = append(bc.addressIndex[address], location)
+		}
+	}
+}
+
+// GetAddressHistory devuelve dónde aparece una dirección en la cadena, de la
+// transacción más antigua a la más reciente
+// Usa el índice por dirección: no recorre la cadena
+func (bc *Blockchain) GetAddressHistory(address string) []TxLocation {
+	return append([]TxLocation(nil), bc.addressIndex[address]...)
+}
+
+// TransactionAt devuelve la transacción de una posición de la cadena
+func (bc *Blockchain) TransactionAt(location TxLocation) *Transaction {
+	return bc.Blocks[location.BlockNumber].Transactions[location.TxIndex]
+}
+
+// WriteTxLookupEntry registra dónde se confirmó una transacción
+func (bc *Blockchain) WriteTxLookupEntry(hash string, location TxLocation) {
+	bc.txLookup[hash] = location
+}
+
+// ReadTxLookupEntry devuelve dónde se confirmó una transacción (false si no está)
+func (bc *Blockchain) ReadTxLookupEntry(hash string) (TxLocation, bool) {
+	location, ok := bc.txLookup[hash]
+	return location, ok
+}
+
+// WriteHeaderNumber registra la altura de un bloque por su hash
+func (bc *Blockchain) WriteHeaderNumber(hash string, number int) {
+	bc.blockNumbers[hash] = number
+}
+
+// ReadHeaderNumber devuelve la altura del bloque con ese hash
+func (bc *Blockchain) ReadHeaderNumber(hash string) (int, bool) {
+	number, ok := bc.blockNumbers[hash]
+	return number, ok
+}
+
+// GetBlockByHash devuelve un bloque de la cadena por su hash
+// Usa el índice por hash: no recorre la cadena
+func (bc *Blockchain) GetBlockByHash(hash string) (*Block, bool) {
+	number, ok := bc.ReadHeaderNumber(hash)
+	if !ok {
+		return nil, false
+	}
+	return bc.Blocks[number], true
+}
@@ -0,0 +1,81 @@
+package blockchain
+
+import (
+	"fmt"
+	"testing"
+
+	"minichain/crypto"
+	"minichain/evm"
+)
+
+// emitterCode guarda 0x2a en memoria y lo emite con LOG1 y el topic 7
+var emitterCode = []byte{
+	byte(evm.PUSH1), 0x2a, byte(evm.PUSH1), 0, byte(evm.MSTORE),
+	byte(evm.PUSH1), 7, byte(evm.PUSH1), 32, byte(evm.PUSH1), 0, byte(evm.LOG1),
+}
+
+// sendSigned firma tx y la añade al mempool
+func sendSigned(t *testing.T, bc *Blockchain, key *crypto.KeyPair, tx *Transaction) *Transaction {
+	t.Helper()
+	if err := tx.Sign(key, bc.ChainID); err != nil {
+		t.Fatal(err)
+	}
+	if err := bc.AddTransaction(tx); err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+// TestContractLogs: los eventos de LOGn llegan al recibo y a FilterLogs; los
+// de una llamada que revierte se descartan
+func TestContractLogs(t *testing.T) {
+	key := newKey(t)
+	bc := fundedChain(t, key)
+	from := key.GetAddress()
+
+	reverter := append(append([]byte{}, emitterCode...), byte(evm.PUSH1), 0, byte(evm.PUSH1), 0, byte(evm.REVERT))
+	emitterDeploy := sendSigned(t, bc, key, NewContractDeploymentTx(from, append(emitterCode, byte(evm.STOP)), 0))
+	reverterDeploy := sendSigned(t, bc, key, NewContractDeploymentTx(from, reverter, 1))
+	bc.MineBlock()
+
+	emitted := sendSigned(t, bc, key, NewContractCallTx(from, emitterDeploy.ContractAddress, nil, 2))
+	reverted := sendSigned(t, bc, key, NewContractCallTx(from, reverterDeploy.ContractAddress, nil, 3))
+	bc.MineBlock()
+
+	topic := fmt.Sprintf("0x%064x", 7)
+	receipt, err := bc.GetReceipt(emitted.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(receipt.Logs) != 1 {
+		t.Fatalf("el recibo tiene %d logs, esperaba 1", len(receipt.Logs))
+	}
+	log := receipt.Logs[0]
+	if log.Address != emitterDeploy.ContractAddress || len(log.Topics) != 1 || log.Topics[0] != topic {
+		t.Fatalf("log = %+v", log)
+	}
+	if len(log.Data) != 32 || log.Data[31] != 0x2a {
+		t.Fatalf("datos del log = %x", log.Data)
+	}
+
+	receipt, err = bc.GetReceipt(reverted.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Status != ReceiptStatusFailed || len(receipt.Logs) != 0 {
+		t.Fatalf("llamada revertida: estado %d con %d logs", receipt.Status, len(receipt.Logs))
+	}
+
+	head := len(bc.Blocks) - 1
+	logs, err := bc.FilterLogs(FilterQuery{FromBlock: 0, ToBlock: head, Topics: [][]string{{topic}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 1 || logs[0].TxHash != emitted.Hash() {
+		t.Fatalf("FilterLogs devolvió %d logs", len(logs))
+	}
+	logs, _ = bc.FilterLogs(FilterQuery{FromBlock: 0, ToBlock: head, Topics: [][]string{{fmt.Sprintf("0x%064x", 8)}}})
+	if len(logs) != 0 {
+		t.Fatalf("FilterLogs con otro topic devolvió %d logs", len(logs))
+	}
+}
@@ -0,0 +1,98 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+	"minichain/mempool"
+)
+
+// poolState adapta la cadena a lo que el mempool necesita consultar
+type poolState struct {
+	bc *Blockchain
+}
+
+// Nonce devuelve el nonce confirmado de una cuenta
+func (s poolState) Nonce(address string) int {
+	return s.bc.GetNonce(address)
+}
+
+// Balance devuelve el saldo confirmado de una cuenta
+func (s poolState) Balance(address string) *big.Int {
+	return s.bc.GetBalance(address)
+}
+
+// Height devuelve cuántos bloques tiene la cadena
+func (s poolState) Height() int {
+	return len(s.bc.Blocks)
+}
+
+// BaseFee devuelve el base fee del próximo bloque (nil si no hay)
+func (s poolState) BaseFee() *big.Int {
+	return s.bc.BaseFee()
+}
+
+// Included dice si la transacción ya está en un bloque de la cadena
+func (s poolState) Included(hash string) bool {
+	_, ok := s.bc.ReadTxLookupEntry(hash)
+	return ok
+}
+
+// ReservedUntil devuelve el primer nonce sin reservar de una cuenta (ver ReserveNonce)
+func (s poolState) ReservedUntil(address string) int {
+	return s.bc.reservedUntil(address)
+}
+
+// Validate aplica las reglas de la cadena (firma, forks, lotes...)
+func (s poolState) Validate(tx mempool.Tx, expectedNonce int) error {
+	return tx.(*Transaction).validate(s.bc.AccountState, s.bc, expectedNonce)
+}
+
+// PendingTransactions devuelve las transacciones del mempool que se pueden
+// minar ya, en orden de llegada
+func (bc *Blockchain) PendingTransactions() []*Transaction {
+	return toTransactions(bc.Mempool.Pending())
+}
+
+// QueuedTransactions devuelve las transacciones del mempool que esperan a que
+// se llene un hueco de nonce reservado (ver ReserveNonce)
+func (bc *Blockchain) QueuedTransactions() []*Transaction {
+	return toTransactions(bc.Mempool.Queued())
+}
+
+// toTransactions convierte lo que devuelve el mempool en transacciones
+func toTransactions(pool []mempool.Tx) []*Transaction {
+	txs := make([]*Transaction, len(pool))
+	for i, tx := range pool {
+		txs[i] = tx.(*Transaction)
+	}
+	return txs
+}
+
+// GetPendingTransaction devuelve una transacción que espera en el mempool
+func (bc *Blockchain) GetPendingTransaction(hash string) (*Transaction, bool) {
+	tx, ok := bc.Mempool.Get(hash)
+	if !ok {
+		return nil, false
+	}
+	return tx.(*Transaction), true
+}
+
+// PendingNonce devuelve el siguiente nonce libre de una cuenta
+// Cuenta las transacciones que ya esperan en el mempool, así que se pueden
+// encadenar varias transacciones del mismo remitente antes de minar
+func (bc *Blockchain) PendingNonce(address string) int {
+	return bc.Mempool.PendingNonce(address)
+}
+
+// reconcileMempool actualiza el mempool después de añadir un bloque
+// Quita las transacciones incluidas y descarta las que ya no son válidas
+func (bc *Blockchain) reconcileMempool(block *Block) {
+	included := make([]string, len(block.Transactions))
+	for i, tx := range block.Transactions {
+		included[i] = tx.Hash()
+	}
+
+	if dropped := bc.Mempool.Reset(included); dropped > 0 {
+		fmt.Printf("   🗑️  %d transacciones pendientes caducadas o ya no válidas se descartan\n", dropped)
+	}
+}
@@ -0,0 +1,247 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+	"minichain/crypto"
+	"minichain/utils"
+	"sort"
+)
+
+// MaxMultisigOwners es el máximo de titulares de una cuenta multifirma
+const MaxMultisigOwners = 16
+
+// multisigSigGas es el gas por firma exigida (verificar cada una cuesta)
+const multisigSigGas = 3000
+
+// Multisig es una cuenta m-de-n: gastar de ella exige Threshold firmas de Owners
+//
+// La cuenta no se registra en la cadena: su dirección se deriva del umbral y
+// los titulares (ver Address), y cada transacción que gasta de ella lleva esa
+// configuración. Así cualquiera puede comprobar que las firmas son de los
+// titulares de esa dirección sin guardar nada en el estado. Para recibir fondos
+// basta con enviarlos a la dirección.
+type Multisig struct {
+	Threshold  int                  // Firmas necesarias (m)
+	Owners     []string             // Direcciones de los titulares (n), ordenadas
+	Signatures []*MultisigSignature // Firmas reunidas, en el orden de Owners
+}
+
+// MultisigSignature es la firma de un titular sobre SigningHash
+type MultisigSignature struct {
+	PublicKeyX *big.Int
+	PublicKeyY *big.Int
+	Signature  string
+}
+
+// NewMultisig crea la configuración de una cuenta m-de-n
+func NewMultisig(threshold int, owners []string) (*Multisig, error) {
+	sorted := append([]string(nil), owners...)
+	sort.Strings(sorted)
+
+	m := &Multisig{Threshold: threshold, Owners: sorted}
+	if err := m.validateConfig(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// validateConfig comprueba umbral y titulares (sin mirar las firmas)
+func (m *Multisig) validateConfig() error {
+	if len(m.Owners) == 0 || len(m.Owners) > MaxMultisigOwners {
+		return fmt.Errorf("una cuenta multifirma necesita entre 1 y %d titulares (tiene %d)",
+			MaxMultisigOwners, len(m.Owners))
+	}
+	if m.Threshold < 1 || m.Threshold > len(m.Owners) {
+		return fmt.Errorf("umbral inválido: %d de %d", m.Threshold, len(m.Owners))
+	}
+	for i, owner := range m.Owners {
+		if owner == "" {
+			return fmt.Errorf("titular %d vacío", i)
+		}
+		// Ordenados y sin repetir: una sola representación por cuenta
+		if i > 0 && m.Owners[i-1] >= owner {
+			return fmt.Errorf("titulares desordenados o repetidos")
+		}
+	}
+	return nil
+}
+
+// Address es la dirección de la cuenta: se deriva del umbral y los titulares
+func (m *Multisig) Address() string {
+	owners := make([]interface{}, len(m.Owners))
+	for i, owner := range m.Owners {
+		owners[i] = owner
+	}
+	return utils.Keccak256Hex(utils.RLPEncode([]interface{}{"multisig", m.Threshold, owners}))[:40]
+}
+
+// ownerIndex devuelve la posición de un titular (-1 si no lo es)
+func (m *Multisig) ownerIndex(address string) int {
+	i := sort.SearchStrings(m.Owners, address)
+	if i < len(m.Owners) && m.Owners[i] == address {
+		return i
+	}
+	return -1
+}
+
+// signer devuelve el titular que hizo una firma
+func (sig *MultisigSignature) signer() string {
+	if sig.PublicKeyX == nil || sig.PublicKeyY == nil {
+		return ""
+	}
+	return crypto.PublicKeyToAddress(sig.PublicKeyX, sig.PublicKeyY)
+}
+
+// NewMultisigTx crea una transferencia (sin firmar) desde una cuenta multifirma
+// El gas cubre la verificación de Threshold firmas
+func NewMultisigTx(m *Multisig, to string, amount *big.Int, nonce int) *Transaction {
+	return &Transaction{
+		From:     m.Address(),
+		To:       to,
+		Amount:   amount,
+		Nonce:    nonce,
+		GasPrice: big.NewInt(DefaultGasPrice),
+		GasLimit: txGas + uint64(m.Threshold)*multisigSigGas,
+		Multisig: &Multisig{Threshold: m.Threshold, Owners: m.Owners},
+	}
+}
+
+// IsMultisig verifica si la transacción gasta de una cuenta multifirma
+func (tx *Transaction) IsMultisig() bool {
+	return tx.Multisig != nil
+}
+
+// multisigGas es el gas extra de verificar las firmas de una multifirma
+func (tx *Transaction) multisigGas() uint64 {
+	if !tx.IsMultisig() {
+		return 0
+	}
+	return uint64(tx.Multisig.Threshold) * multisigSigGas
+}
+
+// SignMultisig añade la firma de un titular a una transacción multifirma
+//
+// Cada titular firma por separado (puede ser en otra máquina, pasándose la
+// transacción en formato raw); cuando hay Threshold firmas se puede enviar.
+func (tx *Transaction) SignMultisig(keyPair *crypto.KeyPair, chainID uint64) error {
+	if !tx.IsMultisig() {
+		return fmt.Errorf("no es una transacción multifirma")
+	}
+	if tx.From != tx.Multisig.Address() {
+		return fmt.Errorf("la dirección From no es la de la cuenta multifirma")
+	}
+
+	owner := keyPair.GetAddress()
+	index := tx.Multisig.ownerIndex(owner)
+	if index < 0 {
+		return fmt.Errorf("%s no es titular de la cuenta multifirma", owner[:16]+"...")
+	}
+
+	// Todas las firmas tienen que ser para la misma red
+	if len(tx.Multisig.Signatures) > 0 && tx.ChainID != chainID {
+		return fmt.Errorf("la transacción ya tiene firmas para la red %d", tx.ChainID)
+	}
+	tx.ChainID = chainID
+
+	for _, sig := range tx.Multisig.Signatures {
+		if sig.signer() == owner {
+			return fmt.Errorf("%s ya firmó esta transacción", owner[:16]+"...")
+		}
+	}
+
+	signature, err := keyPair.SignData(tx.SigningHash())
+	if err != nil {
+		return fmt.Errorf("error firmando transacción: %v", err)
+	}
+
+	tx.Multisig.Signatures = append(tx.Multisig.Signatures, &MultisigSignature{
+		PublicKeyX: keyPair.PublicKey.X,
+		PublicKeyY: keyPair.PublicKey.Y,
+		Signature:  signature,
+	})
+
+	// Orden canónico: el de los titulares
+	sort.Slice(tx.Multisig.Signatures, func(i, j int) bool {
+		return tx.Multisig.Signatures[i].signer() < tx.Multisig.Signatures[j].signer()
+	})
+
+	tx.TxHash = tx.calculateHash()
+	return nil
+}
+
+// MissingSignatures devuelve cuántas firmas le faltan a una multifirma para el umbral
+func (tx *Transaction) MissingSignatures() int {
+	if !tx.IsMultisig() {
+		return 0
+	}
+	missing := tx.Multisig.Threshold - len(tx.Multisig.Signatures)
+	if missing < 0 {
+		return 0
+	}
+	return missing
+}
+
+// verifyMultisig comprueba la configuración, que From es la cuenta que describe
+// y que lleva al menos Threshold firmas válidas de titulares distintos
+// Todas las firmas incluidas tienen que ser válidas y estar en orden
+func (tx *Transaction) verifyMultisig() error {
+	m := tx.Multisig
+	if err := m.validateConfig(); err != nil {
+		return err
+	}
+	if tx.From != m.Address() {
+		return fmt.Errorf("la dirección From no corresponde a la cuenta multifirma")
+	}
+	if tx.Signature != "" || tx.PublicKeyX != nil || tx.PublicKeyY != nil {
+		return fmt.Errorf("una transacción multifirma no lleva firma individual")
+	}
+
+	signingHash := tx.SigningHash()
+	previous := -1
+	for i, sig := range m.Signatures {
+		index := m.ownerIndex(sig.signer())
+		if index < 0 {
+			return fmt.Errorf("firma %d de alguien que no es titular", i)
+		}
+		if index <= previous {
+			return fmt.Errorf("firmas desordenadas o repetidas")
+		}
+		previous = index
+
+		if !crypto.VerifySignature(sig.PublicKeyX, sig.PublicKeyY, signingHash, sig.Signature) {
+			return fmt.Errorf("firma inválida de %s", m.Owners[index][:16]+"...")
+		}
+	}
+
+	if len(m.Signatures) < m.Threshold {
+		return fmt.Errorf("faltan firmas: %d de %d", len(m.Signatures), m.Threshold)
+	}
+	return nil
+}
+
+// rlpMultisigConfig es la configuración multifirma como lista RLP (vacía si no lo es)
+// Es lo que firman los titulares: umbral y titulares, sin las firmas
+func (tx *Transaction) rlpMultisigConfig() []interface{} {
+	if !tx.IsMultisig() {
+		return []interface{}{}
+	}
+	owners := make([]interface{}, len(tx.Multisig.Owners))
+	for i, owner := range tx.Multisig.Owners {
+		owners[i] = owner
+	}
+	return []interface{}{tx.Multisig.Threshold, owners}
+}
+
+// rlpMultisig es la configuración multifirma con las firmas reunidas (vacía si no lo es)
+func (tx *Transaction) rlpMultisig() []interface{} {
+	config := tx.rlpMultisigConfig()
+	if len(config) == 0 {
+		return config
+	}
+	signatures := make([]interface{}, len(tx.Multisig.Signatures))
+	for i, sig := range tx.Multisig.Signatures {
+		signatures[i] = []interface{}{sig.PublicKeyX, sig.PublicKeyY, sig.Signature}
+	}
+	return append(config, signatures)
+}
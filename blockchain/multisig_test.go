@@ -0,0 +1,137 @@
+package blockchain
+
+import (
+	"strings"
+	"testing"
+
+	"minichain/crypto"
+	"minichain/utils"
+)
+
+// newMultisigAccount crea una cuenta 2-de-3 y una cadena que le da 10 MTC
+func newMultisigAccount(t *testing.T) (*Blockchain, *Multisig, []*crypto.KeyPair) {
+	t.Helper()
+	keys := []*crypto.KeyPair{newKey(t), newKey(t), newKey(t)}
+	m, err := NewMultisig(2, []string{keys[0].GetAddress(), keys[1].GetAddress(), keys[2].GetAddress()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bc, err := NewBlockchainFromGenesis(&Genesis{
+		ChainID:    7,
+		Difficulty: 1,
+		Timestamp:  1_700_000_000,
+		Alloc:      map[string]GenesisAccount{m.Address(): {Balance: utils.MTC(10)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bc, m, keys
+}
+
+func TestMultisigConfig(t *testing.T) {
+	a, b, c := newKey(t).GetAddress(), newKey(t).GetAddress(), newKey(t).GetAddress()
+
+	// El orden en que se dan los titulares no cambia la cuenta
+	first, err := NewMultisig(2, []string{a, b, c})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewMultisig(2, []string{c, a, b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Address() != second.Address() {
+		t.Fatal("los mismos titulares en otro orden dan otra dirección")
+	}
+	if other, _ := NewMultisig(3, []string{a, b, c}); other.Address() == first.Address() {
+		t.Fatal("otro umbral da la misma dirección")
+	}
+
+	for _, threshold := range []int{0, 4} {
+		if _, err := NewMultisig(threshold, []string{a, b, c}); err == nil {
+			t.Errorf("umbral %d de 3 aceptado", threshold)
+		}
+	}
+	if _, err := NewMultisig(1, []string{a, a}); err == nil {
+		t.Error("titular repetido aceptado")
+	}
+}
+
+// TestMultisigThreshold: con menos firmas que el umbral se rechaza; al
+// completarlas se acepta y se mina
+func TestMultisigThreshold(t *testing.T) {
+	bc, m, keys := newMultisigAccount(t)
+	recipient := newKey(t).GetAddress()
+
+	tx := NewMultisigTx(m, recipient, utils.MTC(1), 0)
+	if err := tx.SignMultisig(keys[2], bc.ChainID); err != nil {
+		t.Fatal(err)
+	}
+	if tx.MissingSignatures() != 1 {
+		t.Fatalf("faltan %d firmas, esperaba 1", tx.MissingSignatures())
+	}
+	if err := bc.AddTransaction(tx); err == nil || !strings.Contains(err.Error(), "faltan firmas") {
+		t.Fatalf("con 1 de 2 firmas: %v", err)
+	}
+
+	if err := tx.SignMultisig(keys[0], bc.ChainID); err != nil {
+		t.Fatal(err)
+	}
+	if err := bc.AddTransaction(tx); err != nil {
+		t.Fatalf("con 2 de 2 firmas: %v", err)
+	}
+	bc.MineBlock()
+
+	if got := bc.GetBalance(recipient); got.Cmp(utils.MTC(1)) != 0 {
+		t.Fatalf("el destinatario tiene %s, esperaba 1 MTC", utils.FormatMTC(got))
+	}
+}
+
+// TestMultisigSignatureOrder: las firmas quedan en el orden de los titulares
+// sea cual sea el orden en que se añaden; desordenadas, repetidas o de quien no
+// es titular no verifican
+func TestMultisigSignatureOrder(t *testing.T) {
+	bc, m, keys := newMultisigAccount(t)
+
+	tx := NewMultisigTx(m, newKey(t).GetAddress(), utils.MTC(1), 0)
+	for _, i := range []int{2, 1, 0} {
+		if err := tx.SignMultisig(keys[i], bc.ChainID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, sig := range tx.Multisig.Signatures {
+		if sig.signer() != m.Owners[i] {
+			t.Fatalf("firma %d de %s, esperaba la de %s", i, sig.signer(), m.Owners[i])
+		}
+	}
+	if err := tx.verifyMultisig(); err != nil {
+		t.Fatalf("3 firmas en orden: %v", err)
+	}
+
+	if err := tx.SignMultisig(keys[0], bc.ChainID); err == nil {
+		t.Error("un titular firmó dos veces")
+	}
+	if err := tx.SignMultisig(newKey(t), bc.ChainID); err == nil {
+		t.Error("firmó alguien que no es titular")
+	}
+
+	signatures := tx.Multisig.Signatures
+	cases := map[string][]*MultisigSignature{
+		"desordenadas": {signatures[1], signatures[0]},
+		"repetidas":    {signatures[0], signatures[0]},
+	}
+	outsider := newKey(t) // Firma válida, pero no es titular
+	signature, _ := outsider.SignData(tx.SigningHash())
+	cases["de un extraño"] = []*MultisigSignature{signatures[0],
+		{PublicKeyX: outsider.PublicKey.X, PublicKeyY: outsider.PublicKey.Y, Signature: signature}}
+
+	for name, sigs := range cases {
+		t.Run(name, func(t *testing.T) {
+			tx.Multisig.Signatures = sigs
+			if err := tx.verifyMultisig(); err == nil {
+				t.Fatal("verificó")
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package blockchain
+
+import "time"
+
+// NonceReservationTTL es cuánto dura una reserva de nonce que no se usa ni se libera
+const NonceReservationTTL = 5 * time.Minute
+
+// ReserveNonce entrega el siguiente nonce libre y lo aparta para quien lo pide
+//
+// Pensado para varios procesos que comparten una misma cuenta: dos llamadas
+// nunca devuelven el mismo nonce, aunque ninguna transacción haya llegado aún
+// al mempool. Mientras la reserva dure, el mempool admite las transacciones con
+// nonces posteriores y las deja esperando (Queued) a que se use; si no se usa,
+// caduca a los NonceReservationTTL o se libera con ReleaseNonce, y las que
+// esperaban se descartan en el siguiente bloque.
+func (bc *Blockchain) ReserveNonce(address string) int {
+	// Fuera de nonceMu: el mempool consulta las reservas con su candado tomado
+	pending := bc.PendingNonce(address)
+
+	bc.nonceMu.Lock()
+	defer bc.nonceMu.Unlock()
+
+	nonce := max(pending, bc.reservedUntilLocked(address))
+	if bc.reservedNonces[address] == nil {
+		bc.reservedNonces[address] = make(map[int]time.Time)
+	}
+	bc.reservedNonces[address][nonce] = time.Now().Add(NonceReservationTTL)
+
+	return nonce
+}
+
+// ReleaseNonce devuelve un nonce reservado que ya no se va a usar
+// Devuelve false si no estaba reservado (o la reserva ya caducó)
+func (bc *Blockchain) ReleaseNonce(address string, nonce int) bool {
+	bc.nonceMu.Lock()
+	defer bc.nonceMu.Unlock()
+
+	bc.pruneReservations(address)
+	if _, ok := bc.reservedNonces[address][nonce]; !ok {
+		return false
+	}
+	delete(bc.reservedNonces[address], nonce)
+	if len(bc.reservedNonces[address]) == 0 {
+		delete(bc.reservedNonces, address)
+	}
+	return true
+}
+
+// reservedUntil devuelve el primer nonce por encima de todas las reservas
+// vigentes de una cuenta (0 si no tiene)
+func (bc *Blockchain) reservedUntil(address string) int {
+	bc.nonceMu.Lock()
+	defer bc.nonceMu.Unlock()
+
+	return bc.reservedUntilLocked(address)
+}
+
+// reservedUntilLocked es reservedUntil con nonceMu ya tomado
+func (bc *Blockchain) reservedUntilLocked(address string) int {
+	bc.pruneReservations(address)
+
+	until := 0
+	for nonce := range bc.reservedNonces[address] {
+		until = max(until, nonce+1)
+	}
+	return until
+}
+
+// pruneReservations olvida las reservas caducadas y las de nonces ya confirmados
+func (bc *Blockchain) pruneReservations(address string) {
+	reservations, ok := bc.reservedNonces[address]
+	if !ok {
+		return
+	}
+
+	now, confirmed := time.Now(), bc.GetNonce(address)
+	for nonce, expires := range reservations {
+		if nonce < confirmed || now.After(expires) {
+			delete(reservations, nonce)
+		}
+	}
+	if len(reservations) == 0 {
+		delete(bc.reservedNonces, address)
+	}
+}
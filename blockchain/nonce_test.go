@@ -0,0 +1,142 @@
+package blockchain
+
+import (
+	"errors"
+	"testing"
+
+	"minichain/crypto"
+	"minichain/utils"
+)
+
+// fundedChain crea una cadena cuyo génesis da 10 MTC a la cuenta de key
+func fundedChain(t *testing.T, key *crypto.KeyPair) *Blockchain {
+	t.Helper()
+	bc, err := NewBlockchainFromGenesis(&Genesis{
+		ChainID:    7,
+		Difficulty: 1,
+		Timestamp:  1_700_000_000,
+		Alloc:      map[string]GenesisAccount{key.GetAddress(): {Balance: utils.MTC(10)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bc
+}
+
+// signedTransfer firma una transferencia de 1 MTC con ese nonce
+func signedTransfer(t *testing.T, bc *Blockchain, key *crypto.KeyPair, nonce int) *Transaction {
+	t.Helper()
+	tx := NewTransaction(key.GetAddress(), newKey(t).GetAddress(), utils.MTC(1), nonce)
+	if err := tx.Sign(key, bc.ChainID); err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func TestReserveNonceNeverRepeats(t *testing.T) {
+	key := newKey(t)
+	bc := fundedChain(t, key)
+	address := key.GetAddress()
+
+	for want := 0; want < 3; want++ {
+		if got := bc.ReserveNonce(address); got != want {
+			t.Fatalf("reserva %d: nonce %d", want, got)
+		}
+	}
+
+	// Liberar la última permite volver a reservarla; liberar dos veces, no
+	if !bc.ReleaseNonce(address, 2) || bc.ReleaseNonce(address, 2) {
+		t.Fatal("ReleaseNonce debería devolver true solo la primera vez")
+	}
+	if got := bc.ReserveNonce(address); got != 2 {
+		t.Fatalf("tras liberar el 2 se reservó %d", got)
+	}
+}
+
+// TestReservedNonceGapIsQueued: con un nonce reservado, la transacción
+// siguiente espera en el mempool hasta que llega la que falta
+func TestReservedNonceGapIsQueued(t *testing.T) {
+	key := newKey(t)
+	bc := fundedChain(t, key)
+	address := key.GetAddress()
+
+	// Sin reserva, un hueco se rechaza
+	if err := bc.AddTransaction(signedTransfer(t, bc, key, 1)); !errors.Is(err, ErrNonceTooHigh) {
+		t.Fatalf("nonce con hueco sin reserva: %v", err)
+	}
+
+	first, second := bc.ReserveNonce(address), bc.ReserveNonce(address)
+	later := signedTransfer(t, bc, key, second)
+	if err := bc.AddTransaction(later); err != nil {
+		t.Fatalf("nonce reservado: %v", err)
+	}
+	if len(bc.PendingTransactions()) != 0 || len(bc.QueuedTransactions()) != 1 {
+		t.Fatalf("pending %d, queued %d; esperaba 0 y 1",
+			len(bc.PendingTransactions()), len(bc.QueuedTransactions()))
+	}
+
+	// Llega la que faltaba: las dos se pueden minar, en orden de nonce
+	if err := bc.AddTransaction(signedTransfer(t, bc, key, first)); err != nil {
+		t.Fatalf("nonce reservado anterior: %v", err)
+	}
+	if len(bc.PendingTransactions()) != 2 {
+		t.Fatalf("pending %d, esperaba 2", len(bc.PendingTransactions()))
+	}
+	bc.MineBlock()
+	if nonce := bc.GetNonce(address); nonce != 2 {
+		t.Fatalf("nonce %d tras minar, esperaba 2", nonce)
+	}
+	if bc.Mempool.Len() != 0 {
+		t.Fatalf("quedan %d transacciones en el mempool", bc.Mempool.Len())
+	}
+}
+
+// TestReleasedNonceDropsQueued: si el hueco ya no está reservado, lo que
+// esperaba detrás se descarta al revalidar el mempool (tras el siguiente bloque)
+func TestReleasedNonceDropsQueued(t *testing.T) {
+	key := newKey(t)
+	bc := fundedChain(t, key)
+	address := key.GetAddress()
+
+	first, second := bc.ReserveNonce(address), bc.ReserveNonce(address)
+	later := signedTransfer(t, bc, key, second)
+	if err := bc.AddTransaction(later); err != nil {
+		t.Fatal(err)
+	}
+
+	// Sin nada ejecutable no se mina (la cadena no avanza en vacío)
+	bc.MineBlock()
+	if len(bc.Blocks) != 1 {
+		t.Fatalf("se minaron %d bloques sin transacciones ejecutables", len(bc.Blocks)-1)
+	}
+
+	bc.ReleaseNonce(address, first)
+	bc.ReleaseNonce(address, second)
+	if dropped := bc.Mempool.Reset(nil); dropped != 1 {
+		t.Fatalf("se descartaron %d transacciones, esperaba 1", dropped)
+	}
+	if _, dropped := bc.Mempool.DroppedTx(later.Hash()); !dropped {
+		t.Fatal("la transacción sin reserva delante debería descartarse")
+	}
+}
+
+// TestGapCanBeFilledAfterRelease: el nonce que falta se acepta aunque su
+// reserva ya no exista, mientras lo de detrás siga esperando
+func TestGapCanBeFilledAfterRelease(t *testing.T) {
+	key := newKey(t)
+	bc := fundedChain(t, key)
+	address := key.GetAddress()
+
+	first, second := bc.ReserveNonce(address), bc.ReserveNonce(address)
+	if err := bc.AddTransaction(signedTransfer(t, bc, key, second)); err != nil {
+		t.Fatal(err)
+	}
+	bc.ReleaseNonce(address, first)
+
+	if err := bc.AddTransaction(signedTransfer(t, bc, key, first)); err != nil {
+		t.Fatalf("nonce del hueco: %v", err)
+	}
+	if len(bc.PendingTransactions()) != 2 {
+		t.Fatalf("pending %d, esperaba 2", len(bc.PendingTransactions()))
+	}
+}
@@ -0,0 +1,81 @@
+package blockchain
+
+import (
+	"math/big"
+	"sort"
+)
+
+// Parámetros del estimador de comisiones
+const (
+	oracleBlocks     = 20 // Bloques recientes que se miran
+	oraclePercentile = 60 // Percentil de las propinas mínimas de esos bloques
+
+	// MinSuggestedTip es la propina que se sugiere si no hay datos (1 gwei)
+	MinSuggestedTip = 1_000_000_000
+)
+
+// SuggestGasTipCap sugiere una propina por gas para entrar pronto en un bloque
+//
+// Mira la propina más baja que aceptó cada uno de los últimos bloques y toma un
+// percentil: pagando eso, la transacción habría entrado en la mayoría. Si el
+// mempool ya tiene más de lo que cabe en el próximo bloque, sugiere al menos lo
+// justo para superar a la última que entraría. Sin base fee, la propina es el
+// precio entero.
+func (bc *Blockchain) SuggestGasTipCap() *big.Int {
+	var samples []*big.Int
+
+	first := len(bc.Blocks) - oracleBlocks
+	if first < 1 {
+		first = 1
+	}
+	for _, block := range bc.Blocks[first:] {
+		var lowest *big.Int
+		for _, tx := range block.Transactions {
+			if tx.IsCoinbase() {
+				continue
+			}
+			tip := tx.EffectiveTip(block.BaseFee)
+			if lowest == nil || tip.Cmp(lowest) < 0 {
+				lowest = tip
+			}
+		}
+		if lowest != nil {
+			samples = append(samples, lowest)
+		}
+	}
+
+	suggestion := big.NewInt(MinSuggestedTip)
+	if bc.BaseFee() == nil {
+		suggestion = big.NewInt(DefaultGasPrice)
+	}
+	if len(samples) > 0 {
+		sort.Slice(samples, func(i, j int) bool { return samples[i].Cmp(samples[j]) < 0 })
+		suggestion = samples[(len(samples)-1)*oraclePercentile/100]
+	}
+
+	// Con el próximo bloque lleno hay que pagar más que la peor que entra
+	pending := bc.PendingTransactions()
+	if selected := bc.selectTransactions(pending); len(selected) > 0 && len(selected) < len(pending) {
+		var lowest *big.Int
+		for _, tx := range selected {
+			if tip := tx.EffectiveTip(bc.BaseFee()); lowest == nil || tip.Cmp(lowest) < 0 {
+				lowest = tip
+			}
+		}
+		if lowest.Cmp(suggestion) >= 0 {
+			suggestion = new(big.Int).Add(lowest, big.NewInt(1))
+		}
+	}
+
+	return new(big.Int).Set(suggestion)
+}
+
+// SuggestGasPrice sugiere un precio total por gas (como eth_gasPrice): el base
+// fee del próximo bloque más la propina sugerida
+func (bc *Blockchain) SuggestGasPrice() *big.Int {
+	price := bc.SuggestGasTipCap()
+	if baseFee := bc.BaseFee(); baseFee != nil {
+		price.Add(price, baseFee)
+	}
+	return price
+}
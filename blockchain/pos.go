@@ -0,0 +1,201 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+	"minichain/crypto"
+	"minichain/utils"
+	"sort"
+)
+
+// StakingAddress es la dirección especial donde quedan bloqueados los fondos en stake
+// Nadie tiene su clave privada, así que los fondos solo salen por las reglas de PoS
+const StakingAddress = "0000000000000000000000000000000000057a4e"
+
+// NewStakeTx crea una transacción que bloquea amount MTC como stake del remitente
+func NewStakeTx(from string, amount *big.Int, nonce int) *Transaction {
+	return &Transaction{
+		From:     from,
+		To:       StakingAddress,
+		Amount:   amount,
+		Nonce:    nonce,
+		GasPrice: big.NewInt(DefaultGasPrice),
+		GasLimit: txGas,
+	}
+}
+
+// IsStake verifica si la transacción bloquea fondos en stake
+func (tx *Transaction) IsStake() bool {
+	return tx.To == StakingAddress && tx.Amount.Sign() > 0 && len(tx.Data) == 0
+}
+
+// AddStake registra fondos bloqueados por una cuenta
+func (as *AccountState) AddStake(address string, amount *big.Int) {
+	as.Stakes[address] = new(big.Int).Add(as.GetStake(address), amount)
+}
+
+// GetStake devuelve los fondos bloqueados por una cuenta (una copia)
+func (as *AccountState) GetStake(address string) *big.Int {
+	if stake, exists := as.Stakes[address]; exists {
+		return new(big.Int).Set(stake)
+	}
+	return new(big.Int)
+}
+
+// ProofOfStake es un motor de consenso EXPERIMENTAL basado en stake
+//
+// El productor de cada bloque se elige de forma pseudoaleatoria, ponderada por
+// stake, usando como semilla el hash del bloque anterior y los stakes del
+// estado del bloque padre. El elegido firma la cabecera. No hay castigo por
+// firmar dos bloques a la misma altura.
+type ProofOfStake struct {
+	// GetKeyPair da acceso a las claves de los validadores locales
+	GetKeyPair func(address string) (*crypto.KeyPair, error)
+}
+
+// NewProofOfStake crea el motor de PoS usando las claves de la wallet local
+func NewProofOfStake(getKeyPair func(address string) (*crypto.KeyPair, error)) *ProofOfStake {
+	return &ProofOfStake{
+		GetKeyPair: getKeyPair,
+	}
+}
+
+// Name devuelve el nombre del motor
+func (pos *ProofOfStake) Name() string {
+	return "Proof of Stake (experimental)"
+}
+
+// SelectProposer elige al productor del bloque que sigue a parentHash
+// Todos los nodos con el mismo estado eligen al mismo validador
+func SelectProposer(state *AccountState, parentHash string) (string, error) {
+	validators := make([]string, 0, len(state.Stakes))
+	for address, stake := range state.Stakes {
+		if stake.Sign() > 0 {
+			validators = append(validators, address)
+		}
+	}
+	if len(validators) == 0 {
+		return "", fmt.Errorf("no hay validadores con stake")
+	}
+
+	// Orden determinista: el orden de los maps de Go es aleatorio
+	sort.Strings(validators)
+
+	// El peso de cada validador es su stake en unidades base
+	total := big.NewInt(0)
+	weights := make([]*big.Int, len(validators))
+	for i, address := range validators {
+		weights[i] = state.GetStake(address)
+		total.Add(total, weights[i])
+	}
+
+	seed, _ := new(big.Int).SetString(utils.CalculateHash("proposer:"+parentHash), 16)
+	pick := seed.Mod(seed, total)
+
+	for i, address := range validators {
+		if pick.Cmp(weights[i]) < 0 {
+			return address, nil
+		}
+		pick.Sub(pick, weights[i])
+	}
+
+	return validators[len(validators)-1], nil
+}
+
+// Prepare elige al productor con los stakes del bloque padre (antes de
+// ejecutar: un stake dentro del bloque no puede cambiar la elección)
+// Falla si al elegido no le corresponde una clave de este nodo.
+func (pos *ProofOfStake) Prepare(bc *Blockchain, block *Block) error {
+	proposer, err := SelectProposer(bc.AccountState, block.PreviousHash)
+	if err != nil {
+		return err
+	}
+	if _, err := pos.keyPair(proposer); err != nil {
+		return err
+	}
+
+	block.Validator = proposer
+	return nil
+}
+
+// keyPair devuelve las claves locales de un validador
+func (pos *ProofOfStake) keyPair(validator string) (*crypto.KeyPair, error) {
+	if pos.GetKeyPair != nil {
+		if keyPair, err := pos.GetKeyPair(validator); err == nil {
+			return keyPair, nil
+		}
+	}
+	return nil, fmt.Errorf("el turno es de %s, que no es un validador local", validator[:16]+"...")
+}
+
+// Seal firma el bloque con las claves del productor elegido en Prepare
+func (pos *ProofOfStake) Seal(bc *Blockchain, block *Block) error {
+	proposer := block.Validator
+	keyPair, err := pos.keyPair(proposer)
+	if err != nil {
+		return err
+	}
+
+	block.Bits = 0
+	block.Hash = block.CalculateBlockHash()
+
+	signature, err := keyPair.SignData([]byte(block.Hash))
+	if err != nil {
+		return fmt.Errorf("error firmando bloque: %v", err)
+	}
+
+	block.ValidatorSig = signature
+	block.ValidatorKeyX = keyPair.PublicKey.X
+	block.ValidatorKeyY = keyPair.PublicKey.Y
+
+	fmt.Printf("\n✍️  Bloque %d firmado por el validador %s (stake: %s MTC)\n",
+		block.Index, proposer[:16]+"...", utils.FormatMTC(bc.AccountState.GetStake(proposer)))
+
+	return nil
+}
+
+// VerifySeal comprueba el hash, la firma del validador y, para el bloque que
+// sigue a la cabeza, que el firmante sea el productor elegido
+//
+// La elección depende de los stakes del bloque padre. Al importar (ver
+// ImportBlock) el estado es justo ese; para bloques ya aceptados (ValidateChain)
+// el estado es posterior y solo se comprueba la firma.
+func (pos *ProofOfStake) VerifySeal(bc *Blockchain, block *Block) error {
+	if err := verifyValidatorSignature(block); err != nil {
+		return err
+	}
+	if block.Index != len(bc.Blocks) {
+		return nil
+	}
+
+	proposer, err := SelectProposer(bc.AccountState, block.PreviousHash)
+	if err != nil {
+		return err
+	}
+	if block.Validator != proposer {
+		return fmt.Errorf("firmado por %s, pero el turno era de %s", block.Validator[:16]+"...", proposer[:16]+"...")
+	}
+	return nil
+}
+
+// verifyValidatorSignature comprueba hash, firma y que la clave sea del validador
+func verifyValidatorSignature(block *Block) error {
+	if block.Validator == "" || block.ValidatorSig == "" {
+		return fmt.Errorf("bloque sin firma de validador")
+	}
+
+	if block.Hash != block.CalculateBlockHash() {
+		return fmt.Errorf("hash inválido")
+	}
+
+	if block.ValidatorKeyX == nil || block.ValidatorKeyY == nil ||
+		crypto.PublicKeyToAddress(block.ValidatorKeyX, block.ValidatorKeyY) != block.Validator {
+		return fmt.Errorf("la clave pública no corresponde al validador")
+	}
+
+	if !crypto.VerifySignature(block.ValidatorKeyX, block.ValidatorKeyY, []byte(block.Hash), block.ValidatorSig) {
+		return fmt.Errorf("firma del validador inválida")
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package blockchain
+
+import (
+	"fmt"
+	"testing"
+
+	"minichain/crypto"
+	"minichain/utils"
+)
+
+// posPair crea dos cadenas PoS con el mismo génesis, con stake para cada
+// validador y las claves de todos en la que produce
+func posPair(t *testing.T, validators ...*crypto.KeyPair) (source, target *Blockchain) {
+	t.Helper()
+	keys := make(map[string]*crypto.KeyPair)
+	genesis := &Genesis{ChainID: 7, Difficulty: 1, Timestamp: 1_700_000_000, Alloc: map[string]GenesisAccount{}}
+	for _, keyPair := range validators {
+		keys[keyPair.GetAddress()] = keyPair
+		genesis.Alloc[keyPair.GetAddress()] = GenesisAccount{Balance: utils.MTC(100), Stake: utils.MTC(10)}
+	}
+
+	var err error
+	if source, err = NewBlockchainFromGenesis(genesis); err != nil {
+		t.Fatal(err)
+	}
+	if target, err = NewBlockchainFromGenesis(genesis); err != nil {
+		t.Fatal(err)
+	}
+	source.Engine = NewProofOfStake(func(address string) (*crypto.KeyPair, error) {
+		if keyPair, ok := keys[address]; ok {
+			return keyPair, nil
+		}
+		return nil, fmt.Errorf("sin claves para %s", address)
+	})
+	target.Engine = NewProofOfStake(nil)
+	return source, target
+}
+
+// produce mina en source un bloque con una transacción firmada por sender
+func produce(t *testing.T, source *Blockchain, sender *crypto.KeyPair, tx *Transaction) *Block {
+	t.Helper()
+	if err := tx.Sign(sender, source.ChainID); err != nil {
+		t.Fatal(err)
+	}
+	head := len(source.Blocks)
+	if err := source.AddTransaction(tx); err != nil {
+		t.Fatal(err)
+	}
+	source.MineBlock()
+	if len(source.Blocks) != head+1 {
+		t.Fatal("no se produjo el bloque")
+	}
+	return source.Blocks[head]
+}
+
+func TestPoSImportRejectsWrongProposer(t *testing.T) {
+	a, b := newKey(t), newKey(t)
+	source, target := posPair(t, a, b)
+
+	tx := NewTransaction(a.GetAddress(), b.GetAddress(), utils.MTC(1), 0)
+	block := produce(t, source, a, tx)
+
+	// El otro validador firma el mismo bloque como si fuera suyo
+	forger := a
+	if block.Validator == a.GetAddress() {
+		forger = b
+	}
+	block.Validator = forger.GetAddress()
+	block.ValidatorKeyX, block.ValidatorKeyY = forger.PublicKey.X, forger.PublicKey.Y
+	block.Hash = block.CalculateBlockHash()
+	signature, err := forger.SignData([]byte(block.Hash))
+	if err != nil {
+		t.Fatal(err)
+	}
+	block.ValidatorSig = signature
+
+	if err := target.ImportBlock(block); err == nil {
+		t.Fatal("ImportBlock aceptó un bloque firmado por quien no era el productor")
+	}
+	if len(target.Blocks) != 1 {
+		t.Fatalf("la cadena tiene %d bloques, esperaba solo el génesis", len(target.Blocks))
+	}
+}
+
+// TestPoSProposerChosenBeforeExecution: un stake dentro del bloque no cambia
+// quién lo produce, así que productor y verificador coinciden
+func TestPoSProposerChosenBeforeExecution(t *testing.T) {
+	for i := 0; i < 8; i++ {
+		a, b := newKey(t), newKey(t)
+		source, target := posPair(t, a, b)
+		sender := b
+		if proposer, _ := SelectProposer(source.AccountState, source.Blocks[0].Hash); proposer == b.GetAddress() {
+			sender = a
+		}
+
+		// El que no produce mete casi todo su saldo en stake
+		tx := NewStakeTx(sender.GetAddress(), utils.MTC(90), 0)
+		if err := target.ImportBlock(produce(t, source, sender, tx)); err != nil {
+			t.Fatalf("ImportBlock: %v", err)
+		}
+	}
+}
+
+func TestPoSPrepareRequiresLocalProposer(t *testing.T) {
+	a, b := newKey(t), newKey(t)
+	source, _ := posPair(t, a)
+	source.Engine = NewProofOfStake(nil)
+
+	tx := NewTransaction(a.GetAddress(), b.GetAddress(), utils.MTC(1), 0)
+	if err := tx.Sign(a, source.ChainID); err != nil {
+		t.Fatal(err)
+	}
+	if err := source.AddTransaction(tx); err != nil {
+		t.Fatal(err)
+	}
+	before := source.StateRoot()
+	source.MineBlock()
+	if len(source.Blocks) != 1 || source.StateRoot() != before {
+		t.Fatal("sin las claves del productor no se debe producir ni ejecutar el bloque")
+	}
+}
@@ -0,0 +1,82 @@
+package blockchain
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"minichain/utils"
+)
+
+// LeafProof prueba que una hoja está en el árbol de Merkle del estado
+type LeafProof struct {
+	Leaf     []byte   // La hoja tal cual (ver stateLeaves)
+	Index    int      // Su posición entre las hojas ordenadas
+	Siblings [][]byte // Hashes hermanos de abajo arriba (ver utils.MerkleProof)
+}
+
+// Verify comprueba la prueba contra una raíz de estado
+func (p *LeafProof) Verify(stateRoot string) bool {
+	return utils.VerifyMerkleProof(stateRoot, p.Leaf, p.Index, p.Siblings)
+}
+
+// StorageProof es el valor de una clave del storage de un contrato y su prueba
+type StorageProof struct {
+	Key   *big.Int
+	Value *big.Int
+	Proof *LeafProof // nil si el valor es 0 (las claves a cero no tienen hoja)
+}
+
+// StateProof prueba el estado de una dirección contra la raíz del estado actual
+//
+// Con StateRoot (que un cliente ligero compara con el de la cabecera que ya
+// verificó) y estas pruebas se comprueba el saldo, el nonce, el código y el
+// storage sin descargar el estado. Las cuentas vacías no tienen hoja, así que
+// de ellas no hay prueba de inclusión.
+type StateProof struct {
+	Address       string
+	Balance       *big.Int
+	Nonce         int
+	StateRoot     string
+	AccountProof  *LeafProof // nil si la cuenta está vacía
+	CodeProof     *LeafProof // nil si no es un contrato
+	StorageProofs []*StorageProof
+}
+
+// GetProof genera las pruebas de una dirección y de las claves de su storage
+func (bc *Blockchain) GetProof(address string, keys []*big.Int) (*StateProof, error) {
+	leaves := bc.stateLeaves()
+	account := bc.AccountState.GetAccount(address)
+
+	proof := &StateProof{
+		Address:      address,
+		Balance:      new(big.Int).Set(account.Balance),
+		Nonce:        account.Nonce,
+		StateRoot:    utils.MerkleRoot(leaves),
+		AccountProof: leafProof(leaves, fmt.Sprintf("account:%s:", address)),
+		CodeProof:    leafProof(leaves, fmt.Sprintf("code:%s:", address)),
+	}
+
+	contract, _ := bc.GetContract(address)
+	for _, key := range keys {
+		if contract == nil {
+			return nil, fmt.Errorf("%s no es un contrato: no tiene storage", address)
+		}
+		proof.StorageProofs = append(proof.StorageProofs, &StorageProof{
+			Key:   key,
+			Value: contract.Storage.Load(key),
+			Proof: leafProof(leaves, fmt.Sprintf("storage:%s:%s:", address, key)),
+		})
+	}
+
+	return proof, nil
+}
+
+// leafProof busca la hoja que empieza por prefix y genera su prueba (nil si no hay)
+func leafProof(leaves [][]byte, prefix string) *LeafProof {
+	for i, leaf := range leaves {
+		if bytes.HasPrefix(leaf, []byte(prefix)) {
+			return &LeafProof{Leaf: leaf, Index: i, Siblings: utils.MerkleProof(leaves, i)}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,344 @@
+package blockchain
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"minichain/utils"
+	"strings"
+)
+
+// Formato raw: la transacción firmada completa codificada en RLP
+//
+// Sirve para firmar en una máquina sin conexión y enviar solo el resultado: el
+// blob lleva todo lo necesario para verificar la firma, así que la clave privada
+// nunca sale de donde se firmó. Es una lista con los campos en este orden:
+//
+//	[chainId, from, to, amount, nonce, gasPrice, [gasTipCap], gasLimit, data,
+//	 batch, multisig, signature, publicKeyX, publicKeyY]
+//
+// gasTipCap va dentro de una lista (vacía si no se indicó) porque una propina
+// de 0 y una sin indicar se firman distinto. Cada elemento de batch es
+// [from, to, amount, nonce, chainId, signature, publicKeyX, publicKeyY].
+// multisig es una lista vacía en las cuentas normales y, si no,
+// [threshold, [owners...], [[publicKeyX, publicKeyY, signature]...]].
+// Los metadatos de ejecución (gas usado, comisión...) no se incluyen.
+
+// rawTxFields es el número de elementos de la lista raw
+const rawTxFields = 14
+
+// EncodeRaw codifica la transacción firmada en formato raw
+func (tx *Transaction) EncodeRaw() []byte {
+	return utils.RLPEncode([]interface{}{
+		tx.ChainID,
+		tx.From,
+		tx.To,
+		tx.Amount,
+		tx.Nonce,
+		tx.GasPrice,
+		tx.rlpTipCap(),
+		tx.GasLimit,
+		tx.Data,
+		tx.rlpBatch(),
+		tx.rlpMultisig(),
+		tx.Signature,
+		tx.PublicKeyX,
+		tx.PublicKeyY,
+	})
+}
+
+// checkEncodable verifica que los campos que van en la codificación RLP se
+// pueden codificar: sin enteros negativos ni entradas nil
+//
+// Una transacción leída de JSON (un archivo importado) puede traer cualquier
+// cosa, y RLPEncode hace panic con los negativos. Se comprueba antes de
+// calcular su hash o su tx root.
+func (tx *Transaction) checkEncodable() error {
+	if tx.Nonce < 0 {
+		return fmt.Errorf("%w: nonce negativo %d", ErrMalformed, tx.Nonce)
+	}
+	if err := checkNonNegative(tx.Amount, tx.GasPrice, tx.GasTipCap, tx.PublicKeyX, tx.PublicKeyY); err != nil {
+		return err
+	}
+	for i, auth := range tx.Batch {
+		if auth == nil {
+			return fmt.Errorf("%w: autorización %d vacía", ErrMalformed, i)
+		}
+		if auth.Nonce < 0 {
+			return fmt.Errorf("%w: autorización %d con nonce negativo %d", ErrMalformed, i, auth.Nonce)
+		}
+		if err := checkNonNegative(auth.Amount, auth.PublicKeyX, auth.PublicKeyY); err != nil {
+			return err
+		}
+	}
+	if m := tx.Multisig; m != nil {
+		if m.Threshold < 0 {
+			return fmt.Errorf("%w: umbral negativo %d", ErrMalformed, m.Threshold)
+		}
+		for i, sig := range m.Signatures {
+			if sig == nil {
+				return fmt.Errorf("%w: firma %d vacía", ErrMalformed, i)
+			}
+			if err := checkNonNegative(sig.PublicKeyX, sig.PublicKeyY); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkNonNegative falla si algún valor es negativo (nil vale: se codifica como 0)
+func checkNonNegative(values ...*big.Int) error {
+	for _, v := range values {
+		if v != nil && v.Sign() < 0 {
+			return fmt.Errorf("%w: valor negativo %s", ErrMalformed, v)
+		}
+	}
+	return nil
+}
+
+// rlpTipCap es la propina como lista opcional (vacía si no se indicó)
+func (tx *Transaction) rlpTipCap() []interface{} {
+	if tx.GasTipCap == nil {
+		return []interface{}{}
+	}
+	return []interface{}{tx.GasTipCap}
+}
+
+// rlpBatch es el lote como lista RLP, con las autorizaciones completas
+func (tx *Transaction) rlpBatch() []interface{} {
+	batch := make([]interface{}, len(tx.Batch))
+	for i, auth := range tx.Batch {
+		batch[i] = []interface{}{
+			auth.From,
+			auth.To,
+			auth.Amount,
+			auth.Nonce,
+			auth.ChainID,
+			auth.Signature,
+			auth.PublicKeyX,
+			auth.PublicKeyY,
+		}
+	}
+	return batch
+}
+
+// RawHex es EncodeRaw en hexadecimal con prefijo 0x (lo que acepta SendRawTransaction)
+func (tx *Transaction) RawHex() string {
+	return "0x" + hex.EncodeToString(tx.EncodeRaw())
+}
+
+// DecodeRawTransaction reconstruye una transacción firmada a partir de su formato raw
+// Solo decodifica: la firma, el nonce y el saldo se comprueban al validarla
+func DecodeRawTransaction(raw []byte) (*Transaction, error) {
+	decoded, err := utils.RLPDecode(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, ok := decoded.([]interface{})
+	if !ok || len(fields) != rawTxFields {
+		return nil, fmt.Errorf("transacción raw inválida: se esperaba una lista de %d campos", rawTxFields)
+	}
+
+	d := rawDecoder{}
+	tx := &Transaction{
+		ChainID:    d.uint(fields[0], "chainId"),
+		From:       d.string(fields[1], "from"),
+		To:         d.string(fields[2], "to"),
+		Amount:     d.big(fields[3], "amount"),
+		Nonce:      d.int(fields[4], "nonce"),
+		GasPrice:   d.big(fields[5], "gasPrice"),
+		GasLimit:   d.uint(fields[7], "gasLimit"),
+		Data:       d.bytes(fields[8], "data"),
+		Signature:  d.string(fields[11], "signature"),
+		PublicKeyX: d.optionalBig(fields[12], "publicKeyX"),
+		PublicKeyY: d.optionalBig(fields[13], "publicKeyY"),
+	}
+
+	switch tipCap := d.list(fields[6], "gasTipCap"); len(tipCap) {
+	case 0:
+	case 1:
+		tx.GasTipCap = d.big(tipCap[0], "gasTipCap")
+	default:
+		d.fail("gasTipCap", "lista de %d elementos", len(tipCap))
+	}
+
+	for i, item := range d.list(fields[9], "batch") {
+		name := fmt.Sprintf("batch[%d]", i)
+		auth := d.list(item, name)
+		if len(auth) != 8 {
+			d.fail(name, "se esperaban 8 campos")
+			break
+		}
+		tx.Batch = append(tx.Batch, &TransferAuthorization{
+			From:       d.string(auth[0], name+".from"),
+			To:         d.string(auth[1], name+".to"),
+			Amount:     d.big(auth[2], name+".amount"),
+			Nonce:      d.int(auth[3], name+".nonce"),
+			ChainID:    d.uint(auth[4], name+".chainId"),
+			Signature:  d.string(auth[5], name+".signature"),
+			PublicKeyX: d.big(auth[6], name+".publicKeyX"),
+			PublicKeyY: d.big(auth[7], name+".publicKeyY"),
+		})
+	}
+
+	switch multisig := d.list(fields[10], "multisig"); len(multisig) {
+	case 0:
+	case 3:
+		tx.Multisig = &Multisig{Threshold: d.int(multisig[0], "multisig.threshold")}
+		for i, owner := range d.list(multisig[1], "multisig.owners") {
+			tx.Multisig.Owners = append(tx.Multisig.Owners, d.string(owner, fmt.Sprintf("multisig.owners[%d]", i)))
+		}
+		for i, item := range d.list(multisig[2], "multisig.signatures") {
+			name := fmt.Sprintf("multisig.signatures[%d]", i)
+			sig := d.list(item, name)
+			if len(sig) != 3 {
+				d.fail(name, "se esperaban 3 campos")
+				break
+			}
+			tx.Multisig.Signatures = append(tx.Multisig.Signatures, &MultisigSignature{
+				PublicKeyX: d.big(sig[0], name+".publicKeyX"),
+				PublicKeyY: d.big(sig[1], name+".publicKeyY"),
+				Signature:  d.string(sig[2], name+".signature"),
+			})
+		}
+	default:
+		d.fail("multisig", "lista de %d elementos", len(multisig))
+	}
+
+	if d.err != nil {
+		return nil, d.err
+	}
+	if len(tx.Data) == 0 {
+		tx.Data = nil // Igual que una transacción creada en local
+	}
+
+	tx.TxHash = tx.calculateHash()
+	return tx, nil
+}
+
+// DecodeRawTransactionHex es DecodeRawTransaction para el formato de RawHex
+func DecodeRawTransactionHex(rawHex string) (*Transaction, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(rawHex), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("transacción raw inválida: %v", err)
+	}
+	return DecodeRawTransaction(raw)
+}
+
+// SendRawTransaction decodifica una transacción raw en hexadecimal, la valida y
+// la añade al mempool. Devuelve su hash.
+func (bc *Blockchain) SendRawTransaction(rawHex string) (string, error) {
+	tx, err := DecodeRawTransactionHex(rawHex)
+	if err != nil {
+		return "", err
+	}
+
+	if err := bc.AddTransaction(tx); err != nil {
+		return "", err
+	}
+	return tx.Hash(), nil
+}
+
+// SubmitResult es el resultado de enviar una transacción de un lote
+type SubmitResult struct {
+	Hash  string // Vacío si no se pudo decodificar
+	Error error  // nil si entró en el mempool
+}
+
+// SendRawTransactions es SendRawTransaction para varias transacciones a la vez
+//
+// Las que se decodifican bien se añaden juntas con AddTransactions (ordenadas
+// por remitente y nonce); devuelve un resultado por transacción, en el mismo
+// orden que raws.
+func (bc *Blockchain) SendRawTransactions(raws []string) []SubmitResult {
+	results := make([]SubmitResult, len(raws))
+
+	var txs []*Transaction
+	var positions []int
+	for i, rawHex := range raws {
+		tx, err := DecodeRawTransactionHex(rawHex)
+		if err != nil {
+			results[i].Error = err
+			continue
+		}
+		results[i].Hash = tx.Hash()
+		txs = append(txs, tx)
+		positions = append(positions, i)
+	}
+
+	for j, err := range bc.AddTransactions(txs) {
+		results[positions[j]].Error = err
+	}
+	return results
+}
+
+// rawDecoder convierte los elementos RLP a los tipos de la transacción
+// Guarda el primer error para no comprobarlo campo a campo
+type rawDecoder struct {
+	err error
+}
+
+func (d *rawDecoder) fail(field, format string, args ...interface{}) {
+	if d.err == nil {
+		d.err = fmt.Errorf("transacción raw inválida: campo %s: %s", field, fmt.Sprintf(format, args...))
+	}
+}
+
+func (d *rawDecoder) bytes(item interface{}, field string) []byte {
+	value, ok := item.([]byte)
+	if !ok {
+		d.fail(field, "se esperaba una cadena")
+	}
+	return value
+}
+
+func (d *rawDecoder) string(item interface{}, field string) string {
+	return string(d.bytes(item, field))
+}
+
+func (d *rawDecoder) list(item interface{}, field string) []interface{} {
+	value, ok := item.([]interface{})
+	if !ok {
+		d.fail(field, "se esperaba una lista")
+	}
+	return value
+}
+
+// big decodifica un entero sin signo (big-endian, sin ceros a la izquierda)
+func (d *rawDecoder) big(item interface{}, field string) *big.Int {
+	value := d.bytes(item, field)
+	if len(value) > 0 && value[0] == 0 {
+		d.fail(field, "entero con ceros a la izquierda")
+	}
+	return new(big.Int).SetBytes(value)
+}
+
+// optionalBig es big pero devuelve nil si el campo está vacío (p. ej. la clave
+// pública de una multifirma, que no lleva firma individual)
+func (d *rawDecoder) optionalBig(item interface{}, field string) *big.Int {
+	if value, ok := item.([]byte); ok && len(value) == 0 {
+		return nil
+	}
+	return d.big(item, field)
+}
+
+func (d *rawDecoder) uint(item interface{}, field string) uint64 {
+	value := d.big(item, field)
+	if !value.IsUint64() {
+		d.fail(field, "entero demasiado grande")
+	}
+	return value.Uint64()
+}
+
+func (d *rawDecoder) int(item interface{}, field string) int {
+	value := d.uint(item, field)
+	if value > uint64(maxInt) {
+		d.fail(field, "entero demasiado grande")
+	}
+	return int(value)
+}
+
+// maxInt es el mayor int de la plataforma
+const maxInt = int(^uint(0) >> 1)
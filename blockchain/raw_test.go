@@ -0,0 +1,85 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"minichain/utils"
+)
+
+// TestRawTransactionRoundTrip: decodificar el formato raw da la misma
+// transacción (mismo hash, misma codificación y firma válida)
+func TestRawTransactionRoundTrip(t *testing.T) {
+	key := newKey(t)
+	bc := fundedChain(t, key)
+
+	plain := signedTransfer(t, bc, key, 0)
+
+	// Propina de 0 indicada: se firma distinto que sin propina
+	tipped := NewContractCallTx(key.GetAddress(), newKey(t).GetAddress(), []byte{0xca, 0xfe}, 1)
+	tipped.GasTipCap = new(big.Int)
+	if err := tipped.Sign(key, bc.ChainID); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tx := range []*Transaction{plain, tipped} {
+		decoded, err := DecodeRawTransactionHex(tx.RawHex())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Hash() != tx.Hash() || !bytes.Equal(decoded.EncodeRaw(), tx.EncodeRaw()) {
+			t.Fatalf("la transacción decodificada no coincide: %+v", decoded)
+		}
+		if (decoded.GasTipCap == nil) != (tx.GasTipCap == nil) {
+			t.Fatalf("GasTipCap %v, esperaba %v", decoded.GasTipCap, tx.GasTipCap)
+		}
+		if !bytes.Equal(decoded.Data, tx.Data) {
+			t.Fatalf("Data %x, esperaba %x", decoded.Data, tx.Data)
+		}
+	}
+
+	if _, err := bc.SendRawTransaction(plain.RawHex()); err != nil {
+		t.Fatalf("SendRawTransaction: %v", err)
+	}
+}
+
+// TestDecodeRawTransactionMalformed: entradas mal formadas dan error, sin panic
+func TestDecodeRawTransactionMalformed(t *testing.T) {
+	key := newKey(t)
+	bc := fundedChain(t, key)
+	raw := signedTransfer(t, bc, key, 0).EncodeRaw()
+
+	// Cada caso cambia un campo de una transacción válida
+	decoded, err := utils.RLPDecode(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := decoded.([]interface{})
+	withField := func(i int, value interface{}) string {
+		modified := append([]interface{}{}, fields...)
+		modified[i] = value
+		return "0x" + hex.EncodeToString(utils.RLPEncode(modified))
+	}
+
+	cases := map[string]string{
+		"hex inválido":       "0xzz",
+		"truncada":           "0x" + hex.EncodeToString(raw[:len(raw)-1]),
+		"no es una lista":    "0x" + hex.EncodeToString(utils.RLPEncode("hola")),
+		"faltan campos":      "0x" + hex.EncodeToString(utils.RLPEncode(fields[:rawTxFields-1])),
+		"lista en from":      withField(1, []interface{}{}),
+		"cadena en batch":    withField(9, "x"),
+		"nonce con ceros":    withField(4, []byte{0, 1}),
+		"propina de más":     withField(6, []interface{}{1, 2}),
+		"multisig a medias":  withField(10, []interface{}{1}),
+		"chainId desbordado": withField(0, bytes.Repeat([]byte{0xff}, 9)),
+	}
+	for name, rawHex := range cases {
+		t.Run(name, func(t *testing.T) {
+			if tx, err := DecodeRawTransactionHex(rawHex); err == nil {
+				t.Fatalf("se decodificó %+v, esperaba error", tx)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package blockchain
+
+import (
+	"fmt"
+	"minichain/utils"
+)
+
+// Estado de una transacción ejecutada (como en Ethereum)
+const (
+	ReceiptStatusFailed     uint64 = 0 // Revertida o no aplicada: solo cuenta el gas
+	ReceiptStatusSuccessful uint64 = 1
+)
+
+// Log es un evento emitido por un contrato durante la ejecución
+type Log struct {
+	Address string   // Contrato que lo emitió
+	Topics  []string // Índices para filtrar (hex)
+	Data    []byte
+
+	// Dónde se emitió: se rellenan al generar los recibos y no se comprometen
+	BlockNumber int
+	BlockHash   string
+	TxHash      string
+	TxIndex     int
+	Index       int // Posición entre todos los logs del bloque
+}
+
+// Receipt es el resultado de ejecutar una transacción dentro de un bloque
+//
+// Se genera al añadir el bloque a la cadena a partir de la metadata de
+// ejecución de cada transacción y se guarda por hash de bloque.
+type Receipt struct {
+	TxHash            string
+	BlockHash         string
+	BlockNumber       int
+	TxIndex           int
+	Status            uint64 // ReceiptStatusSuccessful o ReceiptStatusFailed
+	GasUsed           uint64
+	CumulativeGasUsed uint64 // Gas del bloque hasta esta transacción incluida
+	ContractAddress   string // Solo en despliegues con éxito
+	RevertReason      string // Solo si falló: el error de la ejecución
+	ReturnData        []byte // Lo que devolvió RETURN o los datos del REVERT (no entra en la raíz)
+	Logs              []*Log
+}
+
+// newReceipts genera los recibos de un bloque ya ejecutado
+func newReceipts(block *Block) []*Receipt {
+	receipts := make([]*Receipt, len(block.Transactions))
+	var cumulative uint64
+	var logIndex int
+
+	for i, tx := range block.Transactions {
+		status := tx.Status
+		if tx.IsCoinbase() {
+			status = ReceiptStatusSuccessful // La recompensa no se ejecuta: siempre se aplica
+		}
+
+		cumulative += tx.GasUsed
+		receipts[i] = &Receipt{
+			TxHash:            tx.Hash(),
+			BlockHash:         block.Hash,
+			BlockNumber:       block.Index,
+			TxIndex:           i,
+			Status:            status,
+			GasUsed:           tx.GasUsed,
+			CumulativeGasUsed: cumulative,
+			RevertReason:      tx.RevertReason,
+			ReturnData:        tx.ReturnData,
+		}
+		for _, log := range tx.Logs {
+			receipts[i].Logs = append(receipts[i].Logs, &Log{
+				Address:     log.Address,
+				Topics:      log.Topics,
+				Data:        log.Data,
+				BlockNumber: block.Index,
+				BlockHash:   block.Hash,
+				TxHash:      tx.Hash(),
+				TxIndex:     i,
+				Index:       logIndex,
+			})
+			logIndex++
+		}
+		if status == ReceiptStatusSuccessful {
+			receipts[i].ContractAddress = tx.ContractAddress
+		}
+	}
+
+	return receipts
+}
+
+// encode es la codificación RLP del recibo que se compromete en ReceiptRoot
+// Solo incluye el resultado de la ejecución (estado, gas acumulado y logs):
+// el hash y la posición del bloque ya los fija la cabecera. El motivo de un
+// fallo es texto para depurar y tampoco entra (como en Ethereum).
+func (r *Receipt) encode() []byte {
+	logs := make([]interface{}, len(r.Logs))
+	for i, log := range r.Logs {
+		topics := make([]interface{}, len(log.Topics))
+		for j, topic := range log.Topics {
+			topics[j] = topic
+		}
+		logs[i] = []interface{}{log.Address, topics, log.Data}
+	}
+
+	return utils.RLPEncode([]interface{}{r.Status, r.CumulativeGasUsed, logs})
+}
+
+// receiptRoot calcula la raíz de Merkle de los recibos de un bloque ejecutado
+func receiptRoot(block *Block) string {
+	receipts := newReceipts(block)
+	leaves := make([][]byte, len(receipts))
+	for i, receipt := range receipts {
+		leaves[i] = receipt.encode()
+	}
+	return utils.MerkleRoot(leaves)
+}
+
+// storeReceipts guarda los recibos de un bloque recién añadido a la cadena
+func (bc *Blockchain) storeReceipts(block *Block) {
+	bc.receipts[block.Hash] = newReceipts(block)
+}
+
+// GetReceipts devuelve los recibos de un bloque por su hash (en el orden de sus transacciones)
+func (bc *Blockchain) GetReceipts(blockHash string) ([]*Receipt, error) {
+	receipts, ok := bc.receipts[blockHash]
+	if !ok {
+		return nil, fmt.Errorf("bloque %s no encontrado", blockHash)
+	}
+	return receipts, nil
+}
+
+// GetReceipt devuelve el recibo de una transacción incluida en la cadena
+func (bc *Blockchain) GetReceipt(txHash string) (*Receipt, error) {
+	location, ok := bc.ReadTxLookupEntry(txHash)
+	if !ok {
+		return nil, fmt.Errorf("transacción %s no encontrada", txHash)
+	}
+
+	receipts, err := bc.GetReceipts(bc.Blocks[location.BlockNumber].Hash)
+	if err != nil {
+		return nil, err
+	}
+	return receipts[location.TxIndex], nil
+}
+
+// Print muestra el recibo de forma bonita
+func (r *Receipt) Print() {
+	status := "✅ Éxito"
+	if r.Status == ReceiptStatusFailed {
+		status = "❌ Fallida"
+	}
+
+	fmt.Println("\n🧾 RECIBO")
+	fmt.Printf("   Transacción: %s\n", r.TxHash)
+	fmt.Printf("   Bloque:      #%d (%s...)\n", r.BlockNumber, r.BlockHash[:16])
+	fmt.Printf("   Posición:    %d\n", r.TxIndex)
+	fmt.Printf("   Estado:      %s\n", status)
+	if r.RevertReason != "" {
+		fmt.Printf("   Motivo:      %s\n", r.RevertReason)
+	}
+	fmt.Printf("   Gas usado:   %d (acumulado en el bloque: %d)\n", r.GasUsed, r.CumulativeGasUsed)
+	if r.ContractAddress != "" {
+		fmt.Printf("   Contrato:    %s\n", r.ContractAddress)
+	}
+	if r.ReturnData != nil {
+		fmt.Printf("   Devuelve:    0x%x\n", r.ReturnData)
+	}
+	fmt.Printf("   Logs:        %d\n", len(r.Logs))
+}
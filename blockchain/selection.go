@@ -0,0 +1,73 @@
+package blockchain
+
+import (
+	"sort"
+)
+
+// selectTransactions elige qué transacciones pendientes entran en el próximo bloque
+//
+// Se ordenan por la propina que recibe el minero (de mayor a menor), pero las de
+// un mismo remitente siempre salen en orden de nonce: solo compite la siguiente de
+// cada uno. Se añaden mientras quepan en el límite de gas del bloque y paguen el
+// base fee. Las que no entran siguen en el mempool (ver reconcileMempool).
+func (bc *Blockchain) selectTransactions(pending []*Transaction) (selected []*Transaction) {
+	// Agrupar por remitente, cada cola ordenada por nonce
+	queues := make(map[string][]*Transaction)
+	var senders []string
+	for _, tx := range pending {
+		if _, exists := queues[tx.From]; !exists {
+			senders = append(senders, tx.From)
+		}
+		queues[tx.From] = append(queues[tx.From], tx)
+	}
+	for _, sender := range senders {
+		queue := queues[sender]
+		sort.SliceStable(queue, func(i, j int) bool {
+			return queue[i].Nonce < queue[j].Nonce
+		})
+	}
+
+	// El minero puede llenar menos que el límite: es una preferencia local, no
+	// una regla de consenso (un bloque con menos gas sigue siendo válido)
+	gasLeft := bc.GasLimit
+	if bc.MinerGasLimit > 0 && bc.MinerGasLimit < gasLeft {
+		gasLeft = bc.MinerGasLimit
+	}
+	baseFee := bc.BaseFee()
+
+	for {
+		// Buscar la cabeza de cola que más paga (empate: el remitente que llegó antes)
+		var best *Transaction
+		for _, sender := range senders {
+			queue := queues[sender]
+			if len(queue) == 0 {
+				continue
+			}
+			if best == nil || queue[0].EffectiveTip(baseFee).Cmp(best.EffectiveTip(baseFee)) > 0 {
+				best = queue[0]
+			}
+		}
+		if best == nil {
+			break
+		}
+
+		// Si no llega al base fee, ese remitente espera a que baje
+		if best.EffectiveTip(baseFee).Sign() < 0 {
+			queues[best.From] = nil
+			continue
+		}
+
+		// Si no cabe, el resto de ese remitente tampoco puede ir antes que ella
+		gas := best.GasLimit
+		if gas > gasLeft {
+			queues[best.From] = nil
+			continue
+		}
+
+		gasLeft -= gas
+		selected = append(selected, best)
+		queues[best.From] = queues[best.From][1:]
+	}
+
+	return selected
+}
@@ -0,0 +1,306 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+	"minichain/evm"
+	"minichain/utils"
+	"sort"
+)
+
+// stateLeaves serializa todo el estado en hojas ordenadas para el árbol de Merkle
+// Incluye cuentas, stakes y contratos (código y storage). Las cuentas vacías
+// se omiten: GetAccount las crea al consultarlas y no deben cambiar la raíz.
+func (bc *Blockchain) stateLeaves() [][]byte {
+	var leaves [][]byte
+
+	addresses := make([]string, 0, len(bc.AccountState.Accounts))
+	for address, account := range bc.AccountState.Accounts {
+		if account.Balance.Sign() != 0 || account.Nonce != 0 {
+			addresses = append(addresses, address)
+		}
+	}
+	sort.Strings(addresses)
+	for _, address := range addresses {
+		account := bc.AccountState.Accounts[address]
+		leaves = append(leaves, []byte(fmt.Sprintf("account:%s:%s:%d", address, account.Balance, account.Nonce)))
+	}
+
+	stakers := make([]string, 0, len(bc.AccountState.Stakes))
+	for address := range bc.AccountState.Stakes {
+		stakers = append(stakers, address)
+	}
+	sort.Strings(stakers)
+	for _, address := range stakers {
+		leaves = append(leaves, []byte(fmt.Sprintf("stake:%s:%s", address, bc.AccountState.Stakes[address])))
+	}
+
+	contracts := make([]string, 0, len(bc.Contracts))
+	for address := range bc.Contracts {
+		contracts = append(contracts, address)
+	}
+	sort.Strings(contracts)
+	for _, address := range contracts {
+		contract := bc.Contracts[address]
+		leaves = append(leaves, []byte(fmt.Sprintf("code:%s:%s:%s:%s",
+			address, contract.Owner, contract.Balance, utils.CalculateHashBytes(contract.Bytecode))))
+
+		keys := make([]string, 0, len(contract.Storage.Data))
+		for key := range contract.Storage.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			leaves = append(leaves, []byte(fmt.Sprintf("storage:%s:%s:%s",
+				address, key, contract.Storage.Data[key].String())))
+		}
+	}
+
+	return leaves
+}
+
+// StateRoot calcula la raíz de Merkle del estado actual
+// Dos nodos con el mismo estado obtienen siempre la misma raíz
+func (bc *Blockchain) StateRoot() string {
+	return utils.MerkleRoot(bc.stateLeaves())
+}
+
+// chainStateSnapshot guarda todo el estado mutable para poder deshacer un bloque
+type chainStateSnapshot struct {
+	accounts  *StateSnapshot
+	contracts map[string]*evm.Contract
+	storages  map[string]map[string]*big.Int
+}
+
+// snapshotState guarda cuentas, contratos y su storage
+func (bc *Blockchain) snapshotState() *chainStateSnapshot {
+	snapshot := &chainStateSnapshot{
+		accounts:  bc.AccountState.CreateSnapshot(),
+		contracts: make(map[string]*evm.Contract),
+		storages:  make(map[string]map[string]*big.Int),
+	}
+
+	for address, contract := range bc.Contracts {
+		snapshot.contracts[address] = contract
+		snapshot.storages[address] = contract.Storage.CreateSnapshot()
+	}
+
+	return snapshot
+}
+
+// revertState deshace todos los cambios posteriores a un snapshot
+func (bc *Blockchain) revertState(snapshot *chainStateSnapshot) {
+	bc.AccountState.RevertToSnapshot(snapshot.accounts)
+
+	bc.Contracts = make(map[string]*evm.Contract)
+	for address, contract := range snapshot.contracts {
+		contract.Storage.RevertToSnapshot(snapshot.storages[address])
+		bc.Contracts[address] = contract
+	}
+}
+
+// blockCoinbase devuelve quién cobra las comisiones del bloque ("" si nadie)
+func blockCoinbase(block *Block) string {
+	if len(block.Transactions) > 0 && block.Transactions[0].IsCoinbase() {
+		return block.Transactions[0].To
+	}
+	return ""
+}
+
+// blockTotals son los contadores de gas y comisiones de un bloque ejecutado
+type blockTotals struct {
+	gasUsed uint64
+	fees    *big.Int
+	refunds *big.Int
+	burned  *big.Int
+}
+
+// setOn copia los contadores a la cabecera del bloque
+func (t blockTotals) setOn(block *Block) {
+	block.GasUsed = t.gasUsed
+	block.TotalFees = t.fees
+	block.TotalRefunds = t.refunds
+	block.TotalBurned = t.burned
+}
+
+// matches verifica que la cabecera declara los mismos contadores
+func (t blockTotals) matches(block *Block) bool {
+	return block.GasUsed == t.gasUsed &&
+		block.TotalFees != nil && block.TotalFees.Cmp(t.fees) == 0 &&
+		block.TotalRefunds != nil && block.TotalRefunds.Cmp(t.refunds) == 0 &&
+		block.TotalBurned != nil && block.TotalBurned.Cmp(t.burned) == 0
+}
+
+// txFailure es una transacción que no se pudo ejecutar (nonce, saldo, gas...)
+// Execute falla antes de tocar el estado, así que el bloque no la puede llevar
+type txFailure struct {
+	index int
+	err   error
+}
+
+// dropTransactions quita del bloque las transacciones que no se pudieron ejecutar
+func (b *Block) dropTransactions(failed []txFailure) {
+	skip := make(map[int]bool, len(failed))
+	for _, f := range failed {
+		skip[f.index] = true
+	}
+	kept := b.Transactions[:0:0]
+	for i, tx := range b.Transactions {
+		if !skip[i] {
+			kept = append(kept, tx)
+		}
+	}
+	b.Transactions = kept
+}
+
+// applyBlock ejecuta todas las transacciones de un bloque sobre el estado
+// Las comisiones se acreditan a la coinbase del bloque (si la tiene)
+// Devuelve también las transacciones que no se pudieron ejecutar
+func (bc *Blockchain) applyBlock(block *Block) (blockTotals, []txFailure) {
+	fmt.Println("\n💼 Ejecutando transacciones del bloque...")
+	bc.executing = block
+	defer func() { bc.executing = nil }()
+
+	totals := blockTotals{fees: new(big.Int), refunds: new(big.Int), burned: new(big.Int)}
+	var failed []txFailure
+	for i, tx := range block.Transactions {
+		fmt.Printf("\n📝 Transacción %d/%d:\n", i+1, len(block.Transactions))
+
+		// La recompensa no se ejecuta como una transferencia: crea moneda nueva
+		if tx.IsCoinbase() {
+			fmt.Printf("   Tipo: COINBASE (%s MTC → %s)\n", utils.FormatMTC(tx.Amount), tx.To[:16]+"...")
+			bc.AccountState.AddBalance(tx.To, tx.Amount)
+			continue
+		}
+
+		// Mostrar tipo de transacción
+		if tx.IsBatch() {
+			fmt.Printf("   Tipo: LOTE (%d transferencias off-chain)\n", len(tx.Batch))
+		} else if tx.IsContractDeployment() {
+			fmt.Println("   Tipo: DESPLIEGUE DE CONTRATO")
+		} else if tx.IsContractCall(bc) {
+			fmt.Println("   Tipo: LLAMADA A CONTRATO")
+		} else {
+			fmt.Printf("   Tipo: TRANSFERENCIA (%s → %s: %s MTC)\n",
+				tx.From[:16]+"...", tx.To[:16]+"...", utils.FormatMTC(tx.Amount))
+		}
+
+		// Ejecutar (incluye contratos si aplica)
+		if err := tx.Execute(bc.AccountState, bc); err != nil {
+			fmt.Printf("   ❌ Error: %v\n", err)
+			failed = append(failed, txFailure{index: i, err: err})
+			continue
+		}
+
+		// La propina (también la de ejecuciones revertidas) es del minero; el base fee se quema
+		totals.gasUsed += tx.GasUsed
+		totals.fees.Add(totals.fees, tx.Fee)
+		totals.refunds.Add(totals.refunds, tx.Refund)
+		totals.burned.Add(totals.burned, tx.Burned)
+
+		if tx.Amount.Sign() > 0 {
+			fmt.Printf("   ✅ Fondos transferidos\n")
+		}
+	}
+
+	// Acreditar las comisiones al minero del bloque
+	if coinbase := blockCoinbase(block); coinbase != "" && totals.fees.Sign() > 0 {
+		bc.AccountState.AddBalance(coinbase, totals.fees)
+		fmt.Printf("\n💰 Comisiones del bloque: %s MTC → %s\n", utils.FormatMTC(totals.fees), coinbase[:16]+"...")
+	}
+
+	return totals, failed
+}
+
+// checkTransactions verifica firma, red y hash de cada transacción del bloque
+//
+// Las roots las calcula quien produce el bloque, así que no prueban nada por sí
+// solas: sin esto, un archivo importado podría mover fondos de cualquier cuenta
+// con transacciones sin firmar. La coinbase no lleva firma (ver validateCoinbase),
+// pero también se tiene que poder codificar para calcular el tx root.
+//
+// Una transacción ya confirmada tampoco puede volver a entrar: el índice por
+// hash pasaría a apuntar al bloque nuevo.
+func (bc *Blockchain) checkTransactions(block *Block) error {
+	for i, tx := range block.Transactions {
+		if tx == nil {
+			return fmt.Errorf("bloque %d, transacción %d: %w: vacía", block.Index, i, ErrMalformed)
+		}
+		var err error
+		if tx.IsCoinbase() {
+			err = tx.checkEncodable()
+		} else {
+			err = tx.checkAuthenticity(bc)
+		}
+		if err != nil {
+			return fmt.Errorf("bloque %d, transacción %d: %w", block.Index, i, err)
+		}
+		if location, ok := bc.ReadTxLookupEntry(tx.Hash()); ok {
+			return fmt.Errorf("bloque %d, transacción %d: ya confirmada en el bloque %d",
+				block.Index, i, location.BlockNumber)
+		}
+	}
+	return nil
+}
+
+// ImportBlock valida y añade un bloque producido por otro nodo
+//
+// Comprueba enlace, sello, coinbase y las firmas de las transacciones; ejecuta
+// las transacciones (todas tienen que poder ejecutarse) y verifica que el estado
+// resultante coincide con el StateRoot de la cabecera. Si algo falla, el estado vuelve a como estaba y el
+// bloque se rechaza.
+func (bc *Blockchain) ImportBlock(block *Block) error {
+	head := bc.Blocks[len(bc.Blocks)-1]
+
+	if block.Index != head.Index+1 {
+		return fmt.Errorf("índice %d inesperado (siguiente: %d)", block.Index, head.Index+1)
+	}
+	if block.PreviousHash != head.Hash {
+		return fmt.Errorf("el bloque %d no enlaza con la cabeza actual", block.Index)
+	}
+	if err := bc.checkCheckpoint(block); err != nil {
+		return err
+	}
+	if err := bc.Engine.VerifySeal(bc, block); err != nil {
+		return fmt.Errorf("sello inválido en bloque %d: %v", block.Index, err)
+	}
+	if err := bc.checkTransactions(block); err != nil {
+		return err
+	}
+	if err := block.checkTxRoot(); err != nil {
+		return err
+	}
+	if err := validateCoinbase(block); err != nil {
+		return fmt.Errorf("bloque %d: %v", block.Index, err)
+	}
+	if err := bc.checkBaseFee(block); err != nil {
+		return err
+	}
+
+	snapshot := bc.snapshotState()
+	totals, failed := bc.applyBlock(block)
+
+	if len(failed) > 0 {
+		bc.revertState(snapshot)
+		return fmt.Errorf("bloque %d, transacción %d: %v", block.Index, failed[0].index, failed[0].err)
+	}
+	if !totals.matches(block) {
+		bc.revertState(snapshot)
+		return fmt.Errorf("gas o comisiones incorrectos en bloque %d", block.Index)
+	}
+	if root := bc.StateRoot(); root != block.StateRoot {
+		bc.revertState(snapshot)
+		return fmt.Errorf("state root incorrecto en bloque %d: cabecera %s, calculado %s",
+			block.Index, block.StateRoot, root)
+	}
+	if root := receiptRoot(block); root != block.ReceiptRoot {
+		bc.revertState(snapshot)
+		return fmt.Errorf("receipt root incorrecto en bloque %d: cabecera %s, calculado %s",
+			block.Index, block.ReceiptRoot, root)
+	}
+
+	bc.appendBlock(block)
+	bc.reconcileMempool(block)
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package blockchain
+
+import (
+	"time"
+)
+
+// SyncProgress es el estado de la sincronización del nodo
+//
+// No hay red P2P: los bloques de otros nodos llegan importando un archivo
+// exportado (ver ImportChain), y el bloque más alto conocido es el último de
+// ese archivo. Fuera de una importación, HighestBlock es la cabeza.
+type SyncProgress struct {
+	Syncing         bool    // Hay una importación en curso
+	StartingBlock   int     // Cabeza al empezar la importación
+	CurrentBlock    int     // Cabeza actual
+	HighestBlock    int     // Último bloque del archivo que se importa
+	BlocksPerSecond float64 // Ritmo de la importación (0 si no hay)
+}
+
+// syncState es la importación en curso
+type syncState struct {
+	starting int
+	highest  int
+	started  time.Time
+}
+
+// SyncProgress devuelve el estado de la sincronización
+// Se puede llamar desde otra goroutine mientras se importa
+func (bc *Blockchain) SyncProgress() SyncProgress {
+	current := int(bc.head.Load())
+
+	bc.syncMu.Lock()
+	defer bc.syncMu.Unlock()
+
+	if bc.syncing == nil {
+		return SyncProgress{StartingBlock: current, CurrentBlock: current, HighestBlock: current}
+	}
+
+	progress := SyncProgress{
+		Syncing:       true,
+		StartingBlock: bc.syncing.starting,
+		CurrentBlock:  current,
+		HighestBlock:  max(bc.syncing.highest, current),
+	}
+	if elapsed := time.Since(bc.syncing.started).Seconds(); elapsed > 0 {
+		progress.BlocksPerSecond = float64(current-bc.syncing.starting) / elapsed
+	}
+	return progress
+}
+
+// startSync marca el comienzo de una importación hasta highest
+func (bc *Blockchain) startSync(highest int) {
+	bc.syncMu.Lock()
+	defer bc.syncMu.Unlock()
+	bc.syncing = &syncState{
+		starting: len(bc.Blocks) - 1,
+		highest:  highest,
+		started:  time.Now(),
+	}
+}
+
+// endSync marca el final de la importación (haya ido bien o no)
+func (bc *Blockchain) endSync() {
+	bc.syncMu.Lock()
+	defer bc.syncMu.Unlock()
+	bc.syncing = nil
+}
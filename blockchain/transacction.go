@@ -2,25 +2,47 @@ package blockchain
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"minichain/crypto"
+	"minichain/evm"
+	"minichain/utils"
 )
 
 // Transaction representa una transacción en la blockchain
 type Transaction struct {
+	ChainID    uint64 // Red para la que se firmó (protección contra replay, como EIP-155)
 	From       string
-	To         string // Si es "", es despliegue de contrato
-	Amount     float64
+	To         string   // Si es "", es despliegue de contrato
+	Amount     *big.Int // Unidades base (ver utils.Decimals)
 	Nonce      int
-	Data       []byte // Bytecode (para deploy) o calldata (para call)
+	Data       []byte   // Bytecode (para deploy) o calldata (para call)
+	GasPrice   *big.Int // Máximo por gas que el remitente ofrece pagar (base fee + propina)
+	GasTipCap  *big.Int // Propina máxima por gas para el minero (nil = todo lo que sobre del base fee)
+	GasLimit   uint64   // Gas máximo: se reserva GasLimit × GasPrice antes de ejecutar
 	Signature  string
 	PublicKeyX *big.Int
 	PublicKeyY *big.Int
+	TxHash     string // Hash canónico (ver calculateHash), lo fija Sign
+
+	// Lote de transferencias firmadas off-chain (solo transacciones de lote)
+	Batch []*TransferAuthorization
+
+	// Configuración y firmas de la cuenta multifirma que gasta (nil si es una cuenta normal)
+	// Sustituye a Signature y a la clave pública
+	Multisig *Multisig
 
 	// Metadata de ejecución
-	ContractAddress string // Si despliega contrato, guarda la dirección aquí
-	GasUsed         uint64 // Gas consumido en la ejecución
+	ContractAddress string   // Si despliega contrato, guarda la dirección aquí
+	GasUsed         uint64   // Gas consumido en la ejecución
+	Fee             *big.Int // Propina cobrada (gas usado × propina), va al minero
+	Burned          *big.Int // Base fee quemado (gas usado × base fee)
+	Refund          *big.Int // Gas reservado y no usado, devuelto al remitente
+	Status          uint64   // ReceiptStatusSuccessful si la ejecución se aplicó
+	RevertReason    string   // Por qué falló la ejecución (vacío si se aplicó)
+	ReturnData      []byte   // Lo que devolvió RETURN o los datos del REVERT
+	Logs            []*Log   // Eventos emitidos por los contratos
 }
 
 // IsContractDeployment verifica si es una transacción de despliegue
@@ -40,49 +62,145 @@ func (tx *Transaction) IsContractCall(bc *Blockchain) bool {
 }
 
 // NewTransaction crea una nueva transacción (sin firmar)
-func NewTransaction(from, to string, amount float64, nonce int) *Transaction {
+func NewTransaction(from, to string, amount *big.Int, nonce int) *Transaction {
 	return &Transaction{
-		From:   from,
-		To:     to,
-		Amount: amount,
-		Nonce:  nonce,
+		From:     from,
+		To:       to,
+		Amount:   amount,
+		Nonce:    nonce,
+		GasPrice: big.NewInt(DefaultGasPrice),
+		GasLimit: txGas,
 	}
 }
 
-// Sign firma la transacción con un par de claves
-func (tx *Transaction) Sign(keyPair *crypto.KeyPair) error {
+// Sign firma la transacción con un par de claves para la red chainID
+// La firma solo es válida en esa red: no se puede reenviar a otra
+func (tx *Transaction) Sign(keyPair *crypto.KeyPair, chainID uint64) error {
 	// Verificar que la dirección coincide con el par de claves
 	if tx.From != keyPair.GetAddress() {
 		return fmt.Errorf("la dirección From no coincide con el par de claves")
 	}
 
+	tx.ChainID = chainID
+
 	// Guardar la clave pública (necesaria para verificar la firma)
 	tx.PublicKeyX = keyPair.PublicKey.X
 	tx.PublicKeyY = keyPair.PublicKey.Y
 
-	// Crear los datos a firmar (sin la firma misma)
-	dataToSign := tx.getDataToSign()
-
-	// Firmar los datos
-	signature, err := keyPair.SignData(dataToSign)
+	// Firmar el hash de todos los campos (sin la firma misma)
+	signature, err := keyPair.SignData(tx.SigningHash())
 	if err != nil {
 		return fmt.Errorf("error firmando transacción: %v", err)
 	}
 
 	tx.Signature = signature
+	tx.TxHash = tx.calculateHash()
 
 	return nil
 }
 
-// getDataToSign obtiene los datos que se firman
-// No incluye la firma misma (obvio, no puedes firmar la firma)
-func (tx *Transaction) getDataToSign() []byte {
-	data := fmt.Sprintf("%s:%s:%.2f:%d", tx.From, tx.To, tx.Amount, tx.Nonce)
-	return []byte(data)
+// SigningHash es el hash que firma el remitente
+//
+// Keccak-256 de la codificación RLP de todos los campos que definen qué hace la
+// transacción: red, remitente, destino, monto, nonce, gas, datos (bytecode o
+// calldata), el lote y la configuración multifirma. Cambiar cualquiera de ellos
+// invalida la firma. No incluye las firmas (obvio, no puedes firmar la firma).
+func (tx *Transaction) SigningHash() []byte {
+	return utils.Keccak256(utils.RLPEncode([]interface{}{
+		tx.ChainID,
+		tx.From,
+		tx.To,
+		tx.Amount,
+		tx.Nonce,
+		tx.GasPrice,
+		tx.rlpTipCap(),
+		tx.GasLimit,
+		tx.Data,
+		tx.rlpBatch(),
+		tx.rlpMultisigConfig(),
+	}))
 }
 
-// VerifySignature verifica que la firma sea válida
+// Hash identifica la transacción (mempool, búsquedas, bloques)
+// Devuelve el TxHash guardado al firmar; validate comprueba que sea el correcto
+func (tx *Transaction) Hash() string {
+	if tx.TxHash != "" {
+		return tx.TxHash
+	}
+	return tx.calculateHash()
+}
+
+// calculateHash es el hash canónico: Keccak-256 de la codificación RLP de la transacción
+//
+// Cubre todos los campos que la definen (también el payload, el lote y la firma),
+// así que dos transacciones distintas nunca comparten hash. Los metadatos de
+// ejecución (gas usado, comisión...) no forman parte de ella.
+func (tx *Transaction) calculateHash() string {
+	batch := make([]interface{}, len(tx.Batch))
+	for i, auth := range tx.Batch {
+		batch[i] = []interface{}{
+			auth.From,
+			auth.To,
+			auth.Amount,
+			auth.Nonce,
+			auth.ChainID,
+			auth.Signature,
+		}
+	}
+
+	return utils.Keccak256Hex(utils.RLPEncode([]interface{}{
+		tx.ChainID,
+		tx.From,
+		tx.To,
+		tx.Amount,
+		tx.Nonce,
+		tx.GasPrice,
+		tx.GasTipCap,
+		tx.GasLimit,
+		tx.Data,
+		batch,
+		tx.rlpMultisig(),
+		tx.Signature,
+		tx.PublicKeyX,
+		tx.PublicKeyY,
+	}))
+}
+
+// Sender devuelve el remitente (para el mempool)
+func (tx *Transaction) Sender() string {
+	return tx.From
+}
+
+// AccountNonce devuelve el nonce del remitente que consume (para el mempool)
+func (tx *Transaction) AccountNonce() int {
+	return tx.Nonce
+}
+
+// Cost devuelve lo máximo que puede gastar: monto + GasLimit × GasPrice (para el mempool)
+func (tx *Transaction) Cost() *big.Int {
+	cost := new(big.Int)
+	if tx.Amount != nil {
+		cost.Add(cost, tx.Amount)
+	}
+	if tx.GasPrice != nil {
+		cost.Add(cost, gasCost(tx.GasLimit, tx.GasPrice))
+	}
+	return cost
+}
+
+// Size devuelve los bytes que ocupa la transacción codificada (para el mempool)
+func (tx *Transaction) Size() int {
+	data, _ := json.Marshal(tx.ToAPI())
+	return len(data)
+}
+
+// VerifySignature verifica la firma y que la clave pública corresponda a From
+// En una multifirma, que reúne las firmas necesarias de sus titulares
 func (tx *Transaction) VerifySignature() bool {
+	if tx.IsMultisig() {
+		return tx.verifyMultisig() == nil
+	}
+
 	if tx.Signature == "" {
 		return false
 	}
@@ -91,28 +209,67 @@ func (tx *Transaction) VerifySignature() bool {
 		return false
 	}
 
-	// Obtener los datos que fueron firmados
-	dataToSign := tx.getDataToSign()
+	// Sin esta comprobación cualquiera podría firmar en nombre de otra dirección
+	if crypto.PublicKeyToAddress(tx.PublicKeyX, tx.PublicKeyY) != tx.From {
+		return false
+	}
 
-	// Verificar la firma
-	return crypto.VerifySignature(tx.PublicKeyX, tx.PublicKeyY, dataToSign, tx.Signature)
+	// Verificar la firma sobre el hash de todos los campos
+	return crypto.VerifySignature(tx.PublicKeyX, tx.PublicKeyY, tx.SigningHash(), tx.Signature)
 }
 
-// Validate valida la transacción antes de añadirla al mempool
+// Validate valida la transacción contra el estado actual
 func (tx *Transaction) Validate(state *AccountState, bc *Blockchain) error {
-	// Verificar que esté firmada
-	if tx.Signature == "" {
-		return fmt.Errorf("transacción no firmada")
+	return tx.validate(state, bc, state.GetAccount(tx.From).Nonce)
+}
+
+// checkAuthenticity verifica que la transacción esté bien firmada, para esta
+// red y con su hash canónico
+//
+// No depende del estado: se comprueba igual al admitirla en el mempool que al
+// importar un bloque de otro nodo (ver ImportBlock).
+func (tx *Transaction) checkAuthenticity(bc *Blockchain) error {
+	// Sin esto, firma y hash podrían hacer panic al codificar los campos
+	if err := tx.checkEncodable(); err != nil {
+		return err
+	}
+
+	// Verificar que esté firmada y que la firma sea válida
+	if tx.IsMultisig() {
+		if err := tx.verifyMultisig(); err != nil {
+			return fmt.Errorf("%w: multifirma: %v", ErrInvalidSignature, err)
+		}
+	} else if tx.Signature == "" {
+		return ErrNotSigned
+	} else if !tx.VerifySignature() {
+		return ErrInvalidSignature
 	}
 
-	// Verificar la firma
-	if !tx.VerifySignature() {
-		return fmt.Errorf("firma inválida")
+	// La firma tiene que ser para esta red (si no, sería un replay de otra)
+	if tx.ChainID != bc.ChainID {
+		return fmt.Errorf("%w: red %d (esta es la %d)", ErrWrongChain, tx.ChainID, bc.ChainID)
+	}
+
+	// El hash guardado tiene que ser el canónico (si no, se podría suplantar a otra)
+	if tx.TxHash != tx.calculateHash() {
+		return ErrInvalidHash
+	}
+
+	return nil
+}
+
+// validate valida la transacción esperando el nonce indicado
+func (tx *Transaction) validate(state *AccountState, bc *Blockchain, expectedNonce int) error {
+	if err := tx.checkAuthenticity(bc); err != nil {
+		return err
 	}
 
 	// Verificar que el monto no sea negativo
-	if tx.Amount < 0 {
-		return fmt.Errorf("monto no puede ser negativo: %.2f", tx.Amount)
+	if tx.Amount == nil {
+		return fmt.Errorf("%w: transacción sin monto", ErrInvalidAmount)
+	}
+	if tx.Amount.Sign() < 0 {
+		return fmt.Errorf("%w: no puede ser negativo: %s", ErrInvalidAmount, utils.FormatMTC(tx.Amount))
 	}
 
 	// Determinar tipo de transacción y validar
@@ -120,31 +277,157 @@ func (tx *Transaction) Validate(state *AccountState, bc *Blockchain) error {
 	isContractCall := tx.IsContractCall(bc)
 
 	// Validar que la transacción tenga propósito
-	if !isContractDeployment && !isContractCall && tx.Amount == 0 {
-		return fmt.Errorf("transacción sin propósito: sin monto, sin deploy, sin llamada")
+	if !isContractDeployment && !isContractCall && !tx.IsBatch() && tx.Amount.Sign() == 0 {
+		return fmt.Errorf("%w: sin monto, sin deploy, sin llamada", ErrNoPurpose)
 	}
 
 	// Verificar que el nonce sea correcto
 	account := state.GetAccount(tx.From)
-	expectedNonce := account.Nonce
+	if tx.Nonce < expectedNonce {
+		return fmt.Errorf("%w: esperado %d, recibido %d", ErrNonceTooLow, expectedNonce, tx.Nonce)
+	}
+	if tx.Nonce > expectedNonce {
+		return fmt.Errorf("%w: esperado %d, recibido %d", ErrNonceTooHigh, expectedNonce, tx.Nonce)
+	}
 
-	if tx.Nonce != expectedNonce {
-		return fmt.Errorf("nonce incorrecto: esperado %d, recibido %d", expectedNonce, tx.Nonce)
+	// Verificar el gas ofrecido
+	if err := tx.checkGas(bc); err != nil {
+		return err
+	}
+
+	// Verificar saldo suficiente para el monto y el gas máximo
+	if cost := tx.Cost(); account.Balance.Cmp(cost) < 0 {
+		return fmt.Errorf("%w: %s < %s (monto + gas máximo)",
+			ErrInsufficientFunds, utils.FormatMTC(account.Balance), utils.FormatMTC(cost))
 	}
 
-	// Verificar saldo suficiente (solo si hay transferencia de fondos)
-	if tx.Amount > 0 {
-		if account.Balance < tx.Amount {
-			return fmt.Errorf("saldo insuficiente: %.2f < %.2f", account.Balance, tx.Amount)
+	// Verificar que no use cambios del protocolo aún no activos
+	if err := bc.Config.checkForks(tx, len(bc.Blocks)); err != nil {
+		return err
+	}
+
+	// Verificar todas las autorizaciones del lote
+	if tx.IsBatch() {
+		if err := tx.validateBatch(state); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidBatch, err)
 		}
 	}
 
 	return nil
 }
 
+// DefaultGasPrice es el precio del gas de las transacciones creadas sin indicar otro
+// 1 gas = 10^12 unidades (0.000001 MTC)
+const DefaultGasPrice = 1_000_000_000_000
+
+// DefaultCallGasLimit es el gas que reservan por defecto las llamadas a contratos
+const DefaultCallGasLimit = 1000000
+
+// Gas intrínseco: lo que cuesta una transacción antes de ejecutar ningún contrato
+const (
+	txGas         = 21000 // Transferencia simple (y base de llamadas y lotes)
+	deployGas     = 32000 // Base de un despliegue
+	deployByteGas = 200   // Por byte de bytecode desplegado
+)
+
+// EffectiveGasPrice devuelve el precio por unidad de gas que paga la transacción
+// en un bloque con ese base fee: base fee + propina, sin pasar de GasPrice.
+// Sin base fee (nil) paga GasPrice entero.
+func (tx *Transaction) EffectiveGasPrice(baseFee *big.Int) *big.Int {
+	if tx.GasPrice == nil {
+		return new(big.Int)
+	}
+	if baseFee == nil {
+		return new(big.Int).Set(tx.GasPrice)
+	}
+
+	price := new(big.Int).Add(baseFee, tx.tipCap())
+	if price.Cmp(tx.GasPrice) > 0 {
+		price.Set(tx.GasPrice)
+	}
+	return price
+}
+
+// EffectiveTip devuelve la propina por gas que recibe el minero con ese base fee
+// Es negativa si GasPrice no llega al base fee (la transacción no puede entrar)
+func (tx *Transaction) EffectiveTip(baseFee *big.Int) *big.Int {
+	price := tx.EffectiveGasPrice(baseFee)
+	if baseFee == nil {
+		return price
+	}
+	return price.Sub(price, baseFee)
+}
+
+// tipCap es la propina máxima: GasTipCap o, si no se indica, todo GasPrice
+func (tx *Transaction) tipCap() *big.Int {
+	if tx.GasTipCap != nil {
+		return tx.GasTipCap
+	}
+	return tx.GasPrice
+}
+
+// gasCost calcula lo que cuesta una cantidad de gas al precio indicado
+func gasCost(gas uint64, price *big.Int) *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(gas), price)
+}
+
+// chargeGas reparte el coste del gas usado: el base fee se quema y la propina
+// es para el minero (tx.Fee). Devuelve el total pagado por el remitente.
+func (tx *Transaction) chargeGas(gasPrice, baseFee *big.Int) *big.Int {
+	paid := gasCost(tx.GasUsed, gasPrice)
+
+	tx.Burned = new(big.Int)
+	if baseFee != nil {
+		tx.Burned = gasCost(tx.GasUsed, baseFee)
+	}
+	tx.Fee = new(big.Int).Sub(paid, tx.Burned)
+
+	return paid
+}
+
+// intrinsicGas es el gas mínimo que consume la transacción
+// Un despliegue paga por su bytecode; un lote, por cada autorización; una
+// multifirma, por cada firma exigida
+func (tx *Transaction) intrinsicGas() uint64 {
+	gas := uint64(txGas)
+	if tx.IsContractDeployment() {
+		gas = deployGas + uint64(len(tx.Data))*deployByteGas
+	} else if tx.IsBatch() {
+		gas = txGas + uint64(len(tx.Batch))*batchTransferGas
+	}
+	return gas + tx.multisigGas()
+}
+
+// checkGas comprueba el precio y el límite de gas que ofrece la transacción
+// El límite tiene que cubrir el gas intrínseco y caber en un bloque
+func (tx *Transaction) checkGas(bc *Blockchain) error {
+	if tx.GasPrice == nil || tx.GasPrice.Sign() <= 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidGasPrice, utils.FormatMTC(tx.GasPrice))
+	}
+	if tx.GasTipCap != nil && (tx.GasTipCap.Sign() < 0 || tx.GasTipCap.Cmp(tx.GasPrice) > 0) {
+		return fmt.Errorf("%w: propina %s (máximo GasPrice, %s)",
+			ErrInvalidGasPrice, utils.FormatMTC(tx.GasTipCap), utils.FormatMTC(tx.GasPrice))
+	}
+	if intrinsic := tx.intrinsicGas(); tx.GasLimit < intrinsic {
+		return fmt.Errorf("%w: %d (mínimo %d)", ErrIntrinsicGas, tx.GasLimit, intrinsic)
+	}
+	if tx.GasLimit > bc.GasLimit {
+		return fmt.Errorf("%w: %d (bloque: %d)", ErrGasLimit, tx.GasLimit, bc.GasLimit)
+	}
+	return nil
+}
+
 // Execute ejecuta la transacción con lógica de revert (como Ethereum)
 func (tx *Transaction) Execute(state *AccountState, bc *Blockchain) error {
-	gasPrice := 0.000001 // 1 gas = 0.000001 MTC
+	// El bloque que se ejecuta es el siguiente a la cabeza actual
+	baseFee := bc.BaseFee()
+
+	// Sin restos de una ejecución anterior (p. ej. de un bloque que no se selló)
+	tx.Status = ReceiptStatusFailed
+	tx.RevertReason = ""
+	tx.ReturnData = nil
+	tx.GasUsed = 0
+	tx.Logs = nil
 
 	// ====================================
 	// FASE 1: VALIDACIONES PREVIAS
@@ -152,25 +435,41 @@ func (tx *Transaction) Execute(state *AccountState, bc *Blockchain) error {
 
 	account := state.GetAccount(tx.From)
 
-	// Calcular gas máximo necesario
-	var gasLimit uint64
-	if tx.IsContractDeployment() {
-		baseGas := uint64(32000)
-		bytecodeGas := uint64(len(tx.Data)) * 200
-		gasLimit = baseGas + bytecodeGas
-	} else if len(tx.Data) > 0 || tx.IsContractCall(bc) {
-		gasLimit = 1000000 // Gas límite para ejecución
-	} else {
-		gasLimit = 21000 // Gas base para transferencia simple
+	if tx.Amount == nil || tx.Amount.Sign() < 0 {
+		return fmt.Errorf("monto inválido")
 	}
 
-	maxGasCost := float64(gasLimit) * gasPrice
+	// Las transacciones de un remitente se ejecutan estrictamente en orden
+	if tx.Nonce != account.Nonce {
+		return fmt.Errorf("nonce incorrecto: esperado %d, recibido %d", account.Nonce, tx.Nonce)
+	}
+
+	if err := bc.Config.checkForks(tx, len(bc.Blocks)); err != nil {
+		return err
+	}
+
+	if err := tx.checkGas(bc); err != nil {
+		return err
+	}
+
+	// Sin llegar al base fee la transacción no puede entrar en el bloque
+	if baseFee != nil && tx.GasPrice.Cmp(baseFee) < 0 {
+		return fmt.Errorf("precio del gas %s por debajo del base fee %s",
+			utils.FormatMTC(tx.GasPrice), utils.FormatMTC(baseFee))
+	}
+	gasPrice := tx.EffectiveGasPrice(baseFee)
+
+	// Gas máximo que el remitente está dispuesto a pagar
+	gasLimit := tx.GasLimit
+
+	maxGasCost := gasCost(gasLimit, tx.GasPrice) // Se reserva al precio máximo
 
 	// Verificar saldo para: monto + gas máximo
-	totalNeeded := tx.Amount + maxGasCost
-	if account.Balance < totalNeeded {
-		return fmt.Errorf("saldo insuficiente: tiene %.6f MTC, necesita %.6f MTC (monto: %.2f + gas máximo: %.6f)",
-			account.Balance, totalNeeded, tx.Amount, maxGasCost)
+	totalNeeded := new(big.Int).Add(tx.Amount, maxGasCost)
+	if account.Balance.Cmp(totalNeeded) < 0 {
+		return fmt.Errorf("saldo insuficiente: tiene %s MTC, necesita %s MTC (monto: %s + gas máximo: %s)",
+			utils.FormatMTC(account.Balance), utils.FormatMTC(totalNeeded),
+			utils.FormatMTC(tx.Amount), utils.FormatMTC(maxGasCost))
 	}
 
 	// ====================================
@@ -179,12 +478,12 @@ func (tx *Transaction) Execute(state *AccountState, bc *Blockchain) error {
 
 	accountSnapshot := state.CreateSnapshot()
 
+	// Con CALL un contrato puede cambiar el storage de cualquier otro
 	var storageSnapshots map[string]map[string]*big.Int
 	if tx.IsContractCall(bc) {
 		storageSnapshots = make(map[string]map[string]*big.Int)
-		contract, _ := bc.GetContract(tx.To)
-		if contract != nil {
-			storageSnapshots[tx.To] = contract.Storage.CreateSnapshot()
+		for address, contract := range bc.Contracts {
+			storageSnapshots[address] = contract.Storage.CreateSnapshot()
 		}
 	}
 
@@ -210,7 +509,7 @@ func (tx *Transaction) Execute(state *AccountState, bc *Blockchain) error {
 	var executionError error
 
 	// Transferir fondos si aplica
-	if tx.Amount > 0 {
+	if tx.Amount.Sign() > 0 {
 		if err := state.SubtractBalance(tx.From, tx.Amount); err != nil {
 			executionError = err
 		} else if tx.To != "" {
@@ -218,10 +517,17 @@ func (tx *Transaction) Execute(state *AccountState, bc *Blockchain) error {
 		}
 	}
 
-	// Ejecutar contrato si aplica
-	if executionError == nil && (len(tx.Data) > 0 || tx.IsContractCall(bc)) {
+	// Aplicar el lote si aplica (todo o nada: el revert deshace lo aplicado)
+	if executionError == nil && tx.IsBatch() {
+		if err := tx.applyBatch(state); err != nil {
+			executionError = fmt.Errorf("error aplicando lote: %v", err)
+		} else {
+			tx.GasUsed = tx.intrinsicGas()
+			fmt.Printf("   📦 Lote aplicado: %d transferencias off-chain\n", len(tx.Batch))
+		}
+	} else if executionError == nil && (len(tx.Data) > 0 || tx.IsContractCall(bc)) {
 		if err := tx.ExecuteContract(bc); err != nil {
-			executionError = fmt.Errorf("error ejecutando contrato: %v", err)
+			executionError = err // ExecuteContract ya dice si falló el despliegue o la llamada
 		}
 
 		// Si no se registró GasUsed, significa que falló antes de calcular
@@ -230,7 +536,7 @@ func (tx *Transaction) Execute(state *AccountState, bc *Blockchain) error {
 		}
 	} else if executionError == nil {
 		// Transacción simple - gas base
-		tx.GasUsed = 21000
+		tx.GasUsed = tx.intrinsicGas()
 	}
 
 	// ====================================
@@ -242,6 +548,9 @@ func (tx *Transaction) Execute(state *AccountState, bc *Blockchain) error {
 		fmt.Printf("   ❌ Error en ejecución: %v\n", executionError)
 		fmt.Printf("   🔄 Revirtiendo cambios de estado...\n")
 
+		// Queda en el recibo para que se pueda depurar después
+		tx.RevertReason = executionError.Error()
+
 		// Revertir estado de cuentas (excepto nonce y gas)
 		currentNonce := state.GetAccount(tx.From).Nonce
 		currentBalance := state.GetAccount(tx.From).Balance
@@ -262,36 +571,62 @@ func (tx *Transaction) Execute(state *AccountState, bc *Blockchain) error {
 			}
 		}
 
-		// Consumir TODO el gas (penalización)
-		tx.GasUsed = gasLimit
-		gasCostUsed := float64(tx.GasUsed) * gasPrice
+		// Un REVERT paga el gas que usó y conserva su motivo; cualquier otro
+		// fallo consume TODO el gas (penalización)
+		reverted := errors.Is(executionError, evm.ErrExecutionReverted)
+		if !reverted {
+			tx.GasUsed = gasLimit
+			tx.ReturnData = nil
+		}
+		gasCostUsed := tx.chargeGas(gasPrice, baseFee)
 
-		fmt.Printf("   ⛽ Gas consumido (penalización): %.6f MTC (%d gas)\n", gasCostUsed, tx.GasUsed)
+		if reverted {
+			fmt.Printf("   ⛽ Gas consumido hasta el REVERT: %s MTC (%d gas)\n", utils.FormatMTC(gasCostUsed), tx.GasUsed)
+		} else {
+			fmt.Printf("   ⛽ Gas consumido (penalización): %s MTC (%d gas)\n", utils.FormatMTC(gasCostUsed), tx.GasUsed)
+		}
 
-		// El gas ya fue restado, así que no hacemos nada más
+		// Solo se devuelve lo reservado por encima del precio efectivo
+		tx.Refund = new(big.Int).Sub(maxGasCost, gasCostUsed)
+		if tx.Refund.Sign() > 0 {
+			state.AddBalance(tx.From, tx.Refund)
+		}
 
 	} else {
 		// ✅ EJECUCIÓN EXITOSA
-		gasCostUsed := float64(tx.GasUsed) * gasPrice
-		gasRefund := maxGasCost - gasCostUsed
+		tx.Status = ReceiptStatusSuccessful
+		gasCostUsed := tx.chargeGas(gasPrice, baseFee)
+
+		// Los fondos enviados a la dirección de staking quedan bloqueados
+		if tx.IsStake() {
+			state.AddStake(tx.From, tx.Amount)
+			fmt.Printf("   🔒 Stake bloqueado: %s MTC (total: %s MTC)\n",
+				utils.FormatMTC(tx.Amount), utils.FormatMTC(state.GetStake(tx.From)))
+		}
+		gasRefund := new(big.Int).Sub(maxGasCost, gasCostUsed)
+		tx.Refund = gasRefund
 
 		// Devolver gas no usado
-		if gasRefund > 0 {
+		if gasRefund.Sign() > 0 {
 			state.AddBalance(tx.From, gasRefund)
-			fmt.Printf("   ⛽ Gas usado: %.6f MTC (%d gas)\n", gasCostUsed, tx.GasUsed)
-			fmt.Printf("   💰 Gas devuelto: %.6f MTC\n", gasRefund)
+			fmt.Printf("   ⛽ Gas usado: %s MTC (%d gas)\n", utils.FormatMTC(gasCostUsed), tx.GasUsed)
+			fmt.Printf("   💰 Gas devuelto: %s MTC\n", utils.FormatMTC(gasRefund))
 		} else {
-			fmt.Printf("   ⛽ Costo de gas: %.6f MTC (%d gas × %.6f)\n",
-				gasCostUsed, tx.GasUsed, gasPrice)
+			fmt.Printf("   ⛽ Costo de gas: %s MTC (%d gas × %s)\n",
+				utils.FormatMTC(gasCostUsed), tx.GasUsed, utils.FormatMTC(gasPrice))
 		}
 	}
 
+	if tx.Burned.Sign() > 0 {
+		fmt.Printf("   🔥 Base fee quemado: %s MTC\n", utils.FormatMTC(tx.Burned))
+	}
+
 	return nil
 }
 
-// ToJSON convierte la transacción a JSON
+// ToJSON convierte la transacción a JSON con el esquema estable de la API
 func (tx *Transaction) ToJSON() (string, error) {
-	data, err := json.Marshal(tx)
+	data, err := json.Marshal(tx.ToAPI())
 	if err != nil {
 		return "", err
 	}
@@ -304,11 +639,25 @@ func (tx *Transaction) Print() {
 	fmt.Println("│          💸 TRANSACCIÓN                │")
 	fmt.Println("└────────────────────────────────────────┘")
 	fmt.Printf("📤 From:      %s\n", tx.From[:16]+"...")
-	fmt.Printf("📥 To:        %s\n", tx.To[:16]+"...")
-	fmt.Printf("💰 Amount:    %.2f MTC\n", tx.Amount)
+	if tx.IsBatch() {
+		fmt.Printf("📦 Lote:      %d transferencias off-chain\n", len(tx.Batch))
+	} else {
+		fmt.Printf("📥 To:        %s\n", tx.To[:16]+"...")
+	}
+	fmt.Printf("💰 Amount:    %s MTC\n", utils.FormatMTC(tx.Amount))
 	fmt.Printf("🔢 Nonce:     %d\n", tx.Nonce)
-
-	if tx.Signature != "" {
+	fmt.Printf("⛽ Gas:       %d × %s MTC\n", tx.GasLimit, utils.FormatMTC(tx.GasPrice))
+	fmt.Printf("🔑 Hash:      %s\n", tx.Hash())
+
+	if tx.IsMultisig() {
+		fmt.Printf("👥 Multifirma: %d de %d titulares, %d firmas reunidas\n",
+			tx.Multisig.Threshold, len(tx.Multisig.Owners), len(tx.Multisig.Signatures))
+		if err := tx.verifyMultisig(); err != nil {
+			fmt.Printf("⚠️  Aún no válida: %v\n", err)
+		} else {
+			fmt.Printf("🔐 Válida:    Sí\n")
+		}
+	} else if tx.Signature != "" {
 		fmt.Printf("✍️  Signature: %s...\n", tx.Signature[:16])
 		fmt.Printf("✅ Firmada:   Sí\n")
 		if tx.VerifySignature() {
@@ -324,22 +673,26 @@ func (tx *Transaction) Print() {
 // NewContractDeploymentTx crea una transacción para desplegar un contrato
 func NewContractDeploymentTx(from string, bytecode []byte, nonce int) *Transaction {
 	return &Transaction{
-		From:   from,
-		To:     "", // Vacío = deploy
-		Amount: 0,
-		Nonce:  nonce,
-		Data:   bytecode,
+		From:     from,
+		To:       "", // Vacío = deploy
+		Amount:   new(big.Int),
+		Nonce:    nonce,
+		Data:     bytecode,
+		GasPrice: big.NewInt(DefaultGasPrice),
+		GasLimit: deployGas + uint64(len(bytecode))*deployByteGas,
 	}
 }
 
 // NewContractCallTx crea una transacción para llamar a un contrato
 func NewContractCallTx(from, contractAddr string, calldata []byte, nonce int) *Transaction {
 	return &Transaction{
-		From:   from,
-		To:     contractAddr,
-		Amount: 0,
-		Nonce:  nonce,
-		Data:   calldata,
+		From:     from,
+		To:       contractAddr,
+		Amount:   new(big.Int),
+		Nonce:    nonce,
+		Data:     calldata,
+		GasPrice: big.NewInt(DefaultGasPrice),
+		GasLimit: DefaultCallGasLimit,
 	}
 }
 
@@ -357,9 +710,9 @@ func (tx *Transaction) ExecuteContract(bc *Blockchain) error {
 
 		// Cobrar gas por deployment (costo base)
 		// En Ethereum real: ~32,000 gas por deploy + gas por bytecode
-		baseGas := uint64(32000)
-		bytecodeGas := uint64(len(tx.Data)) * 200 // 200 gas por byte
-		tx.GasUsed = baseGas + bytecodeGas
+		baseGas := uint64(deployGas)
+		bytecodeGas := uint64(len(tx.Data)) * deployByteGas
+		tx.GasUsed = tx.intrinsicGas() // Más la verificación de firmas si es multifirma
 
 		fmt.Printf("   📜 Contrato desplegado: %s\n", contract.Address[:16]+"...")
 		fmt.Printf("   ⛽ Gas deployment: %d (base: %d + bytecode: %d)\n",
@@ -376,13 +729,33 @@ func (tx *Transaction) ExecuteContract(bc *Blockchain) error {
 
 		fmt.Printf("   ⚙️  Ejecutando contrato %s...\n\n", tx.To[:16]+"...")
 
-		// Ejecutar con el intérprete global
-		gasLeft, err := contract.Execute(1000000)
+		// Ejecutar con el intérprete global: el contrato dispone de lo que queda
+		// del límite tras el gas intrínseco de la transacción y recibe Data
+		// como argumentos (Amount ya está en su saldo)
+		msg := &evm.Message{
+			Caller: tx.From,
+			Origin: tx.From,
+			Value:  tx.Amount,
+			Data:   tx.Data,
+			Gas:    tx.GasLimit - tx.intrinsicGas(),
+
+			GasPrice: tx.EffectiveGasPrice(bc.BaseFee()),
+		}
+		host := bc.newEVMHost()
+		returnData, gasLeft, err := contract.CallWith(msg, host, bc.NewBlockContext())
+		if errors.Is(err, evm.ErrExecutionReverted) {
+			// Solo se cobra el gas usado hasta el REVERT
+			tx.GasUsed = tx.GasLimit - gasLeft
+			tx.ReturnData = returnData
+			return revertError(returnData)
+		}
 		if err != nil {
 			return fmt.Errorf("error ejecutando contrato: %v", err)
 		}
 
-		tx.GasUsed = 1000000 - gasLeft
+		tx.GasUsed = tx.GasLimit - gasLeft
+		tx.ReturnData = returnData
+		tx.Logs = host.chainLogs() // Solo si no revierte: un REVERT descarta sus eventos
 		fmt.Printf("\n   ✅ Contrato ejecutado. Gas usado: %d\n", tx.GasUsed)
 
 		return nil
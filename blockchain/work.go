@@ -0,0 +1,109 @@
+package blockchain
+
+import (
+	"fmt"
+	"minichain/utils"
+)
+
+// MaxPendingWork es cuántos candidatos de GetWork se recuerdan a la vez
+// Un minero que pide trabajo sin parar no hace crecer la memoria del nodo: al
+// pasarse, se olvidan los más antiguos (su solución ya no se acepta).
+const MaxPendingWork = 16
+
+// Work es un bloque candidato listo para que un minero externo busque el nonce
+//
+// El minero prueba nonces hasta que SHA-256(Header + nonce en decimal), leído
+// como número, sea <= Target. No necesita conocer el formato de los bloques.
+type Work struct {
+	ID     string // Identificador para devolver la solución (hash de Header)
+	Index  int    // Altura del bloque candidato
+	Header string // Cabecera sin el nonce (Block.SealingData)
+	Target string // Objetivo en hexadecimal (64 caracteres)
+}
+
+// GetWork arma un bloque candidato con las transacciones pendientes
+// El estado no cambia: el bloque se ejecuta para fijar su StateRoot y se
+// deshace. Solo se aplica de verdad cuando llega una solución con SubmitWork.
+func (bc *Blockchain) GetWork() (*Work, error) {
+	if _, ok := bc.Engine.(*ProofOfWork); !ok {
+		return nil, fmt.Errorf("el motor de consenso %q no usa minado", bc.Engine.Name())
+	}
+
+	block, snapshot, err := bc.prepareBlock()
+	if err != nil {
+		return nil, err
+	}
+	bc.revertState(snapshot)
+	block.Bits = bc.Bits
+
+	header := block.SealingData()
+	work := &Work{
+		ID:     utils.CalculateHash(header),
+		Index:  block.Index,
+		Header: header,
+		Target: fmt.Sprintf("%064x", utils.CompactToTarget(block.Bits)),
+	}
+
+	bc.workMu.Lock()
+	bc.rememberWork(work.ID, block)
+	bc.workMu.Unlock()
+
+	return work, nil
+}
+
+// rememberWork guarda un candidato y olvida los de otras alturas (ya no pueden
+// entrar en la cadena) y los más antiguos por encima de MaxPendingWork
+// Requiere workMu tomado
+func (bc *Blockchain) rememberWork(id string, block *Block) {
+	order := bc.workOrder[:0]
+	for _, old := range bc.workOrder {
+		if old != id && bc.pendingWork[old].Index == block.Index {
+			order = append(order, old)
+		} else {
+			delete(bc.pendingWork, old)
+		}
+	}
+	order = append(order, id)
+
+	if excess := len(order) - MaxPendingWork; excess > 0 {
+		for _, old := range order[:excess] {
+			delete(bc.pendingWork, old)
+		}
+		order = append(order[:0], order[excess:]...)
+	}
+
+	bc.pendingWork[id] = block
+	bc.workOrder = order
+}
+
+// SubmitWork recibe el nonce encontrado por un minero externo para un trabajo
+// El bloque se importa como cualquier otro (sello, coinbase y StateRoot).
+// Los trabajos de alturas anteriores caducan, y también los que GetWork ya
+// olvidó (ver MaxPendingWork).
+func (bc *Blockchain) SubmitWork(id string, nonce int) (*Block, error) {
+	bc.workMu.Lock()
+	candidate, exists := bc.pendingWork[id]
+	bc.workMu.Unlock()
+	if !exists {
+		return nil, fmt.Errorf("trabajo %s desconocido o caducado", id)
+	}
+
+	block := *candidate
+	block.Nonce = nonce
+	block.Hash = block.CalculateBlockHash()
+
+	if err := bc.ImportBlock(&block); err != nil {
+		return nil, err
+	}
+
+	// El bloque ya está en la cadena: ningún trabajo pendiente sigue siendo válido
+	bc.workMu.Lock()
+	bc.pendingWork = make(map[string]*Block)
+	bc.workOrder = nil
+	bc.workMu.Unlock()
+
+	fmt.Printf("\n✅ Bloque %d minado externamente (nonce: %d)\n", block.Index, block.Nonce)
+	fmt.Printf("   Hash: %s\n", block.Hash)
+
+	return &block, nil
+}
@@ -0,0 +1,55 @@
+package blockchain
+
+import (
+	"fmt"
+	"testing"
+)
+
+// TestGetWorkBounded: pedir trabajo sin parar no acumula candidatos; los más
+// antiguos se olvidan y su solución ya no se acepta
+func TestGetWorkBounded(t *testing.T) {
+	key := newKey(t)
+	bc := fundedChain(t, key)
+
+	var first string
+	for i := 0; i < MaxPendingWork+5; i++ {
+		bc.Coinbase = fmt.Sprintf("%040x", i+1) // Cada coinbase da otro candidato
+		work, err := bc.GetWork()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			first = work.ID
+		}
+	}
+
+	if len(bc.pendingWork) != MaxPendingWork || len(bc.workOrder) != MaxPendingWork {
+		t.Fatalf("%d candidatos (%d en orden), esperaba %d", len(bc.pendingWork), len(bc.workOrder), MaxPendingWork)
+	}
+	if _, err := bc.SubmitWork(first, 0); err == nil {
+		t.Fatal("se aceptó la solución de un trabajo olvidado")
+	}
+}
+
+// TestGetWorkDropsStaleHeights: un bloque nuevo deja sin valor los candidatos
+// de la altura anterior
+func TestGetWorkDropsStaleHeights(t *testing.T) {
+	key := newKey(t)
+	bc := fundedChain(t, key)
+
+	stale, err := bc.GetWork()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bc.AddTransaction(signedTransfer(t, bc, key, 0)); err != nil {
+		t.Fatal(err)
+	}
+	bc.MineBlock()
+
+	if _, err := bc.GetWork(); err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := bc.pendingWork[stale.ID]; exists || len(bc.pendingWork) != 1 {
+		t.Fatalf("quedan %d candidatos, con el de la altura %d", len(bc.pendingWork), stale.Index)
+	}
+}
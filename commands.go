@@ -0,0 +1,360 @@
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"minichain/blockchain"
+	"minichain/compiler"
+	"minichain/evm"
+	"minichain/i18n"
+	"minichain/mempool"
+	"minichain/rpc"
+	"minichain/utils"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Command es un subcomando del binario minichain
+type Command struct {
+	Usage       string // Cómo se invoca
+	Description string // Clave del catálogo con qué hace (una línea)
+	Run         func(args []string) error
+}
+
+// commands son todos los subcomandos disponibles
+var commands map[string]*Command
+
+func init() {
+	commands = map[string]*Command{
+		"console": {
+			Usage:       "console [--genesis GENESIS.json] [--import CADENA.jsonl] [--miner.threads N] [--miner.coinbase ADDR] [--consensus pow|pos]",
+			Description: "cli.console",
+			Run:         runConsole,
+		},
+		"compile": {
+			Usage:       "compile [--run] ARCHIVO.asm",
+			Description: "cli.compile",
+			Run:         runCompile,
+		},
+		"bench": {
+			Usage:       "bench [--blocks N] [--txs N] [--save ARCHIVO] [--baseline ARCHIVO] [--tolerance %]",
+			Description: "cli.bench",
+			Run:         runBench,
+		},
+		"disasm": {
+			Usage:       "disasm BYTECODE_HEX",
+			Description: "cli.disasm",
+			Run:         runDisasm,
+		},
+		"init": {
+			Usage:       "init GENESIS.json",
+			Description: "cli.init",
+			Run:         runInit,
+		},
+		"import": {
+			Usage:       "import --genesis GENESIS.json [--consensus pow|pos] CADENA.jsonl",
+			Description: "cli.import",
+			Run:         runImport,
+		},
+		"serve": {
+			Usage:       "serve --genesis GENESIS.json [--import CADENA.jsonl] [--rpc.addr HOST:PUERTO] [--rpc.corsdomains ORÍGENES] [--rpc.authtoken TOKEN] [--rpc.ratelimit N] [--miner.coinbase ADDR] [--consensus pow|pos] [--mine] [--mempool.lifetime DURACIÓN] [--mempool.maxblocks N] [--health.maxlag N]",
+			Description: "cli.serve",
+			Run:         runServe,
+		},
+		"help": {
+			Usage:       "help",
+			Description: "cli.help",
+			Run: func(args []string) error {
+				printUsage()
+				return nil
+			},
+		},
+	}
+}
+
+// addLangFlag añade --lang a un subcomando (por defecto, el idioma activo)
+func addLangFlag(flags *flag.FlagSet) *string {
+	return flags.String("lang", string(i18n.Current()), i18n.T("cli.lang"))
+}
+
+// printUsage muestra la lista de subcomandos
+func printUsage() {
+	fmt.Println("\n" + i18n.T("cli.usage"))
+	fmt.Println("\n" + i18n.T("cli.subcommands"))
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Printf("  %s\n      %s\n", commands[name].Usage, i18n.T(commands[name].Description))
+	}
+}
+
+// runCompile compila un archivo de assembly y muestra el bytecode
+func runCompile(args []string) error {
+	flags := flag.NewFlagSet("compile", flag.ExitOnError)
+	run := flags.Bool("run", false, "ejecutar el bytecode en la EVM tras compilarlo")
+	gas := flags.Uint64("gas", 1000000, "gas disponible al ejecutar")
+	lang := addLangFlag(flags)
+	flags.Parse(args)
+	if err := i18n.SetLanguage(*lang); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("uso: minichain %s", commands["compile"].Usage)
+	}
+
+	source, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("no se pudo leer %s: %v", flags.Arg(0), err)
+	}
+
+	bytecode, err := compiler.NewAssembler().Assemble(string(source))
+	if err != nil {
+		return fmt.Errorf("error compilando: %v", err)
+	}
+
+	compiler.PrintBytecode(bytecode)
+
+	if !*run {
+		return nil
+	}
+
+	// Ejecutar en un contrato temporal (no se guarda en ninguna cadena)
+	contract := evm.NewContract("compile", bytecode)
+	returnData, gasLeft, err := contract.Execute(*gas, nil)
+	if err != nil {
+		return fmt.Errorf("error ejecutando: %v", err)
+	}
+
+	fmt.Printf("\n⛽ Gas usado: %d\n", *gas-gasLeft)
+	if returnData != nil {
+		fmt.Printf("📤 Devuelve: 0x%x\n", returnData)
+	}
+	contract.Storage.Print()
+
+	return nil
+}
+
+// runInit crea el génesis de una especificación y muestra su identidad
+// Todos los nodos que arranquen con el mismo archivo deben ver el mismo hash
+func runInit(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("uso: minichain %s", commands["init"].Usage)
+	}
+
+	genesis, err := blockchain.LoadGenesis(args[0])
+	if err != nil {
+		return err
+	}
+
+	bc, err := blockchain.NewBlockchainFromGenesis(genesis)
+	if err != nil {
+		return err
+	}
+	block := bc.Blocks[0]
+
+	fmt.Printf("\n📜 Génesis de %s\n", args[0])
+	fmt.Printf("   Chain ID:    %d\n", bc.ChainID)
+	fmt.Printf("   Hash:        %s\n", block.Hash)
+	fmt.Printf("   State Root:  %s\n", block.StateRoot)
+	fmt.Printf("   Bits:        %08x\n", block.Bits)
+	fmt.Printf("   Gas límite:  %d\n", bc.GasLimit)
+
+	addresses := make([]string, 0, len(genesis.Alloc))
+	for address := range genesis.Alloc {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	fmt.Printf("\n💰 Reparto inicial (%d cuentas):\n", len(addresses))
+	for _, address := range addresses {
+		account := genesis.Alloc[address]
+		fmt.Printf("   %s: %s MTC", address, utils.FormatMTC(account.Balance))
+		if account.Stake != nil && account.Stake.Sign() > 0 {
+			fmt.Printf(" (+%s MTC en stake)", utils.FormatMTC(account.Stake))
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
+// runImport reconstruye una cadena exportada y muestra el estado resultante
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	genesisPath := flags.String("genesis", "", "especificación del génesis del que salió la cadena")
+	consensus := flags.String("consensus", "pow", "motor de consenso de la cadena: pow o pos")
+	flags.Parse(args)
+
+	if *genesisPath == "" || flags.NArg() != 1 {
+		return fmt.Errorf("uso: minichain %s", commands["import"].Usage)
+	}
+
+	genesis, err := blockchain.LoadGenesis(*genesisPath)
+	if err != nil {
+		return err
+	}
+
+	bc, err := blockchain.NewBlockchainFromGenesis(genesis)
+	if err != nil {
+		return err
+	}
+	if *consensus == "pos" {
+		// Verificar sellos no requiere claves: solo las firmas de las cabeceras
+		bc.Engine = blockchain.NewProofOfStake(nil)
+	}
+
+	var imported int
+	quiet(func() {
+		imported, err = importChainFile(bc, flags.Arg(0))
+	})
+	if err != nil {
+		return err
+	}
+
+	head := bc.Blocks[len(bc.Blocks)-1]
+	fmt.Printf("\n📥 %d bloques importados de %s\n", imported, flags.Arg(0))
+	fmt.Printf("   Cabeza:     #%d %s\n", head.Index, head.Hash)
+	fmt.Printf("   State Root: %s\n", head.StateRoot)
+
+	return nil
+}
+
+// runServe arranca un nodo sin consola que se controla por JSON-RPC
+// Los bloques se minan fuera del nodo con eth_getWork / eth_submitWork, o en
+// el propio nodo con --mine / miner_start
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	genesisPath := flags.String("genesis", "", "especificación del génesis de la red")
+	importPath := flags.String("import", "", "cargar los bloques de un archivo exportado")
+	rpcAddr := flags.String("rpc.addr", "127.0.0.1:8545", "dirección en la que escucha el servidor JSON-RPC")
+	corsDomains := flags.String("rpc.corsdomains", "", "orígenes de navegador permitidos, separados por comas (* = todos)")
+	rateLimit := flags.Float64("rpc.ratelimit", rpc.DefaultRateLimit, "peticiones por segundo por IP (0 = sin límite)")
+	maxBody := flags.Int64("rpc.maxbody", rpc.DefaultMaxBodyBytes, "tamaño máximo del cuerpo de una petición en bytes")
+	authToken := flags.String("rpc.authtoken", "", "token que exigen los métodos que cambian el estado (también MINICHAIN_RPC_TOKEN)")
+	minerCoinbase := flags.String("miner.coinbase", "", "dirección que cobra la recompensa de cada bloque")
+	consensus := flags.String("consensus", "pow", "motor de consenso de la cadena: pow o pos")
+	maxSyncLag := flags.Int("health.maxlag", rpc.DefaultMaxSyncLag, "bloques por detrás con los que /health sigue dando el nodo por listo")
+	mine := flags.Bool("mine", false, "minar en el nodo los bloques con transacciones pendientes")
+	mempoolLifetime := flags.Duration("mempool.lifetime", mempool.DefaultLifetime, "tiempo máximo de una pendiente sin minar (0 = sin límite)")
+	mempoolMaxBlocks := flags.Int("mempool.maxblocks", mempool.DefaultMaxBlocks, "bloques máximos de una pendiente sin minar (0 = sin límite)")
+	lang := addLangFlag(flags) // También el de los mensajes de error de la API
+	flags.Parse(args)
+	if err := i18n.SetLanguage(*lang); err != nil {
+		return err
+	}
+
+	if *genesisPath == "" || flags.NArg() != 0 {
+		return fmt.Errorf("uso: minichain %s", commands["serve"].Usage)
+	}
+
+	genesis, err := blockchain.LoadGenesis(*genesisPath)
+	if err != nil {
+		return err
+	}
+
+	bc, err := blockchain.NewBlockchainFromGenesis(genesis)
+	if err != nil {
+		return err
+	}
+	if *consensus == "pos" {
+		bc.Engine = blockchain.NewProofOfStake(nil) // Sin claves: solo verifica sellos
+	}
+	bc.Coinbase = *minerCoinbase
+	bc.Mempool.Lifetime = *mempoolLifetime
+	bc.Mempool.MaxBlocks = *mempoolMaxBlocks
+
+	head := bc.Blocks[len(bc.Blocks)-1]
+	fmt.Printf("\n🔗 Red %d, cabeza #%d %s\n", bc.ChainID, head.Index, head.Hash)
+	server := rpc.NewServer(bc)
+	if *corsDomains != "" {
+		server.CORSDomains = strings.Split(*corsDomains, ",")
+	}
+	server.AuthToken = *authToken
+	server.RateLimit = *rateLimit
+	server.RateBurst = max(1, int(2**rateLimit))
+	server.MaxBodyBytes = *maxBody
+	server.MaxSyncLag = *maxSyncLag
+	if server.AuthToken == "" {
+		server.AuthToken = os.Getenv("MINICHAIN_RPC_TOKEN") // No queda a la vista en la lista de procesos
+	}
+
+	// La importación sigue mientras el servidor ya escucha (ver /api/sync)
+	if *importPath != "" {
+		file, err := os.Open(*importPath)
+		if err != nil {
+			return fmt.Errorf("no se pudo abrir %s: %v", *importPath, err)
+		}
+		go func() {
+			defer file.Close()
+			imported, err := server.ImportChain(file)
+			if err != nil {
+				fmt.Printf("\n❌ Error importando %s: %v\n", *importPath, err)
+				return
+			}
+			fmt.Printf("\n📥 %d bloques importados de %s\n", imported, *importPath)
+		}()
+	}
+
+	server.StartExpiry()
+	if *mine {
+		server.StartMining()
+		fmt.Println("⛏️  Minando las transacciones pendientes")
+	}
+
+	fmt.Printf("🌐 JSON-RPC escuchando en http://%s\n", *rpcAddr)
+	if server.AuthToken == "" {
+		fmt.Println("⚠️  Sin --rpc.authtoken: cualquiera que llegue al puerto puede enviar transacciones y minar")
+	}
+
+	return http.ListenAndServe(*rpcAddr, server)
+}
+
+// importChainFile carga en bc los bloques de un archivo exportado
+func importChainFile(bc *blockchain.Blockchain, path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("no se pudo abrir %s: %v", path, err)
+	}
+	defer file.Close()
+
+	return bc.ImportChain(file)
+}
+
+// exportChainFile guarda los bloques de bc en un archivo
+func exportChainFile(bc *blockchain.Blockchain, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("no se pudo crear %s: %v", path, err)
+	}
+
+	if err := bc.ExportChain(file); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// runDisasm desensambla bytecode en hexadecimal
+func runDisasm(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("uso: minichain %s", commands["disasm"].Usage)
+	}
+
+	bytecode, err := hex.DecodeString(strings.TrimPrefix(args[0], "0x"))
+	if err != nil {
+		return fmt.Errorf("bytecode inválido: %v", err)
+	}
+
+	fmt.Print(compiler.NewAssembler().Disassemble(bytecode))
+	return nil
+}
@@ -17,34 +17,64 @@ type Assembler struct {
 func NewAssembler() *Assembler {
 	return &Assembler{
 		opcodeMap: map[string]evm.OpCode{
-			"STOP":   evm.STOP,
-			"ADD":    evm.ADD,
-			"MUL":    evm.MUL,
-			"SUB":    evm.SUB,
-			"DIV":    evm.DIV,
-			"MOD":    evm.MOD,
-			"LT":     evm.LT,
-			"GT":     evm.GT,
-			"EQ":     evm.EQ,
-			"POP":    evm.POP,
-			"MLOAD":  evm.MLOAD,
-			"MSTORE": evm.MSTORE,
-			"SLOAD":  evm.SLOAD,
-			"SSTORE": evm.SSTORE,
-			"JUMP":   evm.JUMP,
-			"JUMPI":  evm.JUMPI,
-			"PC":     evm.PC,
-			"PUSH1":  evm.PUSH1,
-			"PUSH2":  evm.PUSH2,
-			"PUSH3":  evm.PUSH3,
-			"PUSH4":  evm.PUSH4,
-			"PUSH5":  evm.PUSH5,
-			"PUSH32": evm.PUSH32,
-			"DUP1":   evm.DUP1,
-			"DUP2":   evm.DUP2,
-			"SWAP1":  evm.SWAP1,
-			"SWAP2":  evm.SWAP2,
-			"RETURN": evm.RETURN,
+			"STOP":         evm.STOP,
+			"ADD":          evm.ADD,
+			"MUL":          evm.MUL,
+			"SUB":          evm.SUB,
+			"DIV":          evm.DIV,
+			"MOD":          evm.MOD,
+			"LT":           evm.LT,
+			"GT":           evm.GT,
+			"EQ":           evm.EQ,
+			"ADDRESS":      evm.ADDRESS,
+			"BALANCE":      evm.BALANCE,
+			"ORIGIN":       evm.ORIGIN,
+			"CALLER":       evm.CALLER,
+			"CALLVALUE":    evm.CALLVALUE,
+			"CALLDATALOAD": evm.CALLDATALOAD,
+			"CALLDATASIZE": evm.CALLDATASIZE,
+			"CALLDATACOPY": evm.CALLDATACOPY,
+			"GASPRICE":     evm.GASPRICE,
+			"EXTCODESIZE":  evm.EXTCODESIZE,
+			"EXTCODECOPY":  evm.EXTCODECOPY,
+			"EXTCODEHASH":  evm.EXTCODEHASH,
+			"BLOCKHASH":    evm.BLOCKHASH,
+			"COINBASE":     evm.COINBASE,
+			"TIMESTAMP":    evm.TIMESTAMP,
+			"NUMBER":       evm.NUMBER,
+			"DIFFICULTY":   evm.DIFFICULTY,
+			"PREVRANDAO":   evm.PREVRANDAO,
+			"SELFBALANCE":  evm.SELFBALANCE,
+			"POP":          evm.POP,
+			"MLOAD":        evm.MLOAD,
+			"MSTORE":       evm.MSTORE,
+			"SLOAD":        evm.SLOAD,
+			"SSTORE":       evm.SSTORE,
+			"JUMP":         evm.JUMP,
+			"JUMPI":        evm.JUMPI,
+			"PC":           evm.PC,
+			"GAS":          evm.GAS,
+			"JUMPDEST":     evm.JUMPDEST,
+			"PUSH1":        evm.PUSH1,
+			"PUSH2":        evm.PUSH2,
+			"PUSH3":        evm.PUSH3,
+			"PUSH4":        evm.PUSH4,
+			"PUSH5":        evm.PUSH5,
+			"PUSH32":       evm.PUSH32,
+			"DUP1":         evm.DUP1,
+			"DUP2":         evm.DUP2,
+			"SWAP1":        evm.SWAP1,
+			"SWAP2":        evm.SWAP2,
+			"LOG0":         evm.LOG0,
+			"LOG1":         evm.LOG1,
+			"LOG2":         evm.LOG2,
+			"LOG3":         evm.LOG3,
+			"LOG4":         evm.LOG4,
+			"CALL":         evm.CALL,
+			"DELEGATECALL": evm.DELEGATECALL,
+			"STATICCALL":   evm.STATICCALL,
+			"RETURN":       evm.RETURN,
+			"REVERT":       evm.REVERT,
 		},
 	}
 }
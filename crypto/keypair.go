@@ -34,8 +34,14 @@ func GenerateKeyPair() (*KeyPair, error) {
 // GetAddress convierte la clave pública en una dirección legible
 // Similar a cómo Bitcoin/Ethereum generan direcciones desde la clave pública
 func (kp *KeyPair) GetAddress() string {
+	return PublicKeyToAddress(kp.PublicKey.X, kp.PublicKey.Y)
+}
+
+// PublicKeyToAddress deriva la dirección a partir de las coordenadas de una clave pública
+// Permite comprobar que una firma pertenece realmente a la dirección que dice firmar
+func PublicKeyToAddress(x, y *big.Int) string {
 	// Concatenar las coordenadas X e Y de la clave pública
-	pubKeyBytes := append(kp.PublicKey.X.Bytes(), kp.PublicKey.Y.Bytes()...)
+	pubKeyBytes := append(x.Bytes(), y.Bytes()...)
 
 	// Hash SHA-256 de la clave pública
 	hash := sha256.Sum256(pubKeyBytes)
@@ -59,8 +65,11 @@ func (kp *KeyPair) SignData(data []byte) (string, error) {
 		return "", fmt.Errorf("error firmando: %v", err)
 	}
 
-	// Combinar r y s en una sola firma
-	signature := append(r.Bytes(), s.Bytes()...)
+	// Combinar r y s en una sola firma (32 bytes cada uno, con ceros a la izquierda)
+	// Sin relleno, un r o s corto desplaza a s y la firma deja de verificar
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
 
 	return hex.EncodeToString(signature), nil
 }
@@ -0,0 +1,38 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+// TestSignDataPadsShortValues: r y s van siempre en 32 bytes, aunque el
+// número tenga ceros a la izquierda (pasa en 1 de cada 128 firmas)
+func TestSignDataPadsShortValues(t *testing.T) {
+	keyPair, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("minichain")
+	x, y := keyPair.PublicKey.X, keyPair.PublicKey.Y
+
+	short := new(big.Int).Lsh(big.NewInt(1), 248) // Menos de 32 bytes
+	for shortSeen := 0; shortSeen < 3; {
+		signature, err := keyPair.SignData(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(signature) != 128 {
+			t.Fatalf("firma de %d caracteres, esperaba 128", len(signature))
+		}
+		if !VerifySignature(x, y, data, signature) {
+			t.Fatalf("la firma %s no verifica", signature)
+		}
+
+		raw, _ := hex.DecodeString(signature)
+		r, s := new(big.Int).SetBytes(raw[:32]), new(big.Int).SetBytes(raw[32:])
+		if r.Cmp(short) < 0 || s.Cmp(short) < 0 {
+			shortSeen++
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package evm
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Gas de CALL además del coste fijo del opcode
+const (
+	callValueGas = 9000 // Por enviar fondos con la llamada
+	callStipend  = 2300 // Gas gratis que recibe quien cobra, para poder reaccionar
+)
+
+// callKind distingue las variantes de CALL
+type callKind int
+
+const (
+	callNormal   callKind = iota // CALL: el código de otro, en su contexto
+	callDelegate                 // DELEGATECALL: el código de otro, en el contexto propio
+	callStatic                   // STATICCALL: como CALL pero sin poder cambiar el estado
+)
+
+// opCall: gas, dirección, valor, argsOffset, argsSize, retOffset, retSize -> éxito
+//
+// La llamada corre en su propio contexto (pila y memoria nuevas) y con su
+// propio gas: como mucho 63/64 del que queda, para que quien llama siempre
+// pueda terminar. Si falla o revierte se deshace solo lo que hizo ella y
+// quien llama recibe un 0 en la pila; el error no se propaga.
+func (interp *EVMInterpreter) opCall(ctx *ExecutionContext) error {
+	return interp.doCall(ctx, callNormal, CALL)
+}
+
+// opDelegateCall: gas, dirección, argsOffset, argsSize, retOffset, retSize -> éxito
+//
+// Ejecuta el código de la dirección como si fuera propio: con el storage, la
+// dirección, el llamante y el valor de quien llama (así funcionan los proxies)
+func (interp *EVMInterpreter) opDelegateCall(ctx *ExecutionContext) error {
+	return interp.doCall(ctx, callDelegate, DELEGATECALL)
+}
+
+// opStaticCall: gas, dirección, argsOffset, argsSize, retOffset, retSize -> éxito
+//
+// Como CALL sin valor, pero la llamada (y las que haga) no puede cambiar el
+// estado: SSTORE o enviar fondos la hacen fallar
+func (interp *EVMInterpreter) opStaticCall(ctx *ExecutionContext) error {
+	return interp.doCall(ctx, callStatic, STATICCALL)
+}
+
+// doCall saca los operandos de la pila, hace la llamada y deja el resultado
+func (interp *EVMInterpreter) doCall(ctx *ExecutionContext, kind callKind, op OpCode) error {
+	operands := 6
+	if kind == callNormal {
+		operands = 7 // Solo CALL lleva valor
+	}
+	if ctx.Stack.Len() < operands {
+		return fmt.Errorf("stack underflow")
+	}
+
+	requestedGas, _ := ctx.Stack.Pop()
+	addressWord, _ := ctx.Stack.Pop()
+	value := new(big.Int)
+	if kind == callNormal {
+		value, _ = ctx.Stack.Pop()
+	}
+	argsOffset, _ := ctx.Stack.Pop()
+	argsSize, _ := ctx.Stack.Pop()
+	retOffset, _ := ctx.Stack.Pop()
+	retSize, _ := ctx.Stack.Pop()
+
+	if ctx.Host == nil {
+		return fmt.Errorf("%s no disponible: la ejecución no tiene acceso al estado", op.String())
+	}
+	if ctx.ReadOnly && value.Sign() > 0 {
+		return fmt.Errorf("no se pueden enviar fondos dentro de un STATICCALL")
+	}
+
+	// La memoria se paga antes de calcular el gas que recibe la llamada
+	argsStart, argsLength, err := interp.useMemory(ctx, argsOffset, argsSize)
+	if err != nil {
+		return err
+	}
+	retStart, retLength, err := interp.useMemory(ctx, retOffset, retSize)
+	if err != nil {
+		return err
+	}
+
+	if value.Sign() > 0 {
+		if err := interp.useGas(ctx, callValueGas); err != nil {
+			return err
+		}
+	}
+
+	// EIP-150: nunca más de 63/64 de lo que queda
+	callGas := ctx.Gas - ctx.Gas/64
+	if requestedGas.IsUint64() && requestedGas.Uint64() < callGas {
+		callGas = requestedGas.Uint64()
+	}
+	ctx.Gas -= callGas
+	if value.Sign() > 0 {
+		callGas += callStipend
+	}
+
+	to := wordToAddress(addressWord)
+	returnData, gasLeft, ok := interp.call(ctx, kind, to, value, readMemory(ctx, argsStart, argsLength), callGas)
+	ctx.Gas += gasLeft
+
+	// Se copia lo que quepa en el hueco reservado por quien llama
+	if retLength > 0 && len(returnData) > 0 {
+		ctx.Memory.Store(retStart, returnData[:min(retLength, len(returnData))])
+	}
+
+	success := big.NewInt(0)
+	if ok {
+		success.SetInt64(1)
+	}
+	ctx.Stack.Push(success)
+
+	if ctx.Verbose {
+		fmt.Printf("→ %s: %s (valor %s, gas %d) = %d\n", op.String(), to, value.String(), callGas, success.Int64())
+	}
+
+	return nil
+}
+
+// call ejecuta una llamada anidada y devuelve sus datos, el gas que sobra y
+// si terminó bien
+func (interp *EVMInterpreter) call(ctx *ExecutionContext, kind callKind, to string, value *big.Int, input []byte, gas uint64) ([]byte, uint64, bool) {
+	if ctx.Depth+1 >= MaxCallDepth {
+		return nil, gas, false
+	}
+	if value.Sign() > 0 && ctx.Host.Balance(ctx.Contract.Address).Cmp(value) < 0 {
+		return nil, gas, false // Sin fondos la llamada ni empieza
+	}
+
+	snapshot := ctx.Host.Snapshot()
+	if value.Sign() > 0 {
+		if err := ctx.Host.Transfer(ctx.Contract.Address, to, value); err != nil {
+			ctx.Host.RevertToSnapshot(snapshot)
+			return nil, gas, false
+		}
+	}
+
+	// Una cuenta sin código solo recibe los fondos
+	callee := ctx.Host.Contract(to)
+	if callee == nil || len(callee.Bytecode) == 0 {
+		return nil, gas, true
+	}
+
+	var sub *ExecutionContext
+	switch kind {
+	case callDelegate:
+		// Mismo contrato, llamante y valor; solo cambia el código
+		msg := &Message{Caller: ctx.Caller, Origin: ctx.Origin, Value: ctx.Value, Data: input, Gas: gas, GasPrice: ctx.GasPrice}
+		sub = newContext(ctx.Contract, msg, ctx.Host, ctx.Block, ctx.Depth+1)
+		sub.Code = callee.Bytecode
+		sub.jumpDests = callee.jumpDests()
+	default:
+		msg := &Message{Caller: ctx.Contract.Address, Origin: ctx.Origin, Value: value, Data: input, Gas: gas, GasPrice: ctx.GasPrice}
+		sub = newContext(callee, msg, ctx.Host, ctx.Block, ctx.Depth+1)
+	}
+	sub.Verbose = ctx.Verbose
+	sub.ReadOnly = ctx.ReadOnly || kind == callStatic
+
+	err := interp.Run(sub)
+	switch {
+	case err == nil:
+		return sub.ReturnData, sub.Gas, true
+	case errors.Is(err, ErrExecutionReverted):
+		ctx.Host.RevertToSnapshot(snapshot)
+		return sub.ReturnData, sub.Gas, false // Un REVERT devuelve el gas que no usó
+	default:
+		ctx.Host.RevertToSnapshot(snapshot)
+		if ctx.Verbose {
+			fmt.Printf("   ❌ La llamada a %s falló: %v\n", to, err)
+		}
+		return nil, 0, false
+	}
+}
+
+// wordToAddress convierte una palabra de la pila en una dirección
+// Las direcciones son de 20 bytes: se usan los 20 bytes bajos de la palabra
+func wordToAddress(word *big.Int) string {
+	return fmt.Sprintf("%040x", new(big.Int).And(word, addressMask))
+}
+
+// addressMask deja los 160 bits bajos de una palabra
+var addressMask = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 160), big.NewInt(1))
+
+// addressToWord convierte una dirección en una palabra de la pila (0 si está vacía)
+func addressToWord(address string) *big.Int {
+	word, ok := new(big.Int).SetString(address, 16)
+	if !ok {
+		return new(big.Int)
+	}
+	return word
+}
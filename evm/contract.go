@@ -2,6 +2,7 @@ package evm
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/big"
 	"minichain/utils"
@@ -13,7 +14,9 @@ type Contract struct {
 	Owner    string   // Dirección del creador
 	Bytecode []byte   // Código del contrato
 	Storage  *Storage // Estado persistente del contrato
-	Balance  float64  // Saldo del contrato (puede recibir fondos)
+	Balance  *big.Int // Saldo del contrato en unidades base (puede recibir fondos)
+
+	jumpBitmap jumpBitmap // Destinos de salto válidos (ver jumpDests)
 }
 
 // NewContract crea un nuevo contrato
@@ -27,55 +30,40 @@ func NewContract(owner string, bytecode []byte) *Contract {
 		Owner:    owner,
 		Bytecode: bytecode,
 		Storage:  NewStorage(),
-		Balance:  0,
+		Balance:  new(big.Int),
 	}
 }
 
 // Execute ejecuta el bytecode del contrato usando el intérprete global
-func (c *Contract) Execute(gas uint64) (uint64, error) {
-	// Crear contexto de ejecución
-	ctx := &ExecutionContext{
-		Stack:    NewStack(),
-		Memory:   NewMemory(),
-		Storage:  c.Storage,  // Referencia al storage del contrato
-		Code:     c.Bytecode,
-		PC:       0,
-		Gas:      gas,
-		Stopped:  false,
-		Verbose:  true,
-		Contract: c,
-	}
-	
-	// Ejecutar con el intérprete global
-	if err := GlobalInterpreter.Run(ctx); err != nil {
-		return 0, err
-	}
-	
-	// Devolver gas restante
-	return ctx.Gas, nil
+// block puede ser nil si se ejecuta fuera de un bloque. Devuelve los datos de
+// RETURN (nil si terminó sin RETURN) y el gas restante.
+func (c *Contract) Execute(gas uint64, block *BlockContext) ([]byte, uint64, error) {
+	return c.Call(nil, gas, block)
 }
 
-// Call simula llamar a una función del contrato con datos
-func (c *Contract) Call(calldata []byte, gas uint64) (uint64, error) {
-	// Crear contexto de ejecución
-	ctx := &ExecutionContext{
-		Stack:    NewStack(),
-		Memory:   NewMemory(),
-		Storage:  c.Storage,
-		Code:     c.Bytecode,
-		PC:       0,
-		Gas:      gas,
-		Stopped:  false,
-		Verbose:  true,
-		Contract: c,
-	}
-	
+// Call ejecuta el contrato con calldata como argumentos (ver CALLDATALOAD)
+// Devuelve lo mismo que Execute. Si el contrato hace REVERT, el error es
+// ErrExecutionReverted y se devuelven sus datos y el gas que no llegó a usar.
+//
+// Sin Host el contrato no puede llamar a otros (ver CallWith).
+func (c *Contract) Call(calldata []byte, gas uint64, block *BlockContext) ([]byte, uint64, error) {
+	return c.CallWith(&Message{Data: calldata, Gas: gas}, nil, block)
+}
+
+// CallWith ejecuta el contrato para msg con acceso al estado de la cadena
+// Devuelve lo mismo que Call
+func (c *Contract) CallWith(msg *Message, host Host, block *BlockContext) ([]byte, uint64, error) {
+	ctx := newContext(c, msg, host, block, 0)
+
 	// Ejecutar con el intérprete global
 	if err := GlobalInterpreter.Run(ctx); err != nil {
-		return 0, err
+		if errors.Is(err, ErrExecutionReverted) {
+			return ctx.ReturnData, ctx.Gas, err
+		}
+		return nil, 0, err
 	}
-	
-	return ctx.Gas, nil
+
+	return ctx.ReturnData, ctx.Gas, nil
 }
 
 // GetStorageValue obtiene un valor del storage del contrato
@@ -90,7 +78,7 @@ func (c *Contract) Print() {
 	fmt.Println("╚════════════════════════════════════════╝")
 	fmt.Printf("📍 Address:  %s\n", c.Address)
 	fmt.Printf("👤 Owner:    %s\n", c.Owner[:16]+"...")
-	fmt.Printf("💰 Balance:  %.2f MTC\n", c.Balance)
+	fmt.Printf("💰 Balance:  %s MTC\n", utils.FormatMTC(c.Balance))
 	fmt.Printf("📝 Bytecode: %d bytes (%s...)\n", len(c.Bytecode), hex.EncodeToString(c.Bytecode[:min(8, len(c.Bytecode))]))
 	fmt.Printf("💾 Storage:  %d keys\n", len(c.Storage.Data))
 
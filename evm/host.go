@@ -0,0 +1,67 @@
+package evm
+
+import (
+	"math/big"
+)
+
+// Host es lo que la EVM necesita de la cadena para que un contrato llame a
+// otras cuentas (ver CALL)
+//
+// La EVM no conoce el estado de las cuentas: lo pone quien ejecuta (la
+// blockchain). Sin Host, un contrato solo puede tocar su propio storage.
+type Host interface {
+	Contract(address string) *Contract // nil si la dirección no tiene código
+	Balance(address string) *big.Int
+	Transfer(from, to string, amount *big.Int) error
+
+	// AddLog guarda un evento emitido con LOG0..LOG4
+	AddLog(log *Log)
+
+	// Snapshot guarda el estado y RevertToSnapshot vuelve a él, deshaciendo
+	// también los snapshots posteriores (y descartando los logs emitidos después)
+	Snapshot() int
+	RevertToSnapshot(id int)
+}
+
+// Message es una llamada a un contrato: quién llama, con qué y cuánto gas
+type Message struct {
+	Caller string   // Cuenta o contrato que llama
+	Origin string   // Cuenta que firmó la transacción (la misma en todas las llamadas anidadas)
+	Value  *big.Int // Unidades base que se envían con la llamada (nil = 0)
+	Data   []byte   // Calldata
+	Gas    uint64
+
+	GasPrice *big.Int // Precio efectivo del gas de la transacción (nil = 0)
+}
+
+// MaxCallDepth es el máximo de llamadas anidadas (como en Ethereum)
+const MaxCallDepth = 1024
+
+// newContext crea el contexto para ejecutar c con msg
+func newContext(c *Contract, msg *Message, host Host, block *BlockContext, depth int) *ExecutionContext {
+	value := msg.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+	gasPrice := msg.GasPrice
+	if gasPrice == nil {
+		gasPrice = new(big.Int)
+	}
+	return &ExecutionContext{
+		Stack:    NewStack(),
+		Memory:   NewMemory(),
+		Storage:  c.Storage,
+		Code:     c.Bytecode,
+		Gas:      msg.Gas,
+		Verbose:  true,
+		Contract: c,
+		Block:    block,
+		CallData: msg.Data,
+		Host:     host,
+		Caller:   msg.Caller,
+		Origin:   msg.Origin,
+		Value:    value,
+		GasPrice: gasPrice,
+		Depth:    depth,
+	}
+}
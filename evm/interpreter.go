@@ -3,6 +3,7 @@ package evm
 import (
 	"fmt"
 	"math/big"
+	"minichain/utils"
 )
 
 // ExecutionContext representa el contexto de ejecución de un contrato
@@ -15,9 +16,45 @@ type ExecutionContext struct {
 	Gas      uint64
 	Stopped  bool
 	Verbose  bool
-	Contract *Contract // Referencia al contrato
+	Contract *Contract     // Referencia al contrato
+	Block    *BlockContext // Información del bloque en el que se ejecuta
+	CallData []byte        // Argumentos de la llamada (los datos de la transacción)
+	Host     Host          // Estado de la cadena para CALL (nil = sin acceso)
+	Caller   string        // Quién hizo esta llamada
+	Origin   string        // Quién firmó la transacción
+	Value    *big.Int      // Unidades base recibidas con la llamada
+	GasPrice *big.Int      // Precio efectivo del gas de la transacción
+	Depth    int           // Llamadas anidadas por encima de esta (0 = la transacción)
+	ReadOnly bool          // Dentro de un STATICCALL: no se puede cambiar el estado
+
+	ReturnData []byte // Resultado de RETURN o motivo de REVERT (nil si no hubo)
+	Reverted   bool   // Terminó con REVERT
+
+	jumpDests jumpBitmap // Destinos de salto válidos en Code
 }
 
+// BlockContext contiene los datos del bloque visibles para el contrato
+type BlockContext struct {
+	// Random es un valor pseudoaleatorio derivado de los hashes de bloques recientes.
+	// Es una fuente DÉBIL: quien mina puede descartar bloques que no le convengan,
+	// pero es mejor que usar el timestamp como semilla. nil antes del fork de
+	// PREVRANDAO: entonces el opcode 0x44 es DIFFICULTY.
+	Random *big.Int
+
+	Number     int             // Altura del bloque
+	Timestamp  int64           // Fecha del bloque (segundos Unix)
+	Coinbase   string          // Quién cobra las comisiones del bloque ("" = nadie)
+	Difficulty *big.Int        // Dificultad de minado (0 en Proof of Stake)
+	Disabled   map[OpCode]bool // Opcodes que todavía no están activos a esta altura
+
+	// GetHash devuelve el hash del bloque number; solo se consulta para los
+	// 256 anteriores a Number
+	GetHash func(number int) string
+}
+
+// blockHashWindow es cuántos bloques anteriores ve BLOCKHASH
+const blockHashWindow = 256
+
 // EVMInterpreter es el intérprete singleton de la EVM
 type EVMInterpreter struct {
 	GasTable map[OpCode]uint64
@@ -44,6 +81,14 @@ func (interp *EVMInterpreter) Run(ctx *ExecutionContext) error {
 		fmt.Printf("⛽ Gas disponible: %d\n", ctx.Gas)
 	}
 
+	if ctx.jumpDests == nil {
+		if ctx.Contract != nil {
+			ctx.jumpDests = ctx.Contract.jumpDests()
+		} else {
+			ctx.jumpDests = analyzeJumpDests(ctx.Code)
+		}
+	}
+
 	stepCount := 0
 
 	for ctx.PC < len(ctx.Code) && !ctx.Stopped {
@@ -62,6 +107,11 @@ func (interp *EVMInterpreter) Run(ctx *ExecutionContext) error {
 				ctx.PC, op.String(), byte(op), ctx.Gas)
 		}
 
+		// Un opcode de un fork futuro se comporta como si no existiera
+		if ctx.Block != nil && ctx.Block.Disabled[op] {
+			return fmt.Errorf("opcode %s no activo en el bloque %d (PC=%d)", op.String(), ctx.Block.Number, ctx.PC)
+		}
+
 		// Verificar gas
 		gasCost := interp.GetGasCost(op)
 		if ctx.Gas < gasCost {
@@ -74,12 +124,19 @@ func (interp *EVMInterpreter) Run(ctx *ExecutionContext) error {
 			return fmt.Errorf("error en PC=%d: %v", ctx.PC, err)
 		}
 
-		// Avanzar PC (si no fue modificado por JUMP)
+		// Avanzar PC (los saltos lo dejan ya en su sitio)
 		if !op.IsJump() {
 			ctx.PC++
 		}
 	}
 
+	if ctx.Reverted {
+		if ctx.Verbose {
+			fmt.Printf("\n↩️  Ejecución revertida (gas restante: %d)\n", ctx.Gas)
+		}
+		return ErrExecutionReverted
+	}
+
 	if ctx.Verbose {
 		fmt.Printf("\n✅ Ejecución completada\n")
 		fmt.Printf("⛽ Gas restante: %d\n", ctx.Gas)
@@ -133,6 +190,58 @@ func (interp *EVMInterpreter) ExecuteOpcode(op OpCode, ctx *ExecutionContext) er
 		return interp.opDup(op, ctx)
 	case SWAP1, SWAP2:
 		return interp.opSwap(op, ctx)
+	case PREVRANDAO:
+		return interp.opPrevRandao(ctx)
+	case BLOCKHASH:
+		return interp.opBlockHash(ctx)
+	case COINBASE:
+		return interp.opCoinbase(ctx)
+	case TIMESTAMP, NUMBER:
+		return interp.opBlockNumber(op, ctx)
+	case ADDRESS:
+		return interp.pushAddress(ctx, op, ctx.Contract.Address)
+	case ORIGIN:
+		return interp.pushAddress(ctx, op, ctx.Origin)
+	case CALLER:
+		return interp.pushAddress(ctx, op, ctx.Caller)
+	case CALLVALUE:
+		return interp.opCallValue(ctx)
+	case BALANCE:
+		return interp.opBalance(ctx)
+	case SELFBALANCE:
+		return interp.pushValue(ctx, op, ctx.balance(ctx.Contract.Address))
+	case GASPRICE:
+		return interp.pushValue(ctx, op, ctx.GasPrice)
+	case GAS:
+		return interp.pushValue(ctx, op, new(big.Int).SetUint64(ctx.Gas))
+	case EXTCODESIZE, EXTCODEHASH:
+		return interp.opExtCode(op, ctx)
+	case EXTCODECOPY:
+		return interp.opExtCodeCopy(ctx)
+	case LOG0, LOG1, LOG2, LOG3, LOG4:
+		return interp.opLog(op, ctx)
+	case CALLDATALOAD:
+		return interp.opCallDataLoad(ctx)
+	case CALLDATASIZE:
+		return interp.opCallDataSize(ctx)
+	case CALLDATACOPY:
+		return interp.opCallDataCopy(ctx)
+	case JUMP:
+		return interp.opJump(ctx)
+	case JUMPI:
+		return interp.opJumpi(ctx)
+	case JUMPDEST:
+		return nil // Solo marca el destino
+	case RETURN:
+		return interp.opReturn(ctx)
+	case REVERT:
+		return interp.opRevert(ctx)
+	case CALL:
+		return interp.opCall(ctx)
+	case DELEGATECALL:
+		return interp.opDelegateCall(ctx)
+	case STATICCALL:
+		return interp.opStaticCall(ctx)
 	default:
 		return fmt.Errorf("opcode no implementado: %s (0x%02x)", op.String(), byte(op))
 	}
@@ -329,7 +438,11 @@ func (interp *EVMInterpreter) opMload(ctx *ExecutionContext) error {
 	}
 
 	offset, _ := ctx.Stack.Pop()
-	value, _ := ctx.Memory.Load(int(offset.Int64()), 32)
+	start, _, err := interp.useMemory(ctx, offset, big.NewInt(32))
+	if err != nil {
+		return err
+	}
+	value := readMemory(ctx, start, 32)
 	ctx.Stack.Push(new(big.Int).SetBytes(value))
 
 	if ctx.Verbose {
@@ -347,7 +460,12 @@ func (interp *EVMInterpreter) opMstore(ctx *ExecutionContext) error {
 	offset, _ := ctx.Stack.Pop()
 	value, _ := ctx.Stack.Pop()
 
-	ctx.Memory.Store(int(offset.Int64()), value.Bytes())
+	// Siempre una palabra entera de 32 bytes, como la lee MLOAD
+	start, _, err := interp.useMemory(ctx, offset, big.NewInt(32))
+	if err != nil {
+		return err
+	}
+	ctx.Memory.Store(start, value.FillBytes(make([]byte, 32)))
 
 	if ctx.Verbose {
 		fmt.Printf("→ MSTORE: memory[%d] = %s\n", offset.Int64(), value.String())
@@ -377,6 +495,10 @@ func (interp *EVMInterpreter) opSstore(ctx *ExecutionContext) error {
 		return fmt.Errorf("stack underflow")
 	}
 
+	if ctx.ReadOnly {
+		return fmt.Errorf("SSTORE no permitido dentro de un STATICCALL")
+	}
+
 	key, _ := ctx.Stack.Pop()
 	value, _ := ctx.Stack.Pop()
 
@@ -441,3 +563,432 @@ func (interp *EVMInterpreter) opSwap(op OpCode, ctx *ExecutionContext) error {
 
 	return nil
 }
+
+func (interp *EVMInterpreter) opPrevRandao(ctx *ExecutionContext) error {
+	// Sin contexto de bloque (ejecución directa fuera de un bloque) se usa 0
+	value := big.NewInt(0)
+	name := "PREVRANDAO"
+	if ctx.Block != nil && ctx.Block.Random != nil {
+		value = new(big.Int).Set(ctx.Block.Random)
+	} else if ctx.Block != nil && ctx.Block.Difficulty != nil {
+		value = new(big.Int).Set(ctx.Block.Difficulty)
+		name = "DIFFICULTY"
+	}
+
+	if err := ctx.Stack.Push(value); err != nil {
+		return err
+	}
+
+	if ctx.Verbose {
+		fmt.Printf("→ %s: %s\n", name, value.Text(16))
+	}
+
+	return nil
+}
+
+// opBlockHash: número -> hash del bloque, o 0 si no está entre los 256
+// anteriores al actual
+func (interp *EVMInterpreter) opBlockHash(ctx *ExecutionContext) error {
+	number, err := ctx.Stack.Pop()
+	if err != nil {
+		return err
+	}
+
+	value := new(big.Int)
+	if block := ctx.Block; block != nil && block.GetHash != nil && number.IsInt64() {
+		n := number.Int64()
+		if n < int64(block.Number) && n >= int64(block.Number-blockHashWindow) {
+			value.SetString(block.GetHash(int(n)), 16)
+		}
+	}
+
+	if err := ctx.Stack.Push(value); err != nil {
+		return err
+	}
+
+	if ctx.Verbose {
+		fmt.Printf("→ BLOCKHASH(%s): %x\n", number.String(), value)
+	}
+
+	return nil
+}
+
+func (interp *EVMInterpreter) opCoinbase(ctx *ExecutionContext) error {
+	coinbase := ""
+	if ctx.Block != nil {
+		coinbase = ctx.Block.Coinbase
+	}
+	return interp.pushAddress(ctx, COINBASE, coinbase)
+}
+
+// opBlockNumber apila TIMESTAMP o NUMBER (0 sin contexto de bloque)
+func (interp *EVMInterpreter) opBlockNumber(op OpCode, ctx *ExecutionContext) error {
+	value := new(big.Int)
+	if ctx.Block != nil {
+		if op == TIMESTAMP {
+			value.SetInt64(ctx.Block.Timestamp)
+		} else {
+			value.SetInt64(int64(ctx.Block.Number))
+		}
+	}
+
+	if err := ctx.Stack.Push(value); err != nil {
+		return err
+	}
+
+	if ctx.Verbose {
+		fmt.Printf("→ %s: %s\n", op.String(), value.String())
+	}
+
+	return nil
+}
+
+// jumpTo mueve el PC a dest si es un JUMPDEST
+func (interp *EVMInterpreter) jumpTo(ctx *ExecutionContext, dest *big.Int) error {
+	if !dest.IsUint64() || !ctx.jumpDests.isJumpDest(dest.Uint64()) {
+		return fmt.Errorf("salto inválido a %s: no es un JUMPDEST", dest.String())
+	}
+	ctx.PC = int(dest.Uint64())
+	return nil
+}
+
+func (interp *EVMInterpreter) opJump(ctx *ExecutionContext) error {
+	if ctx.Stack.Len() < 1 {
+		return fmt.Errorf("stack underflow")
+	}
+
+	dest, _ := ctx.Stack.Pop()
+	if err := interp.jumpTo(ctx, dest); err != nil {
+		return err
+	}
+
+	if ctx.Verbose {
+		fmt.Printf("→ JUMP: a %d\n", ctx.PC)
+	}
+
+	return nil
+}
+
+func (interp *EVMInterpreter) opJumpi(ctx *ExecutionContext) error {
+	if ctx.Stack.Len() < 2 {
+		return fmt.Errorf("stack underflow")
+	}
+
+	dest, _ := ctx.Stack.Pop()
+	cond, _ := ctx.Stack.Pop()
+
+	if cond.Sign() == 0 {
+		ctx.PC++ // No salta: sigue con la siguiente instrucción
+		if ctx.Verbose {
+			fmt.Println("→ JUMPI: condición 0, no salta")
+		}
+		return nil
+	}
+
+	if err := interp.jumpTo(ctx, dest); err != nil {
+		return err
+	}
+
+	if ctx.Verbose {
+		fmt.Printf("→ JUMPI: a %d\n", ctx.PC)
+	}
+
+	return nil
+}
+
+// maxMemorySize limita la memoria de una ejecución, aunque se pague su gas
+const maxMemorySize = 1 << 20
+
+// Gas de la memoria (como Ethereum): 3 por palabra más palabras²/512, así que
+// ampliarla sale barato hasta unos KB y después cada vez más caro
+const (
+	memoryWordGas     = 3
+	memoryQuadDivisor = 512
+)
+
+// copyGas es el gas por palabra de 32 bytes que copian los *COPY
+const copyGas = 3
+
+// memoryRange convierte offset y tamaño de la pila en un rango de memoria válido
+func memoryRange(offset, size *big.Int) (int, int, error) {
+	if size.Sign() == 0 {
+		return 0, 0, nil // No toca la memoria: el offset da igual
+	}
+	// Sin sumar: offset+size podría desbordar uint64 y colarse por debajo del máximo
+	if !offset.IsUint64() || !size.IsUint64() ||
+		offset.Uint64() > maxMemorySize || size.Uint64() > maxMemorySize-offset.Uint64() {
+		return 0, 0, fmt.Errorf("memoria fuera de rango: %s+%s (máximo %d bytes)", offset, size, maxMemorySize)
+	}
+	return int(offset.Uint64()), int(size.Uint64()), nil
+}
+
+// memoryGas es lo que cuesta tener words palabras de memoria
+func memoryGas(words uint64) uint64 {
+	return memoryWordGas*words + words*words/memoryQuadDivisor
+}
+
+// useMemory es memoryRange para un rango que se va a leer o escribir: cobra la
+// expansión de la memoria hasta cubrirlo (solo la diferencia con lo que ya
+// estaba pagado) y la amplía
+func (interp *EVMInterpreter) useMemory(ctx *ExecutionContext, offset, size *big.Int) (int, int, error) {
+	start, length, err := memoryRange(offset, size)
+	if err != nil || length == 0 {
+		return start, length, err
+	}
+
+	words := uint64(start+length+31) / 32
+	current := uint64(ctx.Memory.Size()+31) / 32
+	if words > current {
+		if err := interp.useGas(ctx, memoryGas(words)-memoryGas(current)); err != nil {
+			return 0, 0, err
+		}
+		ctx.Memory.Resize(int(words * 32))
+	}
+	return start, length, nil
+}
+
+// useGas descuenta gas dinámico (el que depende de los operandos)
+func (interp *EVMInterpreter) useGas(ctx *ExecutionContext, gas uint64) error {
+	if ctx.Gas < gas {
+		return fmt.Errorf("out of gas: necesita %d, tiene %d", gas, ctx.Gas)
+	}
+	ctx.Gas -= gas
+	return nil
+}
+
+// sliceData devuelve size bytes de data desde offset, rellenando con ceros lo
+// que quede fuera (leer más allá del final no es un error en la EVM)
+func sliceData(data []byte, offset *big.Int, size int) []byte {
+	result := make([]byte, size)
+	if offset.IsUint64() && offset.Uint64() < uint64(len(data)) {
+		copy(result, data[offset.Uint64():])
+	}
+	return result
+}
+
+func (interp *EVMInterpreter) opCallDataLoad(ctx *ExecutionContext) error {
+	if ctx.Stack.Len() < 1 {
+		return fmt.Errorf("stack underflow")
+	}
+
+	offset, _ := ctx.Stack.Pop()
+	value := new(big.Int).SetBytes(sliceData(ctx.CallData, offset, 32))
+	ctx.Stack.Push(value)
+
+	if ctx.Verbose {
+		fmt.Printf("→ CALLDATALOAD: calldata[%s] = %s\n", offset.String(), value.Text(16))
+	}
+
+	return nil
+}
+
+func (interp *EVMInterpreter) opCallDataSize(ctx *ExecutionContext) error {
+	size := big.NewInt(int64(len(ctx.CallData)))
+	if err := ctx.Stack.Push(size); err != nil {
+		return err
+	}
+
+	if ctx.Verbose {
+		fmt.Printf("→ CALLDATASIZE: %d bytes\n", len(ctx.CallData))
+	}
+
+	return nil
+}
+
+func (interp *EVMInterpreter) opCallDataCopy(ctx *ExecutionContext) error {
+	if ctx.Stack.Len() < 3 {
+		return fmt.Errorf("stack underflow")
+	}
+
+	memOffset, _ := ctx.Stack.Pop()
+	dataOffset, _ := ctx.Stack.Pop()
+	size, _ := ctx.Stack.Pop()
+
+	offset, length, err := interp.useMemory(ctx, memOffset, size)
+	if err != nil {
+		return err
+	}
+	if err := interp.useGas(ctx, copyGas*uint64((length+31)/32)); err != nil {
+		return err
+	}
+	if length > 0 {
+		ctx.Memory.Store(offset, sliceData(ctx.CallData, dataOffset, length))
+	}
+
+	if ctx.Verbose {
+		fmt.Printf("→ CALLDATACOPY: memory[%d:%d] = calldata[%s:]\n", offset, offset+length, dataOffset.String())
+	}
+
+	return nil
+}
+
+// opExtCode: dirección -> tamaño (EXTCODESIZE) o keccak256 (EXTCODEHASH) de
+// su código; una cuenta sin código da 0 en los dos
+func (interp *EVMInterpreter) opExtCode(op OpCode, ctx *ExecutionContext) error {
+	word, err := ctx.Stack.Pop()
+	if err != nil {
+		return err
+	}
+
+	code, exists := ctx.code(wordToAddress(word))
+	value := new(big.Int)
+	switch {
+	case op == EXTCODESIZE:
+		value.SetInt64(int64(len(code)))
+	case exists:
+		value.SetBytes(utils.Keccak256(code))
+	}
+	return interp.pushValue(ctx, op, value)
+}
+
+// opExtCodeCopy: dirección, destino en memoria, offset en el código, tamaño
+// Lo que pasa del final del código se rellena con ceros (como CALLDATACOPY)
+func (interp *EVMInterpreter) opExtCodeCopy(ctx *ExecutionContext) error {
+	if ctx.Stack.Len() < 4 {
+		return fmt.Errorf("stack underflow")
+	}
+
+	word, _ := ctx.Stack.Pop()
+	memOffset, _ := ctx.Stack.Pop()
+	codeOffset, _ := ctx.Stack.Pop()
+	size, _ := ctx.Stack.Pop()
+
+	offset, length, err := interp.useMemory(ctx, memOffset, size)
+	if err != nil {
+		return err
+	}
+	if err := interp.useGas(ctx, copyGas*uint64((length+31)/32)); err != nil {
+		return err
+	}
+
+	address := wordToAddress(word)
+	code, _ := ctx.code(address)
+	if length > 0 {
+		ctx.Memory.Store(offset, sliceData(code, codeOffset, length))
+	}
+
+	if ctx.Verbose {
+		fmt.Printf("→ EXTCODECOPY: memory[%d:%d] = code(%s)[%s:]\n", offset, offset+length, address, codeOffset.String())
+	}
+
+	return nil
+}
+
+// code devuelve el código de address y si es un contrato (sin Host solo se
+// conoce el propio)
+func (ctx *ExecutionContext) code(address string) ([]byte, bool) {
+	if ctx.Host != nil {
+		if contract := ctx.Host.Contract(address); contract != nil {
+			return contract.Bytecode, true
+		}
+		return nil, false
+	}
+	if ctx.Contract != nil && ctx.Contract.Address == address {
+		return ctx.Contract.Bytecode, true
+	}
+	return nil, false
+}
+
+// readMemory lee size bytes desde offset; lo que aún no se escribió vale 0
+func readMemory(ctx *ExecutionContext, offset, size int) []byte {
+	result := make([]byte, size)
+	if offset < ctx.Memory.Size() {
+		copy(result, ctx.Memory.data[offset:])
+	}
+	return result
+}
+
+// stopWithData termina la ejecución con el trozo de memoria que indica la pila
+// (lo comparten RETURN y REVERT)
+func (interp *EVMInterpreter) stopWithData(ctx *ExecutionContext) error {
+	if ctx.Stack.Len() < 2 {
+		return fmt.Errorf("stack underflow")
+	}
+
+	offset, _ := ctx.Stack.Pop()
+	size, _ := ctx.Stack.Pop()
+
+	start, length, err := interp.useMemory(ctx, offset, size)
+	if err != nil {
+		return err
+	}
+	ctx.ReturnData = readMemory(ctx, start, length)
+	ctx.Stopped = true
+	return nil
+}
+
+func (interp *EVMInterpreter) opReturn(ctx *ExecutionContext) error {
+	if err := interp.stopWithData(ctx); err != nil {
+		return err
+	}
+
+	if ctx.Verbose {
+		fmt.Printf("→ RETURN: %x\n", ctx.ReturnData)
+	}
+
+	return nil
+}
+
+func (interp *EVMInterpreter) opRevert(ctx *ExecutionContext) error {
+	if err := interp.stopWithData(ctx); err != nil {
+		return err
+	}
+	ctx.Reverted = true
+
+	if ctx.Verbose {
+		fmt.Printf("→ REVERT: %s\n", RevertReason(ctx.ReturnData))
+	}
+
+	return nil
+}
+
+// pushAddress apila una dirección del contexto (ADDRESS, ORIGIN, CALLER)
+func (interp *EVMInterpreter) pushAddress(ctx *ExecutionContext, op OpCode, address string) error {
+	if err := ctx.Stack.Push(addressToWord(address)); err != nil {
+		return err
+	}
+
+	if ctx.Verbose {
+		fmt.Printf("→ %s: %s\n", op.String(), address)
+	}
+
+	return nil
+}
+
+func (interp *EVMInterpreter) opCallValue(ctx *ExecutionContext) error {
+	return interp.pushValue(ctx, CALLVALUE, ctx.Value)
+}
+
+// pushValue apila una copia de value (0 si es nil)
+func (interp *EVMInterpreter) pushValue(ctx *ExecutionContext, op OpCode, value *big.Int) error {
+	word := new(big.Int)
+	if value != nil {
+		word.Set(value)
+	}
+	if err := ctx.Stack.Push(word); err != nil {
+		return err
+	}
+
+	if ctx.Verbose {
+		fmt.Printf("→ %s: %s\n", op.String(), word.String())
+	}
+
+	return nil
+}
+
+// opBalance: dirección -> saldo de la cuenta
+func (interp *EVMInterpreter) opBalance(ctx *ExecutionContext) error {
+	word, err := ctx.Stack.Pop()
+	if err != nil {
+		return err
+	}
+	return interp.pushValue(ctx, BALANCE, ctx.balance(wordToAddress(word)))
+}
+
+// balance devuelve el saldo de address (0 sin Host: la EVM no conoce las cuentas)
+func (ctx *ExecutionContext) balance(address string) *big.Int {
+	if ctx.Host == nil {
+		return new(big.Int)
+	}
+	return ctx.Host.Balance(address)
+}
@@ -0,0 +1,39 @@
+package evm
+
+// jumpBitmap marca las posiciones del código que son un JUMPDEST de verdad
+// (un bit por byte de código)
+//
+// No basta con mirar si el byte vale 0x5b: dentro de los datos de un PUSH
+// puede aparecer ese valor sin ser una instrucción, y saltar ahí ejecutaría
+// datos como código.
+type jumpBitmap []byte
+
+// analyzeJumpDests recorre el código saltándose los datos de los PUSH
+func analyzeJumpDests(code []byte) jumpBitmap {
+	bitmap := make(jumpBitmap, (len(code)+7)/8)
+	for pc := 0; pc < len(code); pc++ {
+		op := OpCode(code[pc])
+		if op == JUMPDEST {
+			bitmap[pc/8] |= 1 << (pc % 8)
+		}
+		pc += op.PushSize()
+	}
+	return bitmap
+}
+
+// isJumpDest indica si se puede saltar a dest
+func (b jumpBitmap) isJumpDest(dest uint64) bool {
+	if dest >= uint64(len(b))*8 {
+		return false
+	}
+	return b[dest/8]&(1<<(dest%8)) != 0
+}
+
+// jumpDests devuelve el mapa de destinos del contrato, calculándolo la primera vez
+// El código de un contrato no cambia, así que basta con analizarlo una vez
+func (c *Contract) jumpDests() jumpBitmap {
+	if c.jumpBitmap == nil {
+		c.jumpBitmap = analyzeJumpDests(c.Bytecode)
+	}
+	return c.jumpBitmap
+}
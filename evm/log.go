@@ -0,0 +1,58 @@
+package evm
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// logDataGas es el gas por byte de datos de un evento (los topics van en el
+// gas fijo de cada LOGn)
+const logDataGas = 8
+
+// Log es un evento emitido por un contrato con LOG0..LOG4
+type Log struct {
+	Address string     // Contrato que lo emitió (con DELEGATECALL, el que llama)
+	Topics  []*big.Int // De 0 a 4 palabras para filtrar
+	Data    []byte
+}
+
+// opLog: offset, tamaño y los n topics de LOGn -> emite un evento con ese trozo
+// de memoria
+// El evento se guarda en el Host: si la llamada (o una que la contiene) acaba
+// revertida, el Host lo descarta con el resto de sus cambios.
+func (interp *EVMInterpreter) opLog(op OpCode, ctx *ExecutionContext) error {
+	topicCount := int(op - LOG0)
+	if ctx.Stack.Len() < 2+topicCount {
+		return fmt.Errorf("stack underflow")
+	}
+
+	offset, _ := ctx.Stack.Pop()
+	size, _ := ctx.Stack.Pop()
+	topics := make([]*big.Int, topicCount)
+	for i := range topics {
+		topics[i], _ = ctx.Stack.Pop()
+	}
+
+	if ctx.Host == nil {
+		return fmt.Errorf("%s no disponible: la ejecución no tiene acceso al estado", op.String())
+	}
+	if ctx.ReadOnly {
+		return fmt.Errorf("%s no permitido dentro de un STATICCALL", op.String())
+	}
+
+	start, length, err := interp.useMemory(ctx, offset, size)
+	if err != nil {
+		return err
+	}
+	if err := interp.useGas(ctx, logDataGas*uint64(length)); err != nil {
+		return err
+	}
+
+	ctx.Host.AddLog(&Log{Address: ctx.Contract.Address, Topics: topics, Data: readMemory(ctx, start, length)})
+
+	if ctx.Verbose {
+		fmt.Printf("→ %s: %d topics, %d bytes de datos\n", op.String(), topicCount, length)
+	}
+
+	return nil
+}
@@ -35,6 +35,15 @@ func (m *Memory) Store(offset int, value []byte) error {
 	return nil
 }
 
+// Resize amplía la memoria a size bytes (rellenando con ceros); nunca la reduce
+func (m *Memory) Resize(size int) {
+	if size > len(m.data) {
+		newData := make([]byte, size)
+		copy(newData, m.data)
+		m.data = newData
+	}
+}
+
 // Load carga datos desde una posición de memoria
 func (m *Memory) Load(offset, size int) ([]byte, error) {
 	// Verificar que no se lea fuera de la memoria
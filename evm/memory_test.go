@@ -0,0 +1,101 @@
+package evm
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// overflowOffset es 2^64-1 como PUSH32: cabe en uint64, pero offset+size desborda
+var overflowOffset = append(append([]byte{byte(PUSH32)}, make([]byte, 24)...), bytes.Repeat([]byte{0xff}, 8)...)
+
+// runCode ejecuta code en un contrato nuevo sin contexto de bloque
+func runCode(t *testing.T, code []byte) error {
+	t.Helper()
+	_, _, err := NewContract("tester", code).Execute(1_000_000, nil)
+	return err
+}
+
+// expectMemoryError comprueba que code falla por memoria fuera de rango (sin panic)
+func expectMemoryError(t *testing.T, code []byte) {
+	t.Helper()
+	err := runCode(t, code)
+	if err == nil || !strings.Contains(err.Error(), "memoria fuera de rango") {
+		t.Fatalf("esperaba memoria fuera de rango, obtuve %v", err)
+	}
+}
+
+func TestCallDataCopyOffsetOverflow(t *testing.T) {
+	code := []byte{byte(PUSH1), 1, byte(PUSH1), 0}
+	code = append(code, overflowOffset...)
+	code = append(code, byte(CALLDATACOPY))
+	expectMemoryError(t, code)
+}
+
+// TestMemoryOpsOffsetOverflow: MLOAD, MSTORE, RETURN y REVERT con un offset
+// que desborda al sumarle el tamaño fallan sin panic
+func TestMemoryOpsOffsetOverflow(t *testing.T) {
+	cases := map[string][]byte{
+		"MLOAD":  append(append([]byte{}, overflowOffset...), byte(MLOAD)),
+		"MSTORE": append(append([]byte{byte(PUSH1), 1}, overflowOffset...), byte(MSTORE)),
+		"RETURN": append(append([]byte{byte(PUSH1), 1}, overflowOffset...), byte(RETURN)),
+		"REVERT": append(append([]byte{byte(PUSH1), 1}, overflowOffset...), byte(REVERT)),
+	}
+	for name, code := range cases {
+		t.Run(name, func(t *testing.T) {
+			expectMemoryError(t, code)
+		})
+	}
+}
+
+// TestMemoryOpsInRange: el último byte dentro del máximo sigue siendo válido
+func TestMemoryOpsInRange(t *testing.T) {
+	last := []byte{byte(PUSH3), 0x0f, 0xff, 0xe0} // maxMemorySize - 32
+	code := append([]byte{byte(PUSH1), 7}, last...)
+	code = append(code, byte(MSTORE))
+	code = append(code, last...)
+	code = append(code, byte(MLOAD), byte(PUSH1), 0, byte(MSTORE), byte(PUSH1), 32, byte(PUSH1), 0, byte(RETURN))
+
+	// Casi 1 MB de memoria: unos 2,2 millones de gas de expansión
+	data, _, err := NewContract("tester", code).Execute(3_000_000, nil)
+	if err != nil {
+		t.Fatalf("ejecución: %v", err)
+	}
+	if len(data) != 32 || data[31] != 7 {
+		t.Fatalf("RETURN = %x, esperaba 7", data)
+	}
+}
+
+// mstoreAt devuelve el gas que queda tras un MSTORE en offset (PUSH2, así que
+// el gas fijo no depende del offset)
+func mstoreAt(t *testing.T, offset uint16, gas uint64) (uint64, error) {
+	t.Helper()
+	code := []byte{byte(PUSH1), 7, byte(PUSH2), byte(offset >> 8), byte(offset), byte(MSTORE)}
+	_, gasLeft, err := NewContract("tester", code).Execute(gas, nil)
+	return gasLeft, err
+}
+
+// TestMemoryExpansionGas: ampliar la memoria cuesta 3 por palabra más palabras²/512
+func TestMemoryExpansionGas(t *testing.T) {
+	small, err := mstoreAt(t, 0, 1_000_000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	large, err := mstoreAt(t, 1023*32, 1_000_000) // Hasta 1024 palabras
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := memoryGas(1024) - memoryGas(1)
+	if got := small - large; got != want {
+		t.Fatalf("expandir a 1024 palabras costó %d de más, esperaba %d", got, want)
+	}
+}
+
+// TestMemoryExpansionOutOfGas: una expansión grande sin gas para pagarla falla
+// en lugar de reservar la memoria
+func TestMemoryExpansionOutOfGas(t *testing.T) {
+	_, err := mstoreAt(t, 0xffe0, 10_000)
+	if err == nil || !strings.Contains(err.Error(), "out of gas") {
+		t.Fatalf("esperaba out of gas, obtuve %v", err)
+	}
+}
@@ -19,15 +19,43 @@ const (
 	GT OpCode = 0x11 // Mayor que: a > b
 	EQ OpCode = 0x14 // Igual: a == b
 
+	// 0x30 range - Información de la llamada
+	ADDRESS      OpCode = 0x30 // Dirección del contrato que se ejecuta
+	BALANCE      OpCode = 0x31 // Saldo de una cuenta
+	ORIGIN       OpCode = 0x32 // Cuenta que firmó la transacción
+	CALLER       OpCode = 0x33 // Quién hizo esta llamada
+	CALLVALUE    OpCode = 0x34 // Fondos recibidos con la llamada
+	CALLDATALOAD OpCode = 0x35 // Cargar 32 bytes de los datos de la llamada
+	CALLDATASIZE OpCode = 0x36 // Tamaño de los datos de la llamada
+	CALLDATACOPY OpCode = 0x37 // Copiar datos de la llamada a memoria
+	GASPRICE     OpCode = 0x3a // Precio efectivo del gas de la transacción
+	EXTCODESIZE  OpCode = 0x3b // Tamaño del código de otra cuenta
+	EXTCODECOPY  OpCode = 0x3c // Copiar el código de otra cuenta a memoria
+	EXTCODEHASH  OpCode = 0x3f // Hash del código de otra cuenta
+
+	// 0x40 range - Información del bloque
+	BLOCKHASH   OpCode = 0x40 // Hash de uno de los 256 bloques anteriores
+	COINBASE    OpCode = 0x41 // Quién cobra las comisiones del bloque
+	TIMESTAMP   OpCode = 0x42 // Fecha del bloque (segundos Unix)
+	NUMBER      OpCode = 0x43 // Altura del bloque
+	PREVRANDAO  OpCode = 0x44 // Aleatoriedad derivada de hashes recientes
+	SELFBALANCE OpCode = 0x47 // Saldo del contrato que se ejecuta
+
+	// DIFFICULTY es el mismo opcode que PREVRANDAO: antes del fork de
+	// PREVRANDAO devuelve la dificultad del bloque (como en Ethereum antes de The Merge)
+	DIFFICULTY = PREVRANDAO
+
 	// 0x50 range - Stack, Memory, Storage
-	POP    OpCode = 0x50 // Sacar de la pila
-	MLOAD  OpCode = 0x51 // Cargar de memoria
-	MSTORE OpCode = 0x52 // Guardar en memoria
-	SLOAD  OpCode = 0x54 // Cargar de storage
-	SSTORE OpCode = 0x55 // Guardar en storage
-	JUMP   OpCode = 0x56 // Salto incondicional
-	JUMPI  OpCode = 0x57 // Salto condicional
-	PC     OpCode = 0x58 // Program counter (posición actual)
+	POP      OpCode = 0x50 // Sacar de la pila
+	MLOAD    OpCode = 0x51 // Cargar de memoria
+	MSTORE   OpCode = 0x52 // Guardar en memoria
+	SLOAD    OpCode = 0x54 // Cargar de storage
+	SSTORE   OpCode = 0x55 // Guardar en storage
+	JUMP     OpCode = 0x56 // Salto incondicional
+	JUMPI    OpCode = 0x57 // Salto condicional
+	PC       OpCode = 0x58 // Program counter (posición actual)
+	GAS      OpCode = 0x5a // Gas que queda
+	JUMPDEST OpCode = 0x5b // Marca un destino de salto válido
 
 	// 0x60 range - Push
 	PUSH1  OpCode = 0x60 // Push 1 byte
@@ -45,40 +73,80 @@ const (
 	SWAP1 OpCode = 0x90 // Intercambiar 1er y 2do elemento
 	SWAP2 OpCode = 0x91 // Intercambiar 1er y 3er elemento
 
+	// 0xa0 range - Logs
+	LOG0 OpCode = 0xa0 // Emitir un evento sin topics
+	LOG1 OpCode = 0xa1 // Emitir un evento con 1 topic
+	LOG2 OpCode = 0xa2 // Emitir un evento con 2 topics
+	LOG3 OpCode = 0xa3 // Emitir un evento con 3 topics
+	LOG4 OpCode = 0xa4 // Emitir un evento con 4 topics
+
 	// 0xf0 range - System
-	RETURN OpCode = 0xf3 // Retornar datos
+	CALL         OpCode = 0xf1 // Llamar a otra cuenta o contrato
+	DELEGATECALL OpCode = 0xf4 // Ejecutar el código de otro contrato con el storage propio
+	STATICCALL   OpCode = 0xfa // Llamar sin permitir cambios de estado
+	RETURN       OpCode = 0xf3 // Retornar datos
+	REVERT       OpCode = 0xfd // Abortar deshaciendo los cambios, con un motivo
 )
 
 // opcodeNames mapea opcodes a nombres legibles
 var opcodeNames = map[OpCode]string{
-	STOP:   "STOP",
-	ADD:    "ADD",
-	MUL:    "MUL",
-	SUB:    "SUB",
-	DIV:    "DIV",
-	MOD:    "MOD",
-	LT:     "LT",
-	GT:     "GT",
-	EQ:     "EQ",
-	POP:    "POP",
-	MLOAD:  "MLOAD",
-	MSTORE: "MSTORE",
-	SLOAD:  "SLOAD",
-	SSTORE: "SSTORE",
-	JUMP:   "JUMP",
-	JUMPI:  "JUMPI",
-	PC:     "PC",
-	PUSH1:  "PUSH1",
-	PUSH2:  "PUSH2",
-	PUSH3:  "PUSH3",
-	PUSH4:  "PUSH4",
-	PUSH5:  "PUSH5",
-	PUSH32: "PUSH32",
-	DUP1:   "DUP1",
-	DUP2:   "DUP2",
-	SWAP1:  "SWAP1",
-	SWAP2:  "SWAP2",
-	RETURN: "RETURN",
+	STOP:         "STOP",
+	ADD:          "ADD",
+	MUL:          "MUL",
+	SUB:          "SUB",
+	DIV:          "DIV",
+	MOD:          "MOD",
+	LT:           "LT",
+	GT:           "GT",
+	EQ:           "EQ",
+	ADDRESS:      "ADDRESS",
+	BALANCE:      "BALANCE",
+	ORIGIN:       "ORIGIN",
+	CALLER:       "CALLER",
+	CALLVALUE:    "CALLVALUE",
+	CALLDATALOAD: "CALLDATALOAD",
+	CALLDATASIZE: "CALLDATASIZE",
+	CALLDATACOPY: "CALLDATACOPY",
+	GASPRICE:     "GASPRICE",
+	EXTCODESIZE:  "EXTCODESIZE",
+	EXTCODECOPY:  "EXTCODECOPY",
+	EXTCODEHASH:  "EXTCODEHASH",
+	BLOCKHASH:    "BLOCKHASH",
+	COINBASE:     "COINBASE",
+	TIMESTAMP:    "TIMESTAMP",
+	NUMBER:       "NUMBER",
+	PREVRANDAO:   "PREVRANDAO",
+	SELFBALANCE:  "SELFBALANCE",
+	POP:          "POP",
+	MLOAD:        "MLOAD",
+	MSTORE:       "MSTORE",
+	SLOAD:        "SLOAD",
+	SSTORE:       "SSTORE",
+	JUMP:         "JUMP",
+	JUMPI:        "JUMPI",
+	PC:           "PC",
+	GAS:          "GAS",
+	JUMPDEST:     "JUMPDEST",
+	PUSH1:        "PUSH1",
+	PUSH2:        "PUSH2",
+	PUSH3:        "PUSH3",
+	PUSH4:        "PUSH4",
+	PUSH5:        "PUSH5",
+	PUSH32:       "PUSH32",
+	DUP1:         "DUP1",
+	DUP2:         "DUP2",
+	SWAP1:        "SWAP1",
+	SWAP2:        "SWAP2",
+	LOG0:         "LOG0",
+	LOG1:         "LOG1",
+	LOG2:         "LOG2",
+	LOG3:         "LOG3",
+	LOG4:         "LOG4",
+	CALL:         "CALL",
+	DELEGATECALL: "DELEGATECALL",
+	STATICCALL:   "STATICCALL",
+	RETURN:       "RETURN",
+	REVERT:       "REVERT",
 }
 
 // String devuelve el nombre del opcode
@@ -109,34 +177,63 @@ func (op OpCode) IsJump() bool {
 
 // gasCosts define el costo en gas de cada operación
 var gasCosts = map[OpCode]uint64{
-	STOP:   0,
-	ADD:    3,
-	MUL:    5,
-	SUB:    3,
-	DIV:    5,
-	MOD:    5,
-	LT:     3,
-	GT:     3,
-	EQ:     3,
-	POP:    2,
-	MLOAD:  3,
-	MSTORE: 3,
-	SLOAD:  200,   // Leer storage es caro
-	SSTORE: 20000, // Escribir storage es MUY caro
-	JUMP:   8,
-	JUMPI:  10,
-	PC:     2,
-	PUSH1:  3,
-	PUSH2:  3,
-	PUSH3:  3,
-	PUSH4:  3,
-	PUSH5:  3,
-	PUSH32: 3,
-	DUP1:   3,
-	DUP2:   3,
-	SWAP1:  3,
-	SWAP2:  3,
-	RETURN: 0,
+	STOP:         0,
+	ADD:          3,
+	MUL:          5,
+	SUB:          3,
+	DIV:          5,
+	MOD:          5,
+	LT:           3,
+	GT:           3,
+	EQ:           3,
+	ADDRESS:      2,
+	BALANCE:      700, // Leer el saldo de otra cuenta
+	ORIGIN:       2,
+	CALLER:       2,
+	CALLVALUE:    2,
+	CALLDATALOAD: 3,
+	CALLDATASIZE: 2,
+	CALLDATACOPY: 3, // Más copyGas por palabra copiada
+	GASPRICE:     2,
+	EXTCODESIZE:  700,
+	EXTCODECOPY:  700, // Más copyGas por palabra copiada
+	EXTCODEHASH:  700,
+	BLOCKHASH:    20,
+	COINBASE:     2,
+	TIMESTAMP:    2,
+	NUMBER:       2,
+	PREVRANDAO:   2,
+	SELFBALANCE:  5,
+	POP:          2,
+	MLOAD:        3,
+	MSTORE:       3,
+	SLOAD:        200,   // Leer storage es caro
+	SSTORE:       20000, // Escribir storage es MUY caro
+	JUMP:         8,
+	JUMPI:        10,
+	PC:           2,
+	GAS:          2,
+	JUMPDEST:     1,
+	PUSH1:        3,
+	PUSH2:        3,
+	PUSH3:        3,
+	PUSH4:        3,
+	PUSH5:        3,
+	PUSH32:       3,
+	DUP1:         3,
+	DUP2:         3,
+	SWAP1:        3,
+	SWAP2:        3,
+	LOG0:         375, // Más 375 por topic y logDataGas por byte de datos
+	LOG1:         750,
+	LOG2:         1125,
+	LOG3:         1500,
+	LOG4:         1875,
+	CALL:         700, // Más callValueGas si envía fondos
+	DELEGATECALL: 700,
+	STATICCALL:   700,
+	RETURN:       0,
+	REVERT:       0,
 }
 
 // GetGasCost devuelve el costo en gas de un opcode
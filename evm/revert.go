@@ -0,0 +1,41 @@
+package evm
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math/big"
+)
+
+// ErrExecutionReverted es el error de una ejecución que terminó en REVERT
+// Los cambios se deshacen pero, a diferencia de un fallo, solo se cobra el gas
+// usado hasta el REVERT
+var ErrExecutionReverted = errors.New("ejecución revertida")
+
+// revertSelector es el selector de Error(string), la codificación que usa
+// Solidity para require(cond, "motivo") y revert("motivo")
+var revertSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+
+// RevertReason interpreta los datos de un REVERT
+//
+// Si son un Error(string) devuelve el texto; si no, los datos en hexadecimal
+// ("" si el REVERT no llevaba datos).
+func RevertReason(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	if len(data) >= 4+64 && bytes.Equal(data[:4], revertSelector) {
+		// Los límites se comparan sin sumar: offset y longitud vienen del
+		// contrato y una suma podría desbordar uint64
+		args := data[4:]
+		offset := new(big.Int).SetBytes(args[:32])
+		if offset.IsUint64() && offset.Uint64() <= uint64(len(args)-32) {
+			start := offset.Uint64() + 32
+			length := new(big.Int).SetBytes(args[start-32 : start])
+			if length.IsUint64() && length.Uint64() <= uint64(len(args))-start {
+				return string(args[start : start+length.Uint64()])
+			}
+		}
+	}
+	return "0x" + hex.EncodeToString(data)
+}
@@ -0,0 +1,41 @@
+package evm
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+// errorData codifica Error(string) con el offset y la longitud indicados
+func errorData(offset, length *big.Int, text string) []byte {
+	data := append([]byte{}, revertSelector...)
+	data = append(data, offset.FillBytes(make([]byte, 32))...)
+	data = append(data, length.FillBytes(make([]byte, 32))...)
+	return append(data, text...)
+}
+
+func TestRevertReason(t *testing.T) {
+	if got := RevertReason(errorData(big.NewInt(32), big.NewInt(4), "nope")); got != "nope" {
+		t.Fatalf("RevertReason = %q, esperaba %q", got, "nope")
+	}
+	if got := RevertReason(nil); got != "" {
+		t.Fatalf("RevertReason(nil) = %q", got)
+	}
+}
+
+// TestRevertReasonOverflow: offsets y longitudes que desbordan uint64 al
+// sumarlos se devuelven en hex en lugar de hacer panic
+func TestRevertReasonOverflow(t *testing.T) {
+	huge := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 64), big.NewInt(16)) // 2^64-16
+	cases := map[string][]byte{
+		"offset":   append(append(append([]byte{}, revertSelector...), huge.FillBytes(make([]byte, 32))...), bytes.Repeat([]byte{0}, 32)...),
+		"longitud": errorData(big.NewInt(32), huge, "nope"),
+	}
+	for name, data := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := RevertReason(data); got[:2] != "0x" {
+				t.Fatalf("RevertReason = %q, esperaba los datos en hex", got)
+			}
+		})
+	}
+}
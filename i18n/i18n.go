@@ -0,0 +1,56 @@
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Language es un idioma soportado por el catálogo de mensajes
+type Language string
+
+const (
+	Spanish Language = "es" // Idioma original del proyecto
+	English Language = "en"
+)
+
+// current es el idioma activo (por defecto español, o MINICHAIN_LANG si existe)
+var current = Spanish
+
+func init() {
+	if lang := os.Getenv("MINICHAIN_LANG"); lang != "" {
+		SetLanguage(lang)
+	}
+}
+
+// SetLanguage cambia el idioma activo; devuelve error si no está soportado
+func SetLanguage(lang string) error {
+	l := Language(strings.ToLower(strings.TrimSpace(lang)))
+	if _, exists := catalogs[l]; !exists {
+		return fmt.Errorf("idioma no soportado: %s (disponibles: es, en)", lang)
+	}
+	current = l
+	return nil
+}
+
+// Current devuelve el idioma activo
+func Current() Language {
+	return current
+}
+
+// T traduce un mensaje del catálogo y aplica los argumentos con formato de fmt
+// Si la clave no existe en el idioma activo se usa el español; si tampoco, la clave
+func T(key string, args ...any) string {
+	format, exists := catalogs[current][key]
+	if !exists {
+		format, exists = catalogs[Spanish][key]
+	}
+	if !exists {
+		format = key
+	}
+
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
@@ -0,0 +1,208 @@
+package i18n
+
+// catalogs contiene todos los mensajes traducibles, por idioma y clave
+var catalogs = map[Language]map[string]string{
+	Spanish: {
+		// Menú principal
+		"menu.title":          "MENÚ PRINCIPAL",
+		"menu.wallet":         "1. Ver cuentas en wallet",
+		"menu.newAccount":     "2. Crear nueva cuenta",
+		"menu.state":          "3. Ver estado de cuentas",
+		"menu.newTx":          "4. Crear transacción",
+		"menu.pending":        "5. Ver transacciones pendientes",
+		"menu.mine":           "6. Minar bloque",
+		"menu.chain":          "7. Ver blockchain completa",
+		"menu.verify":         "8. Verificar integridad",
+		"menu.contracts":      "--- CONTRATOS INTELIGENTES ---",
+		"menu.deploy":         "10. Desplegar contrato (directo)",
+		"menu.listContracts":  "11. Listar contratos",
+		"menu.execute":        "12. Ejecutar contrato (directo)",
+		"menu.contractState":  "13. Ver estado de contrato",
+		"menu.contractTxs":    "--- TRANSACCIONES DE CONTRATOS ---",
+		"menu.deployTx":       "14. TX: Desplegar contrato",
+		"menu.callTx":         "15. TX: Llamar a contrato",
+		"menu.batchTx":        "16. TX: Lote de transferencias off-chain",
+		"menu.code":           "17. Ver código de contrato",
+		"menu.stakeTx":        "18. TX: Bloquear stake (PoS)",
+		"menu.rawTx":          "20. TX: Enviar transacción raw (hex)",
+		"menu.receipt":        "21. Ver recibo de transacción",
+		"menu.history":        "22. Historial de una dirección",
+		"menu.multisig":       "--- MULTIFIRMA ---",
+		"menu.msigNew":        "23. Crear cuenta multifirma",
+		"menu.msigTx":         "24. TX: Gastar de una multifirma",
+		"menu.msigSign":       "25. TX: Añadir firma a multifirma (raw)",
+		"menu.chainSection":   "--- CADENA ---",
+		"menu.export":         "19. Exportar cadena a archivo",
+		"menu.exitSection":    "--- SALIR ---",
+		"menu.exit":           "9. Salir",
+		"menu.prompt":         "👉 Selecciona una opción: ",
+		"menu.invalidOption":  "❌ Opción inválida",
+		"menu.goodbye":        "👋 ¡Gracias por usar MiniChain!",
+		"chain.valid":         "✅ ¡Blockchain válida! Todos los bloques están intactos.",
+		"chain.corrupt":       "❌ ¡Blockchain corrupta! Se detectaron alteraciones.",
+		"chain.verifying":     "🔍 Verificando integridad de la blockchain...",
+		"err.invalidAccount":  "❌ Cuenta inválida",
+		"err.invalidAmount":   "❌ Cantidad inválida",
+		"err.invalidContract": "❌ Contrato inválido",
+		"err.noContracts":     "❌ No hay contratos desplegados",
+		"err.noPending":       "❌ No hay transacciones pendientes para minar",
+		"err.selfTransfer":    "❌ No puedes enviar a ti mismo",
+		"err.noCode":          "❌ No se escribió ningún código",
+		"err.emptyBatch":      "❌ El lote está vacío",
+		"err.generic":         "❌ Error: %v",
+		"cli.usage":           "Uso: minichain <subcomando> [opciones]",
+		"cli.subcommands":     "Subcomandos:",
+		"cli.unknownCommand":  "❌ Subcomando desconocido: %s",
+		"cli.console":         "Consola interactiva con una blockchain en memoria (por defecto)",
+		"cli.compile":         "Compila assembly a bytecode (y opcionalmente lo ejecuta)",
+		"cli.disasm":          "Desensambla bytecode a assembly legible",
+		"cli.bench":           "Mide la importación de bloques y detecta regresiones de rendimiento",
+		"cli.init":            "Crea el génesis de una especificación JSON y muestra su hash",
+		"cli.import":          "Valida y ejecuta una cadena exportada sobre su génesis",
+		"cli.serve":           "Arranca un nodo sin consola con un servidor JSON-RPC (eth_*)",
+		"cli.help":            "Muestra esta ayuda",
+		"cli.lang":            "idioma de los mensajes: es o en",
+
+		// API (JSON-RPC y REST): sin emojis, los lee un programa
+		"rpc.bodyTooLarge":        "cuerpo demasiado grande (máximo %d bytes)",
+		"rpc.invalidJSON":         "JSON inválido: %v",
+		"rpc.invalidBatch":        "lote vacío o inválido",
+		"rpc.batchTooLarge":       "lote demasiado grande: %d peticiones (máximo %d)",
+		"rpc.invalidRequest":      "petición JSON-RPC 2.0 inválida",
+		"rpc.unknownMethod":       "método %s no existe",
+		"rpc.authRequired":        "%s requiere autenticación",
+		"rpc.paramsNotArray":      "los parámetros deben ser un array",
+		"rpc.tooFewParams":        "se esperaban al menos %d parámetros, llegaron %d",
+		"rpc.tooManyParams":       "se esperaban como mucho %d parámetros, llegaron %d",
+		"rpc.invalidParam":        "parámetro %d inválido: %v",
+		"rpc.methodNotAllowed":    "solo se admite %s",
+		"rpc.unknownRoute":        "ruta %s no existe",
+		"rpc.forbiddenOrigin":     "origen no permitido: %s",
+		"rpc.tooManyRequests":     "demasiadas peticiones",
+		"rpc.unknownBlock":        "bloque %s no encontrado",
+		"rpc.unknownTx":           "transacción %s no encontrada",
+		"rpc.blockNumberFormat":   "número de bloque inválido: %q (hex con 0x o etiqueta)",
+		"rpc.invalidBlockNumber":  "número de bloque inválido: %q",
+		"rpc.latestStateOnly":     "solo está disponible el estado del último bloque",
+		"rpc.invalidData":         "data inválido: %v",
+		"rpc.gasFormat":           "gas inválido: %q (hex con 0x)",
+		"rpc.invalidGas":          "gas inválido: %q",
+		"rpc.gasAboveLimit":       "gas %d por encima del límite de la red (%d)",
+		"rpc.blockHashWithRange":  "blockHash no se puede combinar con fromBlock/toBlock",
+		"rpc.invalidAddressList":  "address inválido: %v",
+		"rpc.invalidTopics":       "topics[%d] inválido: %v",
+		"rpc.invalidStorageKey":   "clave de storage inválida: %q",
+		"rpc.nonceFormat":         "nonce inválido: %q (hex con 0x)",
+		"rpc.invalidNonce":        "nonce inválido: %q",
+		"rpc.invalidThreads":      "hilos inválidos: %d (mínimo 1)",
+		"rpc.invalidAddress":      "dirección inválida: %q",
+		"rpc.notSigned":           "transacción no firmada",
+		"rpc.invalidSignature":    "firma inválida",
+		"rpc.nonceTooLow":         "nonce ya usado",
+		"rpc.nonceTooHigh":        "nonce demasiado alto",
+		"rpc.insufficientFunds":   "saldo insuficiente",
+		"rpc.insufficientPending": "saldo insuficiente contando las pendientes",
+		"rpc.alreadyKnown":        "transacción ya conocida",
+	},
+	English: {
+		"menu.title":          "MAIN MENU",
+		"menu.wallet":         "1. List wallet accounts",
+		"menu.newAccount":     "2. Create new account",
+		"menu.state":          "3. Show account state",
+		"menu.newTx":          "4. Create transaction",
+		"menu.pending":        "5. Show pending transactions",
+		"menu.mine":           "6. Mine block",
+		"menu.chain":          "7. Show full blockchain",
+		"menu.verify":         "8. Verify integrity",
+		"menu.contracts":      "--- SMART CONTRACTS ---",
+		"menu.deploy":         "10. Deploy contract (direct)",
+		"menu.listContracts":  "11. List contracts",
+		"menu.execute":        "12. Execute contract (direct)",
+		"menu.contractState":  "13. Show contract state",
+		"menu.contractTxs":    "--- CONTRACT TRANSACTIONS ---",
+		"menu.deployTx":       "14. TX: Deploy contract",
+		"menu.callTx":         "15. TX: Call contract",
+		"menu.batchTx":        "16. TX: Batch of off-chain transfers",
+		"menu.code":           "17. Show contract code",
+		"menu.stakeTx":        "18. TX: Lock stake (PoS)",
+		"menu.rawTx":          "20. TX: Send raw transaction (hex)",
+		"menu.receipt":        "21. Show transaction receipt",
+		"menu.history":        "22. Address history",
+		"menu.multisig":       "--- MULTISIG ---",
+		"menu.msigNew":        "23. Create multisig account",
+		"menu.msigTx":         "24. TX: Spend from a multisig",
+		"menu.msigSign":       "25. TX: Add multisig signature (raw)",
+		"menu.chainSection":   "--- CHAIN ---",
+		"menu.export":         "19. Export chain to file",
+		"menu.exitSection":    "--- EXIT ---",
+		"menu.exit":           "9. Exit",
+		"menu.prompt":         "👉 Select an option: ",
+		"menu.invalidOption":  "❌ Invalid option",
+		"menu.goodbye":        "👋 Thanks for using MiniChain!",
+		"chain.valid":         "✅ Valid blockchain! All blocks are intact.",
+		"chain.corrupt":       "❌ Corrupt blockchain! Tampering was detected.",
+		"chain.verifying":     "🔍 Verifying blockchain integrity...",
+		"err.invalidAccount":  "❌ Invalid account",
+		"err.invalidAmount":   "❌ Invalid amount",
+		"err.invalidContract": "❌ Invalid contract",
+		"err.noContracts":     "❌ No contracts deployed",
+		"err.noPending":       "❌ No pending transactions to mine",
+		"err.selfTransfer":    "❌ You cannot send to yourself",
+		"err.noCode":          "❌ No code was written",
+		"err.emptyBatch":      "❌ The batch is empty",
+		"err.generic":         "❌ Error: %v",
+		"cli.usage":           "Usage: minichain <subcommand> [options]",
+		"cli.subcommands":     "Subcommands:",
+		"cli.unknownCommand":  "❌ Unknown subcommand: %s",
+		"cli.console":         "Interactive console with an in-memory blockchain (default)",
+		"cli.compile":         "Compile assembly to bytecode (and optionally run it)",
+		"cli.disasm":          "Disassemble bytecode into readable assembly",
+		"cli.bench":           "Benchmark block import and catch performance regressions",
+		"cli.init":            "Build the genesis block from a JSON spec and show its hash",
+		"cli.import":          "Validate and replay an exported chain on top of its genesis",
+		"cli.serve":           "Run a headless node with a JSON-RPC server (eth_*)",
+		"cli.help":            "Show this help",
+		"cli.lang":            "message language: es or en",
+
+		// API (JSON-RPC and REST)
+		"rpc.bodyTooLarge":        "request body too large (max %d bytes)",
+		"rpc.invalidJSON":         "invalid JSON: %v",
+		"rpc.invalidBatch":        "empty or invalid batch",
+		"rpc.batchTooLarge":       "batch too large: %d requests (max %d)",
+		"rpc.invalidRequest":      "invalid JSON-RPC 2.0 request",
+		"rpc.unknownMethod":       "method %s does not exist",
+		"rpc.authRequired":        "%s requires authentication",
+		"rpc.paramsNotArray":      "params must be an array",
+		"rpc.tooFewParams":        "expected at least %d params, got %d",
+		"rpc.tooManyParams":       "expected at most %d params, got %d",
+		"rpc.invalidParam":        "invalid param %d: %v",
+		"rpc.methodNotAllowed":    "only %s is allowed",
+		"rpc.unknownRoute":        "route %s does not exist",
+		"rpc.forbiddenOrigin":     "origin not allowed: %s",
+		"rpc.tooManyRequests":     "too many requests",
+		"rpc.unknownBlock":        "block %s not found",
+		"rpc.unknownTx":           "transaction %s not found",
+		"rpc.blockNumberFormat":   "invalid block number: %q (0x-prefixed hex or tag)",
+		"rpc.invalidBlockNumber":  "invalid block number: %q",
+		"rpc.latestStateOnly":     "only the latest block's state is available",
+		"rpc.invalidData":         "invalid data: %v",
+		"rpc.gasFormat":           "invalid gas: %q (0x-prefixed hex)",
+		"rpc.invalidGas":          "invalid gas: %q",
+		"rpc.gasAboveLimit":       "gas %d above the network limit (%d)",
+		"rpc.blockHashWithRange":  "blockHash cannot be combined with fromBlock/toBlock",
+		"rpc.invalidAddressList":  "invalid address: %v",
+		"rpc.invalidTopics":       "invalid topics[%d]: %v",
+		"rpc.invalidStorageKey":   "invalid storage key: %q",
+		"rpc.nonceFormat":         "invalid nonce: %q (0x-prefixed hex)",
+		"rpc.invalidNonce":        "invalid nonce: %q",
+		"rpc.invalidThreads":      "invalid thread count: %d (min 1)",
+		"rpc.invalidAddress":      "invalid address: %q",
+		"rpc.notSigned":           "transaction not signed",
+		"rpc.invalidSignature":    "invalid signature",
+		"rpc.nonceTooLow":         "nonce too low",
+		"rpc.nonceTooHigh":        "nonce too high",
+		"rpc.insufficientFunds":   "insufficient funds",
+		"rpc.insufficientPending": "insufficient funds for pending transactions",
+		"rpc.alreadyKnown":        "already known",
+	},
+}
@@ -0,0 +1,535 @@
+package mempool
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"minichain/utils"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Límites por defecto del pool
+const (
+	DefaultMaxTxs    = 4096
+	DefaultMaxBytes  = 4 * 1024 * 1024
+	DefaultLifetime  = 3 * time.Hour // Tiempo máximo esperando a ser minada
+	DefaultMaxBlocks = 100           // Bloques máximos esperando a ser minada
+)
+
+// maxDropped es cuántas transacciones descartadas se recuerdan
+const maxDropped = 1024
+
+// Motivos por los que una transacción sale del pool sin minarse
+const (
+	DropExpired = "caducada"   // Superó Lifetime o MaxBlocks
+	DropInvalid = "inválida"   // Dejó de ser válida tras un bloque (nonce, saldo...)
+	DropEvicted = "desplazada" // Expulsada por otra que paga más con el pool lleno
+)
+
+// Dropped es una transacción que salió del pool sin minarse
+// Las carteras la consultan para saber que tienen que reenviarla
+type Dropped struct {
+	Hash   string    `json:"hash"`
+	Sender string    `json:"sender"`
+	Nonce  int       `json:"nonce"`
+	Reason string    `json:"reason"`
+	Time   time.Time `json:"time"`
+}
+
+// ErrAlreadyKnown se devuelve al añadir una transacción que ya está pendiente o
+// ya se incluyó en la cadena
+var ErrAlreadyKnown = errors.New("transacción ya conocida")
+
+// ErrInsufficientFunds se devuelve cuando el saldo no cubre la transacción más
+// las pendientes del mismo remitente
+var ErrInsufficientFunds = errors.New("saldo insuficiente contando las pendientes")
+
+// ErrOversized se devuelve cuando la transacción no cabe en el pool ni vacío
+var ErrOversized = errors.New("transacción demasiado grande")
+
+// ErrUnderpriced se devuelve cuando el pool está lleno y la transacción no paga
+// más que las que ya hay (para entrar tendría que desplazar a alguna)
+var ErrUnderpriced = errors.New("mempool lleno: precio del gas demasiado bajo")
+
+// Tx es lo que el pool necesita saber de una transacción
+// El pool no depende del paquete blockchain: cualquier tipo que cumpla esto sirve
+type Tx interface {
+	Hash() string                           // Identificador único
+	Sender() string                         // Dirección del remitente
+	AccountNonce() int                      // Nonce del remitente que consume
+	Cost() *big.Int                         // Máximo que puede gastar (monto + gas)
+	EffectiveTip(baseFee *big.Int) *big.Int // Propina por gas para el minero con ese base fee
+	Size() int                              // Bytes que ocupa codificada
+}
+
+// State da acceso al estado confirmado y a las reglas de validación de la cadena
+type State interface {
+	Nonce(address string) int
+	Balance(address string) *big.Int
+	Height() int       // Bloques en la cadena
+	BaseFee() *big.Int // Base fee del próximo bloque (nil si no hay)
+
+	// Included dice si una transacción con ese hash ya está en la cadena
+	Included(hash string) bool
+
+	// ReservedUntil es el primer nonce sin reservar del remitente (0 si no
+	// tiene reservas): por debajo de él se admiten nonces con huecos
+	ReservedUntil(address string) int
+
+	// Validate comprueba firma y reglas de la cadena esperando el nonce indicado
+	Validate(tx Tx, expectedNonce int) error
+}
+
+// entry es una transacción guardada con los datos de su llegada
+type entry struct {
+	tx     Tx
+	seq    uint64
+	size   int
+	added  time.Time // Cuándo entró
+	height int       // Altura de la cadena cuando entró
+}
+
+// Pool guarda las transacciones pendientes de minar
+//
+// Es seguro usarlo desde varias goroutines. Cada remitente tiene su propia cola
+// ordenada por nonce: una transacción entra si su nonce es el siguiente al
+// último pendiente de ese remitente (o al confirmado, si no hay) o si está por
+// debajo de sus nonces reservados (ver State.ReservedUntil). En ese caso puede
+// quedar un hueco: lo que hay detrás espera (Queued) hasta que se llene.
+type Pool struct {
+	MaxTxs   int // Máximo de transacciones pendientes
+	MaxBytes int // Máximo de bytes entre todas las pendientes
+
+	// Vida máxima de una pendiente (0 = sin límite). Se comprueba en cada Reset
+	// y en cada Expire.
+	Lifetime  time.Duration
+	MaxBlocks int
+
+	mu     sync.Mutex
+	state  State
+	queues map[string][]*entry // remitente -> cola ordenada por nonce
+	byHash map[string]*entry   // hash -> transacción (para detectar duplicados)
+	bytes  int                 // Tamaño total de las pendientes
+	seq    uint64              // Contador de llegada
+
+	dropped      map[string]Dropped // hash -> descartada (las últimas maxDropped)
+	droppedOrder []string           // hashes descartados, del más antiguo al más reciente
+}
+
+// New crea un pool vacío que valida contra state
+func New(state State) *Pool {
+	return &Pool{
+		MaxTxs:    DefaultMaxTxs,
+		MaxBytes:  DefaultMaxBytes,
+		Lifetime:  DefaultLifetime,
+		MaxBlocks: DefaultMaxBlocks,
+		state:     state,
+		queues:    make(map[string][]*entry),
+		byHash:    make(map[string]*entry),
+		dropped:   make(map[string]Dropped),
+	}
+}
+
+// Add valida una transacción y la añade a la cola de su remitente
+//
+// Reglas de admisión: no estar ya en el pool ni en la cadena, el nonce siguiente al último
+// pendiente del remitente (o uno libre de sus reservados), las reglas de la cadena (firma, gas...) y un saldo
+// que cubra el coste máximo (monto + gas) de esta y de las que ya tiene pendientes.
+// Si el pool está lleno, solo entra desplazando a otras que paguen menos.
+func (p *Pool) Add(tx Tx) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.add(tx)
+}
+
+// AddBatch añade varias transacciones de una vez y devuelve un error por cada
+// una (nil si entró), en el mismo orden que txs
+//
+// Se intentan por remitente y nonce, así que el orden en que lleguen da igual:
+// las de un mismo remitente entran seguidas sin esperar a que el cliente las
+// ordene. Nadie más puede añadir transacciones mientras tanto. Cada una se
+// admite con las mismas reglas que Add; si una falla, las siguientes de ese
+// remitente fallarán por el hueco de nonce.
+func (p *Pool) AddBatch(txs []Tx) []error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	order := make([]int, len(txs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		txA, txB := txs[order[a]], txs[order[b]]
+		if txA.Sender() != txB.Sender() {
+			return txA.Sender() < txB.Sender()
+		}
+		return txA.AccountNonce() < txB.AccountNonce()
+	})
+
+	errs := make([]error, len(txs))
+	for _, i := range order {
+		errs[i] = p.add(txs[i])
+	}
+	return errs
+}
+
+// add es Add con el candado ya tomado
+func (p *Pool) add(tx Tx) error {
+	hash := tx.Hash()
+	if _, exists := p.byHash[hash]; exists {
+		return fmt.Errorf("%w: %s ya está en el mempool", ErrAlreadyKnown, hash[:16]+"...")
+	}
+	if p.state.Included(hash) {
+		return fmt.Errorf("%w: %s ya está en la cadena", ErrAlreadyKnown, hash[:16]+"...")
+	}
+
+	sender := tx.Sender()
+	if err := p.state.Validate(tx, p.expectedNonce(sender, tx.AccountNonce())); err != nil {
+		return err
+	}
+
+	available := new(big.Int).Sub(p.state.Balance(sender), p.pendingCost(sender))
+	if tx.Cost().Cmp(available) > 0 {
+		return fmt.Errorf("%w: %s disponibles, %s necesarios",
+			ErrInsufficientFunds, utils.FormatMTC(available), utils.FormatMTC(tx.Cost()))
+	}
+
+	size := tx.Size()
+	victims, err := p.makeRoom(tx, size)
+	if err != nil {
+		return err
+	}
+	for _, victim := range victims {
+		p.removeTail(victim)
+		p.recordDrop(victim.tx, DropEvicted)
+	}
+
+	p.seq++
+	e := &entry{tx: tx, seq: p.seq, size: size, added: time.Now(), height: p.state.Height()}
+	queue := p.queues[sender]
+	i := sort.Search(len(queue), func(i int) bool { return queue[i].tx.AccountNonce() > tx.AccountNonce() })
+	p.queues[sender] = append(queue[:i], append([]*entry{e}, queue[i:]...)...)
+	p.byHash[hash] = e
+	p.bytes += size
+	p.forgetDrop(hash) // Reenviada: ya no cuenta como descartada
+
+	return nil
+}
+
+// makeRoom elige qué transacciones desalojar para que quepa tx
+//
+// Solo se puede desalojar la última de cada remitente (quitar una intermedia
+// dejaría un hueco de nonces). Se elige la que menos propina deja al minero con
+// el base fee actual y, a igual propina, la más antigua. Si ninguna paga menos
+// que tx, tx se rechaza con ErrUnderpriced.
+func (p *Pool) makeRoom(tx Tx, size int) ([]*entry, error) {
+	if size > p.MaxBytes {
+		return nil, fmt.Errorf("%w: %d bytes (máximo %d)", ErrOversized, size, p.MaxBytes)
+	}
+	count, bytes := len(p.byHash)+1, p.bytes+size
+	baseFee := p.state.BaseFee() // Se compara la propina que recibiría el minero
+
+	cut := make(map[string]int) // remitente -> cuántas quedan en su cola
+	var victims []*entry
+
+	for count > p.MaxTxs || bytes > p.MaxBytes {
+		var victim *entry
+		for sender, queue := range p.queues {
+			if sender == tx.Sender() {
+				continue // tx va detrás de su propia cola
+			}
+			n, seen := cut[sender]
+			if !seen {
+				n = len(queue)
+			}
+			if n == 0 {
+				continue
+			}
+			tail := queue[n-1]
+			if victim == nil {
+				victim = tail
+				continue
+			}
+			cmp := tail.tx.EffectiveTip(baseFee).Cmp(victim.tx.EffectiveTip(baseFee))
+			if cmp < 0 || (cmp == 0 && tail.seq < victim.seq) {
+				victim = tail
+			}
+		}
+
+		if victim == nil || victim.tx.EffectiveTip(baseFee).Cmp(tx.EffectiveTip(baseFee)) >= 0 {
+			return nil, fmt.Errorf("%w (%d transacciones, %d bytes)", ErrUnderpriced, len(p.byHash), p.bytes)
+		}
+
+		sender := victim.tx.Sender()
+		if _, seen := cut[sender]; !seen {
+			cut[sender] = len(p.queues[sender])
+		}
+		cut[sender]--
+		victims = append(victims, victim)
+		count--
+		bytes -= victim.size
+	}
+
+	return victims, nil
+}
+
+// expectedNonce es el nonce con el que se valida una transacción que llega con
+// nonce: el suyo si ocupa un hueco (entre las pendientes o reservado), si no el
+// siguiente al último pendiente
+func (p *Pool) expectedNonce(sender string, nonce int) int {
+	next := p.pendingNonce(sender)
+	if nonce < p.state.Nonce(sender) || nonce >= max(next, p.state.ReservedUntil(sender)) {
+		return next
+	}
+	for _, e := range p.queues[sender] {
+		if e.tx.AccountNonce() == nonce {
+			return next // Ese nonce ya está ocupado
+		}
+	}
+	return nonce
+}
+
+// removeTail quita la última transacción de la cola de su remitente
+func (p *Pool) removeTail(e *entry) {
+	sender := e.tx.Sender()
+	queue := p.queues[sender]
+	p.queues[sender] = queue[:len(queue)-1]
+	if len(p.queues[sender]) == 0 {
+		delete(p.queues, sender)
+	}
+
+	delete(p.byHash, e.tx.Hash())
+	p.bytes -= e.size
+}
+
+// PendingNonce devuelve el siguiente nonce libre de un remitente
+func (p *Pool) PendingNonce(address string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.pendingNonce(address)
+}
+
+// pendingNonce es PendingNonce con el mutex ya tomado
+func (p *Pool) pendingNonce(address string) int {
+	queue := p.queues[address]
+	if len(queue) == 0 {
+		return p.state.Nonce(address)
+	}
+	return queue[len(queue)-1].tx.AccountNonce() + 1
+}
+
+// pendingCost suma lo máximo que pueden gastar las pendientes de un remitente
+func (p *Pool) pendingCost(address string) *big.Int {
+	total := new(big.Int)
+	for _, e := range p.queues[address] {
+		total.Add(total, e.tx.Cost())
+	}
+	return total
+}
+
+// Pending devuelve las transacciones que se pueden minar ya (sin huecos de
+// nonce por delante), en orden de llegada
+func (p *Pool) Pending() []Tx {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.collect(true)
+}
+
+// Queued devuelve las transacciones que esperan a que se llene un hueco de
+// nonce de su remitente, en orden de llegada
+func (p *Pool) Queued() []Tx {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.collect(false)
+}
+
+// collect devuelve las transacciones ejecutables (o las que esperan) en orden de llegada
+func (p *Pool) collect(executable bool) []Tx {
+	var entries []*entry
+	for sender, queue := range p.queues {
+		next := p.state.Nonce(sender)
+		for _, e := range queue {
+			ready := e.tx.AccountNonce() == next
+			if ready {
+				next++
+			}
+			if ready == executable {
+				entries = append(entries, e)
+			}
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].seq < entries[j].seq
+	})
+
+	txs := make([]Tx, len(entries))
+	for i, e := range entries {
+		txs[i] = e.tx
+	}
+	return txs
+}
+
+// Get devuelve una transacción pendiente por su hash
+func (p *Pool) Get(hash string) (Tx, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	e, exists := p.byHash[hash]
+	if !exists {
+		return nil, false
+	}
+	return e.tx, true
+}
+
+// Len devuelve cuántas transacciones hay pendientes
+func (p *Pool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.byHash)
+}
+
+// Reset actualiza el pool tras añadir un bloque a la cadena
+//
+// Quita las transacciones incluidas (por hash) y vuelve a validar el resto
+// contra el nuevo estado. Se descartan las caducadas (Lifetime, MaxBlocks) y las
+// que ya no pueden ejecutarse (nonce usado, hueco en los nonces que nadie tiene
+// reservado, saldo que no las cubre...). Devuelve cuántas se descartaron.
+func (p *Pool) Reset(included []string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, hash := range included {
+		if e, exists := p.byHash[hash]; exists {
+			delete(p.byHash, hash)
+			p.bytes -= e.size
+		}
+	}
+
+	now, height := time.Now(), p.state.Height()
+
+	dropped := 0
+	for sender, queue := range p.queues {
+		expected := p.state.Nonce(sender)
+		reserved := p.state.ReservedUntil(sender)
+		available := new(big.Int).Set(p.state.Balance(sender))
+
+		var kept []*entry
+		for _, e := range queue {
+			if _, exists := p.byHash[e.tx.Hash()]; !exists {
+				continue // Incluida en el bloque
+			}
+
+			// Tras un hueco solo siguen las que tienen el nonce reservado
+			nonce := e.tx.AccountNonce()
+			reason := ""
+			if p.expired(e, now, height) {
+				reason = DropExpired
+			} else if nonce != expected && (nonce < expected || nonce >= reserved) {
+				reason = DropInvalid
+			} else if p.state.Validate(e.tx, nonce) != nil || e.tx.Cost().Cmp(available) > 0 {
+				reason = DropInvalid
+			}
+			if reason != "" {
+				delete(p.byHash, e.tx.Hash())
+				p.bytes -= e.size
+				p.recordDrop(e.tx, reason)
+				dropped++
+				continue
+			}
+
+			kept = append(kept, e)
+			expected = nonce + 1
+			available.Sub(available, e.tx.Cost())
+		}
+
+		if len(kept) == 0 {
+			delete(p.queues, sender)
+		} else {
+			p.queues[sender] = kept
+		}
+	}
+
+	return dropped
+}
+
+// Expire descarta las pendientes que superaron su vida máxima sin esperar a un
+// bloque (sin bloques, Reset no se llama y Lifetime no se comprobaría nunca)
+// Es Reset sin transacciones incluidas: también vuelve a validar el resto.
+// Devuelve cuántas se descartaron.
+func (p *Pool) Expire() int {
+	return p.Reset(nil)
+}
+
+// expired indica si una pendiente superó su vida máxima
+func (p *Pool) expired(e *entry, now time.Time, height int) bool {
+	if p.Lifetime > 0 && now.Sub(e.added) > p.Lifetime {
+		return true
+	}
+	return p.MaxBlocks > 0 && height-e.height >= p.MaxBlocks
+}
+
+// recordDrop apunta una transacción descartada (olvidando las más antiguas)
+func (p *Pool) recordDrop(tx Tx, reason string) {
+	hash := tx.Hash()
+	if _, exists := p.dropped[hash]; !exists {
+		p.droppedOrder = append(p.droppedOrder, hash)
+	}
+	p.dropped[hash] = Dropped{
+		Hash:   hash,
+		Sender: tx.Sender(),
+		Nonce:  tx.AccountNonce(),
+		Reason: reason,
+		Time:   time.Now(),
+	}
+
+	for len(p.droppedOrder) > maxDropped {
+		delete(p.dropped, p.droppedOrder[0])
+		p.droppedOrder = p.droppedOrder[1:]
+	}
+}
+
+// forgetDrop quita una transacción de las descartadas
+func (p *Pool) forgetDrop(hash string) {
+	if _, exists := p.dropped[hash]; !exists {
+		return
+	}
+	delete(p.dropped, hash)
+	for i, h := range p.droppedOrder {
+		if h == hash {
+			p.droppedOrder = append(p.droppedOrder[:i], p.droppedOrder[i+1:]...)
+			break
+		}
+	}
+}
+
+// Dropped devuelve las transacciones descartadas, de la más reciente a la más antigua
+// Si sender no está vacío, solo las de ese remitente
+func (p *Pool) Dropped(sender string) []Dropped {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var result []Dropped
+	for i := len(p.droppedOrder) - 1; i >= 0; i-- {
+		d := p.dropped[p.droppedOrder[i]]
+		if sender == "" || d.Sender == sender {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+// DroppedTx indica si una transacción fue descartada y por qué
+func (p *Pool) DroppedTx(hash string) (Dropped, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	d, exists := p.dropped[hash]
+	return d, exists
+}
@@ -0,0 +1,206 @@
+package mempool
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// testTx es una transacción mínima: solo lo que el pool necesita
+type testTx struct {
+	hash   string
+	sender string
+	nonce  int
+	cost   int64
+	tip    int64
+	size   int
+}
+
+func (tx *testTx) Hash() string                           { return tx.hash }
+func (tx *testTx) Sender() string                         { return tx.sender }
+func (tx *testTx) AccountNonce() int                      { return tx.nonce }
+func (tx *testTx) Cost() *big.Int                         { return big.NewInt(tx.cost) }
+func (tx *testTx) EffectiveTip(baseFee *big.Int) *big.Int { return big.NewInt(tx.tip) }
+func (tx *testTx) Size() int                              { return tx.size }
+
+// testState es un estado en memoria: los nonces y saldos se cambian a mano
+type testState struct {
+	nonces   map[string]int
+	balances map[string]int64
+	reserved map[string]int
+	invalid  map[string]bool // hash -> las reglas de la cadena la rechazan
+	height   int
+}
+
+func newTestState() *testState {
+	return &testState{
+		nonces:   make(map[string]int),
+		balances: make(map[string]int64),
+		reserved: make(map[string]int),
+		invalid:  make(map[string]bool),
+	}
+}
+
+func (s *testState) Nonce(address string) int         { return s.nonces[address] }
+func (s *testState) Balance(address string) *big.Int  { return big.NewInt(s.balances[address]) }
+func (s *testState) Height() int                      { return s.height }
+func (s *testState) BaseFee() *big.Int                { return nil }
+func (s *testState) Included(hash string) bool        { return false }
+func (s *testState) ReservedUntil(address string) int { return s.reserved[address] }
+
+func (s *testState) Validate(tx Tx, expectedNonce int) error {
+	if tx.AccountNonce() != expectedNonce {
+		return fmt.Errorf("nonce %d, esperado %d", tx.AccountNonce(), expectedNonce)
+	}
+	if s.invalid[tx.Hash()] {
+		return fmt.Errorf("inválida")
+	}
+	return nil
+}
+
+// newTestPool crea un pool sobre un estado nuevo en el que cada remitente
+// de senders tiene 1000 de saldo
+func newTestPool(senders ...string) (*Pool, *testState) {
+	state := newTestState()
+	for _, sender := range senders {
+		state.balances[sender] = 1000
+	}
+	return New(state), state
+}
+
+// transfer crea una transacción de sender con coste 10, propina tip y 100 bytes
+func transfer(sender string, nonce int, tip int64) *testTx {
+	return &testTx{hash: fmt.Sprintf("%s-%d-%d", sender, nonce, tip), sender: sender, nonce: nonce, cost: 10, tip: tip, size: 100}
+}
+
+func mustAdd(t *testing.T, pool *Pool, txs ...*testTx) {
+	t.Helper()
+	for _, tx := range txs {
+		if err := pool.Add(tx); err != nil {
+			t.Fatalf("Add(%s): %v", tx.hash, err)
+		}
+	}
+}
+
+// TestExpireDropsWithoutBlocks: Lifetime se cumple aunque no llegue ningún
+// bloque, y lo descartado se puede consultar
+func TestExpireDropsWithoutBlocks(t *testing.T) {
+	pool, _ := newTestPool("alice", "bob")
+	pool.Lifetime = time.Hour
+
+	old, follower := transfer("alice", 0, 1), transfer("alice", 1, 1)
+	fresh := transfer("bob", 0, 1)
+	mustAdd(t, pool, old, follower, fresh)
+	pool.byHash[old.hash].added = time.Now().Add(-2 * time.Hour)
+
+	// La de detrás se queda con un hueco: también sale
+	if dropped := pool.Expire(); dropped != 2 {
+		t.Fatalf("Expire descartó %d, esperaba 2", dropped)
+	}
+	if pool.Len() != 1 {
+		t.Fatalf("quedan %d pendientes, esperaba 1", pool.Len())
+	}
+
+	d, ok := pool.DroppedTx(old.hash)
+	if !ok || d.Reason != DropExpired {
+		t.Fatalf("DroppedTx = %+v, %v; esperaba caducada", d, ok)
+	}
+	if d, _ := pool.DroppedTx(follower.hash); d.Reason != DropInvalid {
+		t.Fatalf("la siguiente salió como %q, esperaba inválida", d.Reason)
+	}
+	if got := pool.Dropped("alice"); len(got) != 2 || got[0].Hash != follower.hash {
+		t.Fatalf("Dropped(alice) = %+v", got)
+	}
+}
+
+// TestEvictsLowestTipTail: con el pool lleno entra la que paga más, desalojando
+// la última de la cola que menos propina deja (nunca una intermedia)
+func TestEvictsLowestTipTail(t *testing.T) {
+	pool, _ := newTestPool("alice", "bob", "carol")
+	pool.MaxTxs = 3
+
+	// alice 0 paga lo mínimo, pero quitarla dejaría un hueco delante de alice 1
+	aliceFirst, aliceSecond := transfer("alice", 0, 1), transfer("alice", 1, 9)
+	bob := transfer("bob", 0, 5)
+	mustAdd(t, pool, aliceFirst, aliceSecond, bob)
+
+	carol := transfer("carol", 0, 6)
+	mustAdd(t, pool, carol)
+
+	if _, ok := pool.Get(bob.hash); ok {
+		t.Fatal("bob seguía en el pool: esperaba que lo desalojara carol")
+	}
+	for _, tx := range []*testTx{aliceFirst, aliceSecond, carol} {
+		if _, ok := pool.Get(tx.hash); !ok {
+			t.Fatalf("%s ya no está en el pool", tx.hash)
+		}
+	}
+	if d, ok := pool.DroppedTx(bob.hash); !ok || d.Reason != DropEvicted {
+		t.Fatalf("DroppedTx(bob) = %+v, %v; esperaba desplazada", d, ok)
+	}
+}
+
+// TestUnderpricedRejected: con el pool lleno, una que no paga más que todas las
+// desalojables se rechaza y el pool no cambia
+func TestUnderpricedRejected(t *testing.T) {
+	pool, _ := newTestPool("alice", "bob", "carol")
+	pool.MaxTxs = 2
+	mustAdd(t, pool, transfer("alice", 0, 5), transfer("bob", 0, 3))
+
+	for _, tip := range []int64{1, 3} { // Igualar la propina tampoco basta
+		err := pool.Add(transfer("carol", 0, tip))
+		if !errors.Is(err, ErrUnderpriced) {
+			t.Fatalf("propina %d: %v, esperaba ErrUnderpriced", tip, err)
+		}
+	}
+	if pool.Len() != 2 || len(pool.Dropped("")) != 0 {
+		t.Fatalf("el pool cambió: %d pendientes, %d descartadas", pool.Len(), len(pool.Dropped("")))
+	}
+
+	// Una que no cabe ni con el pool vacío no desaloja a nadie
+	pool.MaxBytes = 150
+	huge := transfer("carol", 0, 100)
+	huge.size = 200
+	if err := pool.Add(huge); !errors.Is(err, ErrOversized) {
+		t.Fatalf("transacción de 200 bytes: %v, esperaba ErrOversized", err)
+	}
+}
+
+// TestResetRevalidates: tras un bloque salen las incluidas y se descartan las
+// que el nuevo estado ya no admite, junto con las que quedan tras su hueco
+func TestResetRevalidates(t *testing.T) {
+	pool, state := newTestPool("alice", "bob", "carol")
+	alice := []*testTx{transfer("alice", 0, 1), transfer("alice", 1, 1), transfer("alice", 2, 1)}
+	bob, carol := transfer("bob", 0, 1), transfer("carol", 0, 1)
+	mustAdd(t, pool, alice...)
+	mustAdd(t, pool, bob, carol)
+
+	// El bloque incluye alice 0; alice 1 deja de ser válida y bob se queda sin saldo
+	state.nonces["alice"] = 1
+	state.invalid[alice[1].hash] = true
+	state.balances["bob"] = 5
+	state.height++
+
+	if dropped := pool.Reset([]string{alice[0].hash}); dropped != 3 {
+		t.Fatalf("Reset descartó %d, esperaba 3", dropped)
+	}
+	if pool.Len() != 1 {
+		t.Fatalf("quedan %d pendientes, esperaba solo la de carol", pool.Len())
+	}
+	if _, ok := pool.Get(carol.hash); !ok {
+		t.Fatal("carol no debería descartarse")
+	}
+	if _, ok := pool.DroppedTx(alice[0].hash); ok {
+		t.Fatal("la incluida en el bloque no cuenta como descartada")
+	}
+	for _, tx := range []*testTx{alice[1], alice[2], bob} {
+		if d, ok := pool.DroppedTx(tx.hash); !ok || d.Reason != DropInvalid {
+			t.Fatalf("DroppedTx(%s) = %+v, %v; esperaba inválida", tx.hash, d, ok)
+		}
+	}
+	if got := pool.PendingNonce("alice"); got != 1 {
+		t.Fatalf("PendingNonce(alice) = %d, esperaba 1", got)
+	}
+}
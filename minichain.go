@@ -3,16 +3,71 @@ package main
 import (
 	"bufio"
 	"encoding/hex"
+	"flag"
 	"fmt"
+	"math/big"
 	"minichain/blockchain"
-	"minichain/compiler" // ← AÑADIR
-	"minichain/crypto"   // ← AÑADIR
+	"minichain/compiler"
+	"minichain/crypto"
+	"minichain/i18n"
+	"minichain/mempool"
+	"minichain/utils"
 	"os"
+	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 func main() {
+	// Sin subcomando (o con flags directamente) se abre la consola interactiva
+	name := "console"
+	args := os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	command, exists := commands[name]
+	if !exists {
+		fmt.Println(i18n.T("cli.unknownCommand", name))
+		printUsage()
+		os.Exit(2)
+	}
+
+	if err := command.Run(args); err != nil {
+		fmt.Println(i18n.T("err.generic", err))
+		os.Exit(1)
+	}
+}
+
+// runConsole abre la consola interactiva con una blockchain en memoria
+func runConsole(args []string) error {
+	flags := flag.NewFlagSet("console", flag.ExitOnError)
+	minerThreads := flags.Int("miner.threads", runtime.NumCPU(), "goroutines que buscan el nonce en paralelo")
+	consensus := flags.String("consensus", "pow", "motor de consenso: pow o pos (experimental)")
+	minerCoinbase := flags.String("miner.coinbase", "", "dirección que cobra la recompensa de cada bloque (por defecto: cuenta 1)")
+	genesisPath := flags.String("genesis", "", "especificación del génesis en JSON (por defecto: saldos de ejemplo)")
+	mempoolMaxTxs := flags.Int("mempool.maxtxs", mempool.DefaultMaxTxs, "máximo de transacciones pendientes")
+	mempoolMaxBytes := flags.Int("mempool.maxbytes", mempool.DefaultMaxBytes, "máximo de bytes entre todas las pendientes")
+	mempoolLifetime := flags.Duration("mempool.lifetime", mempool.DefaultLifetime, "tiempo máximo de una pendiente sin minar (0 = sin límite)")
+	mempoolMaxBlocks := flags.Int("mempool.maxblocks", mempool.DefaultMaxBlocks, "bloques máximos de una pendiente sin minar (0 = sin límite)")
+	importPath := flags.String("import", "", "cargar los bloques de un archivo exportado (requiere el mismo --genesis)")
+	var checkpoints []string
+	flags.Func("checkpoint", "checkpoint altura:hash que la cadena no puede contradecir (repetible)", func(value string) error {
+		if _, _, err := blockchain.ParseCheckpoint(value); err != nil {
+			return err
+		}
+		checkpoints = append(checkpoints, value)
+		return nil
+	})
+	lang := addLangFlag(flags)
+	flags.Parse(args)
+	if err := i18n.SetLanguage(*lang); err != nil {
+		return err
+	}
+
 	fmt.Println("╔══════════════════════════════════════════╗")
 	fmt.Println("║                                          ║")
 	fmt.Println("║          🔗 MINICHAIN v2.0 🔗           ║")
@@ -20,57 +75,91 @@ func main() {
 	fmt.Println("║                                          ║")
 	fmt.Println("╚══════════════════════════════════════════╝")
 
-	// Crear la blockchain con dificultad 3
-	fmt.Println("\n🚀 Creando blockchain...")
-	bc := blockchain.NewBlockchain(3)
-
 	// Crear una wallet para gestionar cuentas
 	wallet := crypto.NewWallet()
+	multisigs := make(map[string]*blockchain.Multisig) // Cuentas multifirma creadas en esta sesión
 
-	// Crear 3 cuentas de ejemplo y darles saldo inicial
+	// Crear 3 cuentas de ejemplo
 	fmt.Println("\n💼 Creando cuentas de ejemplo...")
-
 	account1, _ := wallet.CreateAccount()
-	bc.AccountState.AddBalance(account1, 100.0)
-
 	account2, _ := wallet.CreateAccount()
-	bc.AccountState.AddBalance(account2, 50.0)
-
 	account3, _ := wallet.CreateAccount()
-	bc.AccountState.AddBalance(account3, 75.0)
 
-	fmt.Println("\n💰 Saldos iniciales asignados:")
-	fmt.Printf("   Cuenta 1: 100 MTC\n")
-	fmt.Printf("   Cuenta 2: 50 MTC\n")
-	fmt.Printf("   Cuenta 3: 75 MTC\n")
+	// Sin especificación, el génesis reparte saldo inicial a las cuentas de ejemplo
+	var genesis *blockchain.Genesis
+	if *genesisPath != "" {
+		var err error
+		if genesis, err = blockchain.LoadGenesis(*genesisPath); err != nil {
+			return err
+		}
+		fmt.Printf("\n📜 Génesis: %s (chain ID %d, %d cuentas con saldo)\n", *genesisPath, genesis.ChainID, len(genesis.Alloc))
+	} else {
+		genesis = &blockchain.Genesis{
+			ChainID:    blockchain.DefaultChainID,
+			Difficulty: 3,
+			Timestamp:  time.Now().Unix(),
+			Alloc: map[string]blockchain.GenesisAccount{
+				account1: {Balance: utils.MTC(100)},
+				account2: {Balance: utils.MTC(50)},
+				account3: {Balance: utils.MTC(75)},
+			},
+		}
+
+		fmt.Println("\n💰 Saldos iniciales asignados:")
+		fmt.Printf("   Cuenta 1: 100 MTC\n")
+		fmt.Printf("   Cuenta 2: 50 MTC\n")
+		fmt.Printf("   Cuenta 3: 75 MTC\n")
+
+		// Validador inicial: sin stake nadie podría producir bloques
+		if *consensus == "pos" {
+			genesis.Alloc[account1] = blockchain.GenesisAccount{Balance: utils.MTC(100), Stake: utils.MTC(10)}
+			fmt.Println("🔒 Stake inicial: 10 MTC para la cuenta 1")
+		}
+	}
+
+	fmt.Println("\n🚀 Creando blockchain...")
+	bc, err := blockchain.NewBlockchainFromGenesis(genesis)
+	if err != nil {
+		return err
+	}
+	bc.MinerThreads = *minerThreads
+	bc.Mempool.MaxTxs = *mempoolMaxTxs
+	bc.Mempool.MaxBytes = *mempoolMaxBytes
+	bc.Mempool.Lifetime = *mempoolLifetime
+	bc.Mempool.MaxBlocks = *mempoolMaxBlocks
+	for _, value := range checkpoints {
+		height, hash, _ := blockchain.ParseCheckpoint(value)
+		bc.AddCheckpoint(height, hash)
+	}
+
+	// Motor de consenso
+	if *consensus == "pos" {
+		bc.Engine = blockchain.NewProofOfStake(wallet.GetKeyPair)
+	}
+	fmt.Printf("\n⚖️  Consenso: %s\n", bc.Engine.Name())
+
+	// Cargar una cadena exportada antes (se revalida bloque a bloque)
+	if *importPath != "" {
+		imported, err := importChainFile(bc, *importPath)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\n📥 %d bloques importados de %s\n", imported, *importPath)
+	}
+
+	// Dirección que cobra la recompensa de los bloques minados
+	bc.Coinbase = *minerCoinbase
+	if bc.Coinbase == "" {
+		bc.Coinbase = account1
+	}
+	fmt.Printf("\n⛏️  Recompensa de minado (%s MTC) para: %s\n", utils.FormatMTC(blockchain.BlockReward), bc.Coinbase)
 
 	// Menú interactivo
 	scanner := bufio.NewScanner(os.Stdin)
 
 	for {
-		fmt.Println("\n╔════════════════════════════════════════╗")
-		fmt.Println("║              MENÚ PRINCIPAL            ║")
-		fmt.Println("╠════════════════════════════════════════╣")
-		fmt.Println("║ 1. Ver cuentas en wallet               ║")
-		fmt.Println("║ 2. Crear nueva cuenta                  ║")
-		fmt.Println("║ 3. Ver estado de cuentas               ║")
-		fmt.Println("║ 4. Crear transacción                   ║")
-		fmt.Println("║ 5. Ver transacciones pendientes        ║")
-		fmt.Println("║ 6. Minar bloque                        ║")
-		fmt.Println("║ 7. Ver blockchain completa             ║")
-		fmt.Println("║ 8. Verificar integridad                ║")
-		fmt.Println("║ --- CONTRATOS INTELIGENTES ---         ║")
-		fmt.Println("║ 10. Desplegar contrato (directo)       ║")
-		fmt.Println("║ 11. Listar contratos                   ║")
-		fmt.Println("║ 12. Ejecutar contrato (directo)        ║")
-		fmt.Println("║ 13. Ver estado de contrato             ║")
-		fmt.Println("║ --- TRANSACCIONES DE CONTRATOS ---     ║")
-		fmt.Println("║ 14. TX: Desplegar contrato             ║")
-		fmt.Println("║ 15. TX: Llamar a contrato              ║")
-		fmt.Println("║ --- SALIR ---                          ║")
-		fmt.Println("║ 9. Salir                               ║")
-		fmt.Println("╚════════════════════════════════════════╝")
-		fmt.Print("\n👉 Selecciona una opción: ")
+		printMenu()
+		fmt.Print("\n" + i18n.T("menu.prompt"))
 
 		scanner.Scan()
 		option := strings.TrimSpace(scanner.Text())
@@ -88,10 +177,10 @@ func main() {
 			scanner.Scan()
 			amountStr := strings.TrimSpace(scanner.Text())
 			if amountStr != "" {
-				amount, err := strconv.ParseFloat(amountStr, 64)
-				if err == nil && amount > 0 {
+				amount, err := utils.ParseMTC(amountStr)
+				if err == nil && amount.Sign() > 0 {
 					bc.AccountState.AddBalance(address, amount)
-					fmt.Printf("✅ Saldo asignado: %.2f MTC\n", amount)
+					fmt.Printf("✅ Saldo asignado: %s MTC\n", utils.FormatMTC(amount))
 				}
 			}
 
@@ -108,9 +197,9 @@ func main() {
 			accounts := []string{}
 			i := 1
 			for address := range wallet.KeyPairs {
-				fmt.Printf("%d. %s (Balance: %.2f MTC, Nonce: %d)\n",
+				fmt.Printf("%d. %s (Balance: %s MTC, Nonce: %d)\n",
 					i, address[:16]+"...",
-					bc.GetBalance(address),
+					utils.FormatMTC(bc.GetBalance(address)),
 					bc.GetNonce(address))
 				accounts = append(accounts, address)
 				i++
@@ -121,59 +210,84 @@ func main() {
 			scanner.Scan()
 			fromIdx, err := strconv.Atoi(strings.TrimSpace(scanner.Text()))
 			if err != nil || fromIdx < 1 || fromIdx > len(accounts) {
-				fmt.Println("❌ Cuenta inválida")
+				fmt.Println(i18n.T("err.invalidAccount"))
 				continue
 			}
 			fromAddress := accounts[fromIdx-1]
 
-			// Seleccionar destinatario
-			fmt.Print("👤 Número de cuenta destinatario: ")
+			// Seleccionar destinatario (del wallet o cualquier dirección, p. ej. una multifirma)
+			fmt.Print("👤 Número de cuenta destinatario (o dirección): ")
 			scanner.Scan()
-			toIdx, err := strconv.Atoi(strings.TrimSpace(scanner.Text()))
-			if err != nil || toIdx < 1 || toIdx > len(accounts) {
-				fmt.Println("❌ Cuenta inválida")
+			toAddress := parseAccount(scanner.Text(), accounts)
+			if toAddress == "" {
+				fmt.Println(i18n.T("err.invalidAccount"))
 				continue
 			}
-			toAddress := accounts[toIdx-1]
 
 			if fromAddress == toAddress {
-				fmt.Println("❌ No puedes enviar a ti mismo")
+				fmt.Println(i18n.T("err.selfTransfer"))
 				continue
 			}
 
 			// Cantidad
 			fmt.Print("💰 Cantidad a enviar: ")
 			scanner.Scan()
-			amount, err := strconv.ParseFloat(strings.TrimSpace(scanner.Text()), 64)
-			if err != nil || amount <= 0 {
-				fmt.Println("❌ Cantidad inválida")
+			amount, err := utils.ParseMTC(scanner.Text())
+			if err != nil || amount.Sign() <= 0 {
+				fmt.Println(i18n.T("err.invalidAmount"))
 				continue
 			}
 
+			// Precio del gas (opcional): pagar más adelanta la transacción
+			baseFee := bc.BaseFee()
+			if baseFee != nil {
+				fmt.Printf("📉 Base fee del próximo bloque: %s MTC/gas (se quema)\n", utils.FormatMTC(baseFee))
+			}
+			fmt.Printf("⛽ Precio del gas en MTC (Enter = sugerido, %s): ", utils.FormatMTC(bc.SuggestGasPrice()))
+			scanner.Scan()
+			var gasPrice *big.Int
+			if gasPriceStr := strings.TrimSpace(scanner.Text()); gasPriceStr != "" {
+				gasPrice, err = utils.ParseMTC(gasPriceStr)
+				if err != nil || gasPrice.Sign() <= 0 {
+					fmt.Println(i18n.T("err.invalidAmount"))
+					continue
+				}
+			}
+
 			// Obtener nonce actual
-			nonce := bc.GetNonce(fromAddress)
+			nonce := bc.PendingNonce(fromAddress)
 
 			// Crear transacción
 			tx := blockchain.NewTransaction(fromAddress, toAddress, amount, nonce)
+			if gasPrice != nil {
+				tx.GasPrice = gasPrice
+			} else if tip := bc.SuggestGasTipCap(); baseFee != nil {
+				// Propina sugerida y margen para que el base fee pueda doblarse
+				tx.GasTipCap = tip
+				tx.GasPrice = new(big.Int).Add(new(big.Int).Mul(baseFee, big.NewInt(2)), tip)
+			} else {
+				tx.GasPrice = tip
+			}
 
 			// Firmar transacción
 			keyPair, err := wallet.GetKeyPair(fromAddress)
 			if err != nil {
-				fmt.Printf("❌ Error: %v\n", err)
+				fmt.Println(i18n.T("err.generic", err))
 				continue
 			}
 
-			if err := tx.Sign(keyPair); err != nil {
+			if err := tx.Sign(keyPair, bc.ChainID); err != nil {
 				fmt.Printf("❌ Error firmando: %v\n", err)
 				continue
 			}
 
 			// Mostrar transacción
 			tx.Print()
+			fmt.Printf("📦 Raw (para enviarla desde otro nodo con la opción 20):\n%s\n", tx.RawHex())
 
 			// Añadir al mempool
 			if err := bc.AddTransaction(tx); err != nil {
-				fmt.Printf("❌ Error: %v\n", err)
+				fmt.Println(i18n.T("err.generic", err))
 				continue
 			}
 
@@ -185,12 +299,13 @@ func main() {
 			// Minar bloque
 			fmt.Println("\n⛏️  MINAR BLOQUE")
 
-			if len(bc.PendingTxs) == 0 {
-				fmt.Println("❌ No hay transacciones pendientes para minar")
+			pending := len(bc.Mempool.Pending())
+			if pending == 0 {
+				fmt.Println(i18n.T("err.noPending"))
 				continue
 			}
 
-			fmt.Printf("📊 Transacciones a incluir: %d\n", len(bc.PendingTxs))
+			fmt.Printf("📊 Transacciones a incluir: %d\n", pending)
 			fmt.Print("⚠️  Esto puede tardar unos segundos. ¿Continuar? (s/n): ")
 			scanner.Scan()
 			if strings.ToLower(strings.TrimSpace(scanner.Text())) != "s" {
@@ -205,17 +320,17 @@ func main() {
 
 		case "8":
 			// Verificar integridad
-			fmt.Println("\n🔍 Verificando integridad de la blockchain...")
+			fmt.Println("\n" + i18n.T("chain.verifying"))
 			if bc.IsValid() {
-				fmt.Println("✅ ¡Blockchain válida! Todos los bloques están intactos.")
+				fmt.Println(i18n.T("chain.valid"))
 			} else {
-				fmt.Println("❌ ¡Blockchain corrupta! Se detectaron alteraciones.")
+				fmt.Println(i18n.T("chain.corrupt"))
 			}
 
 		case "9":
 			// Salir
-			fmt.Println("\n👋 ¡Gracias por usar MiniChain!")
-			return
+			fmt.Println("\n" + i18n.T("menu.goodbye"))
+			return nil
 
 		case "10":
 			// Desplegar contrato
@@ -263,7 +378,7 @@ func main() {
 				}
 
 				if len(lines) == 0 {
-					fmt.Println("❌ No se escribió ningún código")
+					fmt.Println(i18n.T("err.noCode"))
 					continue
 				}
 
@@ -293,7 +408,7 @@ func main() {
 				hexStr = strings.TrimSpace(hexStr)
 				bytecode, err = hex.DecodeString(hexStr)
 				if err != nil {
-					fmt.Printf("❌ Error: %v\n", err)
+					fmt.Println(i18n.T("err.generic", err))
 					continue
 				}
 			}
@@ -313,7 +428,7 @@ func main() {
 			fmt.Scanln(&ownerIdxStr)
 			ownerIdx, err := strconv.Atoi(strings.TrimSpace(ownerIdxStr))
 			if err != nil || ownerIdx < 1 || ownerIdx > len(accounts) {
-				fmt.Println("❌ Cuenta inválida")
+				fmt.Println(i18n.T("err.invalidAccount"))
 				continue
 			}
 			ownerAddress := accounts[ownerIdx-1]
@@ -321,7 +436,7 @@ func main() {
 			// Desplegar
 			contract, err := bc.DeployContract(ownerAddress, bytecode)
 			if err != nil {
-				fmt.Printf("❌ Error: %v\n", err)
+				fmt.Println(i18n.T("err.generic", err))
 				continue
 			}
 
@@ -336,7 +451,7 @@ func main() {
 			fmt.Println("\n⚙️  EJECUTAR CONTRATO")
 
 			if len(bc.Contracts) == 0 {
-				fmt.Println("❌ No hay contratos desplegados")
+				fmt.Println(i18n.T("err.noContracts"))
 				continue
 			}
 
@@ -354,14 +469,14 @@ func main() {
 			scanner.Scan()
 			contractIdx, err := strconv.Atoi(strings.TrimSpace(scanner.Text()))
 			if err != nil || contractIdx < 1 || contractIdx > len(contractAddrs) {
-				fmt.Println("❌ Contrato inválido")
+				fmt.Println(i18n.T("err.invalidContract"))
 				continue
 			}
 			contractAddr := contractAddrs[contractIdx-1]
 
 			// Ejecutar con gas suficiente
 			if err := bc.ExecuteContract(contractAddr, 1000000); err != nil {
-				fmt.Printf("❌ Error: %v\n", err)
+				fmt.Println(i18n.T("err.generic", err))
 			}
 
 		case "13":
@@ -369,7 +484,7 @@ func main() {
 			fmt.Println("\n📊 ESTADO DE CONTRATO")
 
 			if len(bc.Contracts) == 0 {
-				fmt.Println("❌ No hay contratos desplegados")
+				fmt.Println(i18n.T("err.noContracts"))
 				continue
 			}
 
@@ -387,7 +502,7 @@ func main() {
 			scanner.Scan()
 			contractIdx, err := strconv.Atoi(strings.TrimSpace(scanner.Text()))
 			if err != nil || contractIdx < 1 || contractIdx > len(contractAddrs) {
-				fmt.Println("❌ Contrato inválido")
+				fmt.Println(i18n.T("err.invalidContract"))
 				continue
 			}
 			contractAddr := contractAddrs[contractIdx-1]
@@ -406,8 +521,8 @@ func main() {
 			for address := range wallet.KeyPairs {
 				balance := bc.GetBalance(address)
 				nonce := bc.GetNonce(address)
-				fmt.Printf("%d. %s (Balance: %.2f MTC, Nonce: %d)\n",
-					i, address[:16]+"...", balance, nonce)
+				fmt.Printf("%d. %s (Balance: %s MTC, Nonce: %d)\n",
+					i, address[:16]+"...", utils.FormatMTC(balance), nonce)
 				accounts = append(accounts, address)
 				i++
 			}
@@ -416,7 +531,7 @@ func main() {
 			scanner.Scan()
 			accountIdx, err := strconv.Atoi(strings.TrimSpace(scanner.Text()))
 			if err != nil || accountIdx < 1 || accountIdx > len(accounts) {
-				fmt.Println("❌ Cuenta inválida")
+				fmt.Println(i18n.T("err.invalidAccount"))
 				continue
 			}
 			fromAddress := accounts[accountIdx-1]
@@ -460,7 +575,7 @@ func main() {
 				}
 
 				if len(lines) == 0 {
-					fmt.Println("❌ No se escribió ningún código")
+					fmt.Println(i18n.T("err.noCode"))
 					continue
 				}
 
@@ -481,13 +596,13 @@ func main() {
 				hexStr := strings.TrimSpace(scanner.Text())
 				bytecode, err = hex.DecodeString(hexStr)
 				if err != nil {
-					fmt.Printf("❌ Error: %v\n", err)
+					fmt.Println(i18n.T("err.generic", err))
 					continue
 				}
 			}
 
 			// Crear transacción
-			nonce := bc.GetNonce(fromAddress)
+			nonce := bc.PendingNonce(fromAddress)
 			tx := blockchain.NewContractDeploymentTx(fromAddress, bytecode, nonce)
 
 			// Firmar
@@ -497,14 +612,14 @@ func main() {
 				continue
 			}
 
-			if err := tx.Sign(keyPair); err != nil {
+			if err := tx.Sign(keyPair, bc.ChainID); err != nil {
 				fmt.Printf("❌ Error firmando: %v\n", err)
 				continue
 			}
 
 			// Añadir al mempool
 			if err := bc.AddTransaction(tx); err != nil {
-				fmt.Printf("❌ Error: %v\n", err)
+				fmt.Println(i18n.T("err.generic", err))
 				continue
 			}
 
@@ -516,7 +631,7 @@ func main() {
 			fmt.Println("\n⚙️  CREAR TRANSACCIÓN DE LLAMADA")
 
 			if len(bc.Contracts) == 0 {
-				fmt.Println("❌ No hay contratos desplegados")
+				fmt.Println(i18n.T("err.noContracts"))
 				continue
 			}
 
@@ -527,8 +642,8 @@ func main() {
 			for address := range wallet.KeyPairs {
 				balance := bc.GetBalance(address)
 				nonce := bc.GetNonce(address)
-				fmt.Printf("%d. %s (Balance: %.2f MTC, Nonce: %d)\n",
-					i, address[:16]+"...", balance, nonce)
+				fmt.Printf("%d. %s (Balance: %s MTC, Nonce: %d)\n",
+					i, address[:16]+"...", utils.FormatMTC(balance), nonce)
 				accounts = append(accounts, address)
 				i++
 			}
@@ -537,7 +652,7 @@ func main() {
 			scanner.Scan()
 			accountIdx, err := strconv.Atoi(strings.TrimSpace(scanner.Text()))
 			if err != nil || accountIdx < 1 || accountIdx > len(accounts) {
-				fmt.Println("❌ Cuenta inválida")
+				fmt.Println(i18n.T("err.invalidAccount"))
 				continue
 			}
 			fromAddress := accounts[accountIdx-1]
@@ -556,7 +671,7 @@ func main() {
 			scanner.Scan()
 			contractIdx, err := strconv.Atoi(strings.TrimSpace(scanner.Text()))
 			if err != nil || contractIdx < 1 || contractIdx > len(contractAddrs) {
-				fmt.Println("❌ Contrato inválido")
+				fmt.Println(i18n.T("err.invalidContract"))
 				continue
 			}
 			contractAddr := contractAddrs[contractIdx-1]
@@ -565,7 +680,7 @@ func main() {
 			calldata := []byte{}
 
 			// Crear transacción
-			nonce := bc.GetNonce(fromAddress)
+			nonce := bc.PendingNonce(fromAddress)
 			tx := blockchain.NewContractCallTx(fromAddress, contractAddr, calldata, nonce)
 
 			// Firmar
@@ -574,22 +689,479 @@ func main() {
 				fmt.Printf("❌ Error obteniendo keypair: %v\n", err)
 				continue
 			}
-			if err := tx.Sign(keyPair); err != nil {
+			if err := tx.Sign(keyPair, bc.ChainID); err != nil {
 				fmt.Printf("❌ Error firmando: %v\n", err)
 				continue
 			}
 
 			// Añadir al mempool
 			if err := bc.AddTransaction(tx); err != nil {
-				fmt.Printf("❌ Error: %v\n", err)
+				fmt.Println(i18n.T("err.generic", err))
 				continue
 			}
 
 			fmt.Println("✅ Transacción de llamada añadida al mempool")
 			fmt.Println("💡 Usa la opción 6 para minar y ejecutar el contrato")
 
+		case "16":
+			// Crear un lote de transferencias firmadas off-chain
+			fmt.Println("\n📦 CREAR LOTE DE TRANSFERENCIAS OFF-CHAIN")
+
+			fmt.Println("\nCuentas disponibles:")
+			accounts := []string{}
+			i := 1
+			for address := range wallet.KeyPairs {
+				fmt.Printf("%d. %s (Balance: %s MTC, Nonce: %d)\n",
+					i, address[:16]+"...", utils.FormatMTC(bc.GetBalance(address)), bc.GetNonce(address))
+				accounts = append(accounts, address)
+				i++
+			}
+
+			fmt.Print("\n👤 Número de cuenta agregadora (paga el gas): ")
+			scanner.Scan()
+			aggIdx, err := strconv.Atoi(strings.TrimSpace(scanner.Text()))
+			if err != nil || aggIdx < 1 || aggIdx > len(accounts) {
+				fmt.Println(i18n.T("err.invalidAccount"))
+				continue
+			}
+			aggregator := accounts[aggIdx-1]
+
+			// Cada titular firma sus autorizaciones con nonces consecutivos
+			nonces := make(map[string]int)
+			batch := []*blockchain.TransferAuthorization{}
+
+			for {
+				fmt.Print("\n👤 Remitente (número, Enter para terminar): ")
+				scanner.Scan()
+				fromStr := strings.TrimSpace(scanner.Text())
+				if fromStr == "" {
+					break
+				}
+				fromIdx, err := strconv.Atoi(fromStr)
+				if err != nil || fromIdx < 1 || fromIdx > len(accounts) {
+					fmt.Println(i18n.T("err.invalidAccount"))
+					continue
+				}
+
+				fmt.Print("👤 Destinatario (número): ")
+				scanner.Scan()
+				toIdx, err := strconv.Atoi(strings.TrimSpace(scanner.Text()))
+				if err != nil || toIdx < 1 || toIdx > len(accounts) || toIdx == fromIdx {
+					fmt.Println(i18n.T("err.invalidAccount"))
+					continue
+				}
+
+				fmt.Print("💰 Cantidad: ")
+				scanner.Scan()
+				amount, err := utils.ParseMTC(scanner.Text())
+				if err != nil || amount.Sign() <= 0 {
+					fmt.Println(i18n.T("err.invalidAmount"))
+					continue
+				}
+
+				from := accounts[fromIdx-1]
+				if _, ok := nonces[from]; !ok {
+					nonces[from] = bc.GetNonce(from)
+				}
+
+				auth := blockchain.NewTransferAuthorization(from, accounts[toIdx-1], amount, nonces[from])
+				keyPair, _ := wallet.GetKeyPair(from)
+				if err := auth.Sign(keyPair, bc.ChainID); err != nil {
+					fmt.Printf("❌ Error firmando: %v\n", err)
+					continue
+				}
+
+				nonces[from]++
+				batch = append(batch, auth)
+				fmt.Printf("✍️  Autorización %d firmada\n", len(batch))
+			}
+
+			if len(batch) == 0 {
+				fmt.Println(i18n.T("err.emptyBatch"))
+				continue
+			}
+
+			// El agregador firma la transacción que incluye el lote
+			tx := blockchain.NewBatchTx(aggregator, batch, bc.PendingNonce(aggregator))
+			keyPair, _ := wallet.GetKeyPair(aggregator)
+			if err := tx.Sign(keyPair, bc.ChainID); err != nil {
+				fmt.Printf("❌ Error firmando: %v\n", err)
+				continue
+			}
+
+			if err := bc.AddTransaction(tx); err != nil {
+				fmt.Println(i18n.T("err.generic", err))
+				continue
+			}
+
+			fmt.Println("💡 Usa la opción 6 para minar y aplicar el lote")
+
+		case "17":
+			// Ver el bytecode desplegado (y opcionalmente desensamblado)
+			fmt.Println("\n🔎 CÓDIGO DE CONTRATO")
+
+			if len(bc.Contracts) == 0 {
+				fmt.Println(i18n.T("err.noContracts"))
+				continue
+			}
+
+			fmt.Println("\nContratos disponibles:")
+			contractAddrs := []string{}
+			i := 1
+			for address := range bc.Contracts {
+				fmt.Printf("%d. %s\n", i, address[:16]+"...")
+				contractAddrs = append(contractAddrs, address)
+				i++
+			}
+
+			fmt.Print("\nNúmero de contrato: ")
+			scanner.Scan()
+			contractIdx, err := strconv.Atoi(strings.TrimSpace(scanner.Text()))
+			if err != nil || contractIdx < 1 || contractIdx > len(contractAddrs) {
+				fmt.Println(i18n.T("err.invalidContract"))
+				continue
+			}
+
+			code, err := bc.GetCode(contractAddrs[contractIdx-1])
+			if err != nil {
+				fmt.Println(i18n.T("err.generic", err))
+				continue
+			}
+
+			compiler.PrintBytecode(code)
+
+			fmt.Print("\n¿Desensamblar? (s/n): ")
+			scanner.Scan()
+			if strings.ToLower(strings.TrimSpace(scanner.Text())) == "s" {
+				fmt.Println("\n📜 Desensamblado:")
+				fmt.Print(compiler.NewAssembler().Disassemble(code))
+			}
+
+		case "18":
+			// Bloquear fondos como stake para producir bloques en PoS
+			fmt.Println("\n🔒 BLOQUEAR STAKE")
+
+			fmt.Println("\nCuentas disponibles:")
+			accounts := []string{}
+			i := 1
+			for address := range wallet.KeyPairs {
+				fmt.Printf("%d. %s (Balance: %s MTC, Stake: %s MTC)\n",
+					i, address[:16]+"...", utils.FormatMTC(bc.GetBalance(address)),
+					utils.FormatMTC(bc.AccountState.GetStake(address)))
+				accounts = append(accounts, address)
+				i++
+			}
+
+			fmt.Print("\n👤 Número de cuenta: ")
+			scanner.Scan()
+			accountIdx, err := strconv.Atoi(strings.TrimSpace(scanner.Text()))
+			if err != nil || accountIdx < 1 || accountIdx > len(accounts) {
+				fmt.Println(i18n.T("err.invalidAccount"))
+				continue
+			}
+			fromAddress := accounts[accountIdx-1]
+
+			fmt.Print("💰 Cantidad a bloquear: ")
+			scanner.Scan()
+			amount, err := utils.ParseMTC(scanner.Text())
+			if err != nil || amount.Sign() <= 0 {
+				fmt.Println(i18n.T("err.invalidAmount"))
+				continue
+			}
+
+			tx := blockchain.NewStakeTx(fromAddress, amount, bc.PendingNonce(fromAddress))
+			keyPair, _ := wallet.GetKeyPair(fromAddress)
+			if err := tx.Sign(keyPair, bc.ChainID); err != nil {
+				fmt.Printf("❌ Error firmando: %v\n", err)
+				continue
+			}
+
+			if err := bc.AddTransaction(tx); err != nil {
+				fmt.Println(i18n.T("err.generic", err))
+				continue
+			}
+
+			fmt.Println("💡 El stake se bloquea al minar el bloque (opción 6)")
+
+		case "19":
+			// Guardar la cadena para compartirla o volver a cargarla con --import
+			fmt.Print("\n💾 Archivo de destino: ")
+			scanner.Scan()
+			path := strings.TrimSpace(scanner.Text())
+			if path == "" {
+				continue
+			}
+
+			if err := exportChainFile(bc, path); err != nil {
+				fmt.Println(i18n.T("err.generic", err))
+				continue
+			}
+			fmt.Printf("✅ %d bloques exportados a %s\n", len(bc.Blocks)-1, path)
+
+		case "20":
+			// Transacción firmada en otra parte (p. ej. en una máquina sin conexión)
+			fmt.Print("\n📦 Transacción raw (hex): ")
+			scanner.Scan()
+			hash, err := bc.SendRawTransaction(scanner.Text())
+			if err != nil {
+				fmt.Println(i18n.T("err.generic", err))
+				continue
+			}
+			fmt.Printf("🔑 Hash: %s\n", hash)
+
+		case "21":
+			// Resultado de una transacción ya incluida en un bloque
+			fmt.Print("\n🔑 Hash de la transacción: ")
+			scanner.Scan()
+			receipt, err := bc.GetReceipt(strings.TrimSpace(scanner.Text()))
+			if err != nil {
+				fmt.Println(i18n.T("err.generic", err))
+				continue
+			}
+			receipt.Print()
+
+		case "22":
+			// Transacciones confirmadas en las que aparece una dirección
+			fmt.Print("\n👤 Dirección: ")
+			scanner.Scan()
+			address := strings.TrimSpace(scanner.Text())
+			history := bc.GetAddressHistory(address)
+			if len(history) == 0 {
+				fmt.Println("📭 Sin transacciones confirmadas")
+				continue
+			}
+			fmt.Printf("\n📜 %d transacciones:\n", len(history))
+			for _, location := range history {
+				tx := bc.TransactionAt(location)
+				fmt.Printf("   Bloque #%d [%d] %s... %s MTC\n",
+					location.BlockNumber, location.TxIndex, tx.Hash()[:16], utils.FormatMTC(tx.Amount))
+			}
+
+		case "23":
+			// Cuenta m-de-n: su dirección se deriva de los titulares y el umbral
+			fmt.Println("\n👥 CREAR CUENTA MULTIFIRMA")
+			fmt.Println("\nCuentas disponibles:")
+			accounts := []string{}
+			for address := range wallet.KeyPairs {
+				accounts = append(accounts, address)
+			}
+			sort.Strings(accounts)
+			for i, address := range accounts {
+				fmt.Printf("%d. %s\n", i+1, address[:16]+"...")
+			}
+
+			fmt.Print("\n👤 Titulares (números separados por comas): ")
+			scanner.Scan()
+			indices, err := parseIndices(scanner.Text(), len(accounts))
+			if err != nil {
+				fmt.Println(i18n.T("err.invalidAccount"))
+				continue
+			}
+			owners := make([]string, len(indices))
+			for i, index := range indices {
+				owners[i] = accounts[index]
+			}
+
+			fmt.Print("✍️  Firmas necesarias: ")
+			scanner.Scan()
+			threshold, _ := strconv.Atoi(strings.TrimSpace(scanner.Text()))
+
+			multisig, err := blockchain.NewMultisig(threshold, owners)
+			if err != nil {
+				fmt.Println(i18n.T("err.generic", err))
+				continue
+			}
+			multisigs[multisig.Address()] = multisig
+
+			fmt.Printf("✅ Cuenta multifirma %d de %d: %s\n", multisig.Threshold, len(multisig.Owners), multisig.Address())
+			fmt.Println("💡 Envíale fondos con la opción 4 escribiendo su dirección como destinatario")
+
+		case "24":
+			// Transferencia desde una multifirma: firman los titulares que estén en este wallet
+			fmt.Println("\n👥 GASTAR DE UNA MULTIFIRMA")
+			if len(multisigs) == 0 {
+				fmt.Println("❌ No hay cuentas multifirma (créala con la opción 23)")
+				continue
+			}
+			addresses := []string{}
+			for address := range multisigs {
+				addresses = append(addresses, address)
+			}
+			sort.Strings(addresses)
+			for i, address := range addresses {
+				multisig := multisigs[address]
+				fmt.Printf("%d. %s (%d de %d, Balance: %s MTC)\n", i+1, address[:16]+"...",
+					multisig.Threshold, len(multisig.Owners), utils.FormatMTC(bc.GetBalance(address)))
+			}
+
+			fmt.Print("\n👥 Número de cuenta multifirma: ")
+			scanner.Scan()
+			from := parseAccount(scanner.Text(), addresses)
+			if multisigs[from] == nil {
+				fmt.Println(i18n.T("err.invalidAccount"))
+				continue
+			}
+			multisig := multisigs[from]
+
+			fmt.Print("👤 Dirección destinataria: ")
+			scanner.Scan()
+			to := strings.TrimSpace(scanner.Text())
+			if to == "" || to == from {
+				fmt.Println(i18n.T("err.invalidAccount"))
+				continue
+			}
+
+			fmt.Print("💰 Cantidad a enviar: ")
+			scanner.Scan()
+			amount, err := utils.ParseMTC(scanner.Text())
+			if err != nil || amount.Sign() <= 0 {
+				fmt.Println(i18n.T("err.invalidAmount"))
+				continue
+			}
+
+			tx := blockchain.NewMultisigTx(multisig, to, amount, bc.PendingNonce(from))
+			signMultisigFromWallet(tx, wallet, bc.ChainID, scanner)
+			submitMultisig(bc, tx)
+
+		case "25":
+			// Firma de un titular sobre una multifirma que otro preparó
+			fmt.Print("\n📦 Transacción multifirma raw (hex): ")
+			scanner.Scan()
+			tx, err := blockchain.DecodeRawTransactionHex(scanner.Text())
+			if err != nil {
+				fmt.Println(i18n.T("err.generic", err))
+				continue
+			}
+			if !tx.IsMultisig() {
+				fmt.Println("❌ No es una transacción multifirma")
+				continue
+			}
+
+			signMultisigFromWallet(tx, wallet, bc.ChainID, scanner)
+			submitMultisig(bc, tx)
+
 		default:
-			fmt.Println("\n❌ Opción inválida")
+			fmt.Println("\n" + i18n.T("menu.invalidOption"))
+		}
+	}
+}
+
+// menuItems son las claves del catálogo que forman el menú principal, en orden
+var menuItems = []string{
+	"menu.wallet", "menu.newAccount", "menu.state", "menu.newTx", "menu.pending",
+	"menu.mine", "menu.chain", "menu.verify",
+	"menu.contracts", "menu.deploy", "menu.listContracts", "menu.execute", "menu.contractState",
+	"menu.contractTxs", "menu.deployTx", "menu.callTx", "menu.batchTx", "menu.code", "menu.stakeTx",
+	"menu.chainSection", "menu.export", "menu.rawTx", "menu.receipt",
+	"menu.history",
+	"menu.multisig", "menu.msigNew", "menu.msigTx", "menu.msigSign",
+	"menu.exitSection", "menu.exit",
+}
+
+// printMenu muestra el menú principal en el idioma activo
+func printMenu() {
+	const width = 42 // Ancho interior de la caja
+	border := strings.Repeat("═", width)
+
+	title := i18n.T("menu.title")
+	padding := (width - utf8.RuneCountInString(title)) / 2
+
+	fmt.Println("\n╔" + border + "╗")
+	fmt.Printf("║%s%-*s║\n", strings.Repeat(" ", padding), width-padding, title)
+	fmt.Println("╠" + border + "╣")
+	for _, key := range menuItems {
+		fmt.Printf("║ %-*s ║\n", width-2, i18n.T(key))
+	}
+	fmt.Println("╚" + border + "╝")
+}
+
+// parseAccount interpreta la respuesta a "número de cuenta": un número de la
+// lista o una dirección escrita entera. Devuelve "" si no es ninguna de las dos.
+func parseAccount(input string, accounts []string) string {
+	input = strings.TrimSpace(input)
+	if index, err := strconv.Atoi(input); err == nil {
+		if index < 1 || index > len(accounts) {
+			return ""
+		}
+		return accounts[index-1]
+	}
+	if len(input) == 40 {
+		if _, err := hex.DecodeString(input); err == nil {
+			return input
 		}
 	}
+	return ""
+}
+
+// parseIndices lee números de lista separados por comas ("1,3") y los devuelve
+// como índices desde 0, sin repetir
+func parseIndices(input string, max int) ([]int, error) {
+	seen := make(map[int]bool)
+	var indices []int
+	for _, field := range strings.Split(input, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || n < 1 || n > max {
+			return nil, fmt.Errorf("número inválido: %q", field)
+		}
+		if !seen[n] {
+			seen[n] = true
+			indices = append(indices, n-1)
+		}
+	}
+	return indices, nil
+}
+
+// signMultisigFromWallet pregunta qué titulares del wallet firman la multifirma y firma con ellos
+func signMultisigFromWallet(tx *blockchain.Transaction, wallet *crypto.Wallet, chainID uint64, scanner *bufio.Scanner) {
+	var owners []string
+	for _, owner := range tx.Multisig.Owners {
+		if _, err := wallet.GetKeyPair(owner); err == nil {
+			owners = append(owners, owner)
+		}
+	}
+	if len(owners) == 0 {
+		fmt.Println("⚠️  Ningún titular de esta multifirma está en el wallet")
+		return
+	}
+
+	fmt.Println("\nTitulares en este wallet:")
+	for i, owner := range owners {
+		fmt.Printf("%d. %s\n", i+1, owner[:16]+"...")
+	}
+	fmt.Print("✍️  Firman (números separados por comas, Enter = ninguno): ")
+	scanner.Scan()
+	input := strings.TrimSpace(scanner.Text())
+	if input == "" {
+		return
+	}
+	indices, err := parseIndices(input, len(owners))
+	if err != nil {
+		fmt.Println(i18n.T("err.invalidAccount"))
+		return
+	}
+
+	for _, index := range indices {
+		keyPair, _ := wallet.GetKeyPair(owners[index])
+		if err := tx.SignMultisig(keyPair, chainID); err != nil {
+			fmt.Println(i18n.T("err.generic", err))
+			continue
+		}
+		fmt.Printf("✅ Firmada por %s\n", owners[index][:16]+"...")
+	}
+}
+
+// submitMultisig envía la multifirma si ya tiene las firmas necesarias
+// Si no, muestra el formato raw para que la firmen los demás titulares
+func submitMultisig(bc *blockchain.Blockchain, tx *blockchain.Transaction) {
+	tx.Print()
+
+	if missing := tx.MissingSignatures(); missing > 0 {
+		fmt.Printf("\n⏳ Faltan %d firmas. Pásales esta transacción a los demás titulares (opción 25):\n%s\n",
+			missing, tx.RawHex())
+		return
+	}
+
+	if err := bc.AddTransaction(tx); err != nil {
+		fmt.Println(i18n.T("err.generic", err))
+	}
 }
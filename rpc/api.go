@@ -0,0 +1,163 @@
+package rpc
+
+import (
+	"math/big"
+	"minichain/blockchain"
+	"minichain/i18n"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// rpcTransactionJSON es una transacción con dónde se confirmó
+// Los campos del bloque son null mientras espera en el mempool
+type rpcTransactionJSON struct {
+	*blockchain.TransactionJSON
+	BlockHash        *string `json:"blockHash"`
+	BlockNumber      *string `json:"blockNumber"`
+	TransactionIndex *string `json:"transactionIndex"`
+}
+
+// lookupTx busca una transacción en la cadena y, si no está, en el mempool
+// Devuelve nil si no se conoce
+func (s *Server) lookupTx(hash string) *rpcTransactionJSON {
+	if location, ok := s.bc.ReadTxLookupEntry(hash); ok {
+		block := s.bc.Blocks[location.BlockNumber]
+		number := hexUint(uint64(location.BlockNumber))
+		index := hexUint(uint64(location.TxIndex))
+		return &rpcTransactionJSON{
+			TransactionJSON:  s.bc.TransactionAt(location).ToAPI(),
+			BlockHash:        &block.Hash,
+			BlockNumber:      &number,
+			TransactionIndex: &index,
+		}
+	}
+	if tx, ok := s.bc.GetPendingTransaction(hash); ok {
+		return &rpcTransactionJSON{TransactionJSON: tx.ToAPI()}
+	}
+	return nil
+}
+
+// Estado de una transacción en /api/tx
+const (
+	txStatusPending = "pending"
+	txStatusSuccess = "success"
+	txStatusFailed  = "failed"
+)
+
+// txDetailsJSON es la respuesta de /api/tx/<hash>
+type txDetailsJSON struct {
+	Transaction   *rpcTransactionJSON     `json:"transaction"`
+	Receipt       *blockchain.ReceiptJSON `json:"receipt"` // null si está pendiente
+	Status        string                  `json:"status"`
+	Confirmations int                     `json:"confirmations"` // Bloques desde el suyo, incluido
+}
+
+// handleTx: GET /api/tx/<hash> -> transacción, recibo y confirmaciones
+func (s *Server) handleTx(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	hash := parseHash(strings.TrimPrefix(r.URL.Path, "/api/tx/"))
+	tx := s.lookupTx(hash)
+	if tx == nil {
+		writeError(w, http.StatusNotFound, &Error{Code: CodeUnknownTx, Message: i18n.T("rpc.unknownTx", hash)})
+		return
+	}
+
+	out := &txDetailsJSON{Transaction: tx, Status: txStatusPending}
+	if receipt, err := s.bc.GetReceipt(hash); err == nil {
+		out.Receipt = receipt.ToAPI()
+		out.Confirmations = len(s.bc.Blocks) - receipt.BlockNumber
+		out.Status = txStatusFailed
+		if receipt.Status == blockchain.ReceiptStatusSuccessful {
+			out.Status = txStatusSuccess
+		}
+	}
+	writeJSON(w, out)
+}
+
+// mempoolJSON es la respuesta de /api/mempool
+//
+// Pending son las que se pueden minar ya; Queued, las que esperan a que se
+// llene un hueco de nonce reservado (ver txpool_reserveNonce).
+type mempoolJSON struct {
+	Pending      []*blockchain.TransactionJSON `json:"pending"`
+	Queued       []*blockchain.TransactionJSON `json:"queued"`
+	PendingCount int                           `json:"pendingCount"`
+	QueuedCount  int                           `json:"queuedCount"`
+	TotalFees    string                        `json:"totalFees"` // Máximo que pagarían en gas (límite × precio)
+}
+
+// handleMempool: GET /api/mempool[?sender=ADDR] -> transacciones pendientes
+func (s *Server) handleMempool(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	sender := parseAddress(r.URL.Query().Get("sender"))
+	out := &mempoolJSON{
+		Pending: []*blockchain.TransactionJSON{},
+		Queued:  []*blockchain.TransactionJSON{},
+	}
+	totalFees := new(big.Int)
+
+	for _, tx := range s.bc.PendingTransactions() {
+		if sender != "" && tx.From != sender {
+			continue
+		}
+		out.Pending = append(out.Pending, tx.ToAPI())
+		totalFees.Add(totalFees, new(big.Int).Mul(new(big.Int).SetUint64(tx.GasLimit), tx.GasPrice))
+	}
+	for _, tx := range s.bc.QueuedTransactions() {
+		if sender != "" && tx.From != sender {
+			continue
+		}
+		out.Queued = append(out.Queued, tx.ToAPI())
+		totalFees.Add(totalFees, new(big.Int).Mul(new(big.Int).SetUint64(tx.GasLimit), tx.GasPrice))
+	}
+
+	out.PendingCount = len(out.Pending)
+	out.QueuedCount = len(out.Queued)
+	out.TotalFees = hexBig(totalFees)
+	writeJSON(w, out)
+}
+
+// handleBlock: GET /api/block/<altura|hash> -> bloque con los hashes de sus transacciones
+// La altura va en decimal (o en hex con 0x, o como etiqueta: latest...); el
+// hash, en 64 caracteres hex
+func (s *Server) handleBlock(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/block/")
+	if hash := parseHash(id); len(hash) == 64 {
+		block, ok := s.bc.GetBlockByHash(hash)
+		if !ok {
+			writeError(w, http.StatusNotFound, &Error{Code: CodeUnknownBlock, Message: i18n.T("rpc.unknownBlock", hash)})
+			return
+		}
+		writeJSON(w, blockToJSON(block, false))
+		return
+	}
+
+	tag := id
+	if number, err := strconv.ParseUint(id, 10, 63); err == nil {
+		tag = hexUint(number)
+	}
+	number, err := s.parseBlockNumber(tag)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, toError(err))
+		return
+	}
+	if number >= len(s.bc.Blocks) {
+		writeError(w, http.StatusNotFound, &Error{Code: CodeUnknownBlock, Message: i18n.T("rpc.unknownBlock", id)})
+		return
+	}
+	writeJSON(w, blockToJSON(s.bc.Blocks[number], false))
+}
@@ -0,0 +1,62 @@
+package rpc
+
+import (
+	"crypto/subtle"
+	"minichain/i18n"
+	"net/http"
+	"strings"
+)
+
+// authorized dice si la petición trae el token de AuthToken
+// Sin token configurado todas las peticiones están autorizadas
+func (s *Server) authorized(r *http.Request) bool {
+	if s.AuthToken == "" {
+		return true
+	}
+
+	token := r.Header.Get("X-API-Key")
+	if bearer := r.Header.Get("Authorization"); strings.HasPrefix(bearer, "Bearer ") {
+		token = strings.TrimPrefix(bearer, "Bearer ")
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.AuthToken)) == 1
+}
+
+// allowedOrigin dice si un navegador en origin puede llamar al servidor
+func (s *Server) allowedOrigin(origin string) bool {
+	for _, domain := range s.CORSDomains {
+		if domain = strings.TrimSpace(domain); domain == "*" || strings.EqualFold(domain, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// cors añade las cabeceras CORS y responde las preflight (OPTIONS)
+// Devuelve false si la petición ya está respondida
+//
+// Una petición desde un origen no permitido se rechaza con 403 aunque no sea
+// preflight: un POST "simple" (p. ej. text/plain) no la necesita, y sin esto se
+// ejecutaría igual; el navegador solo ocultaría la respuesta.
+func (s *Server) cors(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true // No viene de un navegador
+	}
+
+	w.Header().Add("Vary", "Origin")
+	if !s.allowedOrigin(origin) {
+		writeError(w, http.StatusForbidden, &Error{Code: CodeForbidden, Message: i18n.T("rpc.forbiddenOrigin", origin)})
+		return false
+	}
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	w.Header().Set("Access-Control-Expose-Headers", SchemaHeader) // Si no, el navegador no la deja leer
+
+	if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key")
+		w.Header().Set("Access-Control-Max-Age", "600")
+		w.WriteHeader(http.StatusNoContent)
+		return false
+	}
+	return true
+}
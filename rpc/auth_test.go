@@ -0,0 +1,123 @@
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"minichain/blockchain"
+)
+
+// newTestServer crea un servidor sobre una cadena nueva, sin límite de peticiones
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	bc, err := blockchain.NewBlockchainFromGenesis(&blockchain.Genesis{ChainID: 7, Difficulty: 1, Timestamp: 1_700_000_000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(bc)
+	s.RateLimit = 0
+	return s
+}
+
+// post envía una petición JSON-RPC con las cabeceras indicadas
+func post(s *Server, body string, headers map[string]string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		r.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	return w
+}
+
+// rpcError devuelve el error de una respuesta JSON-RPC (nil si no hay)
+func rpcError(t *testing.T, w *httptest.ResponseRecorder) *Error {
+	t.Helper()
+	var resp struct{ Error *Error }
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("respuesta %d no es JSON: %s", w.Code, w.Body)
+	}
+	return resp.Error
+}
+
+// TestAuthToken: con AuthToken, los métodos que cambian el estado exigen el
+// token (Bearer o X-API-Key); los de lectura no
+func TestAuthToken(t *testing.T) {
+	s := newTestServer(t)
+	s.AuthToken = "secreto"
+	reserve := `{"jsonrpc":"2.0","id":1,"method":"txpool_reserveNonce","params":["0x` + strings.Repeat("ab", 20) + `"]}`
+
+	rejected := map[string]map[string]string{
+		"sin token":     nil,
+		"token erróneo": {"Authorization": "Bearer otro"},
+		"sin Bearer":    {"Authorization": "secreto"},
+		"clave errónea": {"X-API-Key": "secret"},
+		"clave vacía":   {"X-API-Key": ""},
+	}
+	for name, headers := range rejected {
+		t.Run(name, func(t *testing.T) {
+			if err := rpcError(t, post(s, reserve, headers)); err == nil || err.Code != CodeUnauthorized {
+				t.Fatalf("error %+v, esperaba CodeUnauthorized", err)
+			}
+		})
+	}
+
+	for _, headers := range []map[string]string{{"Authorization": "Bearer secreto"}, {"X-API-Key": "secreto"}} {
+		if err := rpcError(t, post(s, reserve, headers)); err != nil {
+			t.Fatalf("con %v: %+v", headers, err)
+		}
+	}
+
+	read := `{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber","params":[]}`
+	if err := rpcError(t, post(s, read, nil)); err != nil {
+		t.Fatalf("lectura sin token: %+v", err)
+	}
+}
+
+// TestCORS: un origen que no está en CORSDomains recibe 403 sin que la
+// petición se ejecute; uno permitido recibe las cabeceras CORS
+func TestCORS(t *testing.T) {
+	s := newTestServer(t)
+	s.CORSDomains = []string{"https://wallet.example"}
+	reserve := `{"jsonrpc":"2.0","id":1,"method":"txpool_reserveNonce","params":["0x` + strings.Repeat("ab", 20) + `"]}`
+
+	// Un POST "simple" desde otro origen: el navegador no haría preflight
+	w := post(s, reserve, map[string]string{"Origin": "https://evil.example", "Content-Type": "text/plain"})
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("origen no permitido: %d, esperaba 403", w.Code)
+	}
+	var body Error
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Code != CodeForbidden {
+		t.Fatalf("cuerpo %s, esperaba CodeForbidden", w.Body)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Fatalf("Access-Control-Allow-Origin = %q en un origen rechazado", got)
+	}
+	if nonce := s.bc.ReserveNonce(strings.Repeat("ab", 20)); nonce != 0 {
+		t.Fatalf("la petición rechazada reservó un nonce (siguiente %d)", nonce)
+	}
+
+	// Preflight desde el origen permitido
+	r := httptest.NewRequest(http.MethodOptions, "/", nil)
+	r.Header.Set("Origin", "https://wallet.example")
+	r.Header.Set("Access-Control-Request-Method", "POST")
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Origin") != "https://wallet.example" {
+		t.Fatalf("preflight: %d, Allow-Origin %q", w.Code, w.Header().Get("Access-Control-Allow-Origin"))
+	}
+
+	w = post(s, `{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber","params":[]}`, map[string]string{"Origin": "https://wallet.example"})
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Expose-Headers") != SchemaHeader {
+		t.Fatalf("origen permitido: %d, Expose-Headers %q", w.Code, w.Header().Get("Access-Control-Expose-Headers"))
+	}
+
+	// Sin Origin no viene de un navegador: CORS no se aplica
+	if w := post(s, `{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber","params":[]}`, nil); w.Code != http.StatusOK {
+		t.Fatalf("sin Origin: %d", w.Code)
+	}
+}
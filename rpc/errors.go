@@ -0,0 +1,60 @@
+package rpc
+
+import (
+	"errors"
+	"minichain/blockchain"
+	"minichain/i18n"
+	"minichain/mempool"
+	"net/http"
+)
+
+// txErrorCodes asigna un código propio a los motivos de rechazo de una
+// transacción, para que un cliente pueda reaccionar sin leer el mensaje
+var txErrorCodes = []struct {
+	err  error
+	code int
+	key  string // Mensaje del catálogo de i18n
+}{
+	{blockchain.ErrNotSigned, CodeInvalidSignature, "rpc.notSigned"},
+	{blockchain.ErrInvalidSignature, CodeInvalidSignature, "rpc.invalidSignature"},
+	{blockchain.ErrNonceTooLow, CodeNonceTooLow, "rpc.nonceTooLow"},
+	{blockchain.ErrNonceTooHigh, CodeNonceTooHigh, "rpc.nonceTooHigh"},
+	{blockchain.ErrInsufficientFunds, CodeInsufficientFunds, "rpc.insufficientFunds"},
+	{mempool.ErrInsufficientFunds, CodeInsufficientFunds, "rpc.insufficientPending"},
+	{mempool.ErrAlreadyKnown, CodeAlreadyKnown, "rpc.alreadyKnown"},
+}
+
+// toError convierte un error de la cadena en un error de JSON-RPC
+// Los rechazos conocidos llevan el mensaje en el idioma del nodo y el detalle
+// de la cadena (nonce esperado, saldo...) en Data; el resto, el error tal cual.
+func toError(err error) *Error {
+	var rpcErr *Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr
+	}
+	for _, known := range txErrorCodes {
+		if errors.Is(err, known.err) {
+			return &Error{Code: known.code, Message: i18n.T(known.key), Data: err.Error()}
+		}
+	}
+	return &Error{Code: CodeServer, Message: err.Error()}
+}
+
+// writeError responde a una petición HTTP con el error en JSON
+// Es el mismo objeto {code, message, data} que va en "error" en JSON-RPC
+func writeError(w http.ResponseWriter, status int, err *Error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	writeJSON(w, err)
+}
+
+// methodNotAllowed responde 405 indicando los métodos HTTP admitidos
+func methodNotAllowed(w http.ResponseWriter, allow string) {
+	w.Header().Set("Allow", allow)
+	writeError(w, http.StatusMethodNotAllowed, &Error{Code: CodeInvalidRequest, Message: i18n.T("rpc.methodNotAllowed", allow)})
+}
+
+// notFound responde 404 a una ruta que no existe
+func notFound(w http.ResponseWriter, r *http.Request) {
+	writeError(w, http.StatusNotFound, &Error{Code: CodeMethodNotFound, Message: i18n.T("rpc.unknownRoute", r.URL.Path)})
+}
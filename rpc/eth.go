@@ -0,0 +1,577 @@
+package rpc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"minichain/blockchain"
+	"minichain/i18n"
+	"strconv"
+	"strings"
+)
+
+// ClientVersion es lo que devuelve web3_clientVersion
+const ClientVersion = "minichain/v2.0"
+
+// methods son los métodos que atiende el servidor
+var methods = map[string]handler{
+	"web3_clientVersion": func(s *Server, params []json.RawMessage) (interface{}, error) {
+		return ClientVersion, nil
+	},
+	"net_version": func(s *Server, params []json.RawMessage) (interface{}, error) {
+		return strconv.FormatUint(s.bc.ChainID, 10), nil
+	},
+	"net_listening": func(s *Server, params []json.RawMessage) (interface{}, error) {
+		return true, nil
+	},
+	"net_peerCount": func(s *Server, params []json.RawMessage) (interface{}, error) {
+		return hexUint(0), nil // El nodo no tiene red P2P: no hay pares
+	},
+	"eth_chainId": func(s *Server, params []json.RawMessage) (interface{}, error) {
+		return hexUint(s.bc.ChainID), nil
+	},
+	"eth_blockNumber": func(s *Server, params []json.RawMessage) (interface{}, error) {
+		return hexUint(uint64(len(s.bc.Blocks) - 1)), nil
+	},
+	"eth_gasPrice": func(s *Server, params []json.RawMessage) (interface{}, error) {
+		return hexBig(s.bc.SuggestGasPrice()), nil
+	},
+	"eth_maxPriorityFeePerGas": func(s *Server, params []json.RawMessage) (interface{}, error) {
+		return hexBig(s.bc.SuggestGasTipCap()), nil
+	},
+	"eth_getBalance":            ethGetBalance,
+	"eth_getTransactionCount":   ethGetTransactionCount,
+	"eth_getCode":               ethGetCode,
+	"eth_getBlockByNumber":      ethGetBlockByNumber,
+	"eth_getBlockByHash":        ethGetBlockByHash,
+	"eth_getTransactionByHash":  ethGetTransactionByHash,
+	"eth_getTransactionReceipt": ethGetTransactionReceipt,
+	"eth_sendRawTransaction":    ethSendRawTransaction,
+	"eth_call":                  ethCall,
+	"eth_getLogs":               ethGetLogs,
+	"eth_getProof":              ethGetProof,
+	"eth_getWork":               ethGetWork,
+	"eth_submitWork":            ethSubmitWork,
+	"eth_hashrate": func(s *Server, params []json.RawMessage) (interface{}, error) {
+		return hexUint(uint64(s.bc.Hashrate())), nil
+	},
+	"eth_syncing": ethSyncing,
+	"eth_mining": func(s *Server, params []json.RawMessage) (interface{}, error) {
+		return s.Mining(), nil
+	},
+	"miner_start":         minerStart,
+	"miner_stop":          minerStop,
+	"miner_setCoinbase":   minerSetCoinbase,
+	"miner_setGasLimit":   minerSetGasLimit,
+	"txpool_reserveNonce": txpoolReserveNonce,
+	"txpool_releaseNonce": txpoolReleaseNonce,
+	"txpool_dropped":      txpoolDropped,
+	"admin_nodeInfo":      adminNodeInfo,
+	"admin_peers": func(s *Server, params []json.RawMessage) (interface{}, error) {
+		return []interface{}{}, nil // Sin red P2P (ver net_peerCount)
+	},
+}
+
+// mutating son los métodos que cambian el estado del nodo: con AuthToken
+// configurado solo se atienden si la petición trae el token
+var mutating = map[string]bool{
+	"eth_sendRawTransaction": true,
+	"eth_getWork":            true, // Ejecuta y guarda un bloque candidato
+	"eth_submitWork":         true,
+	"miner_start":            true,
+	"miner_stop":             true,
+	"miner_setCoinbase":      true,
+	"miner_setGasLimit":      true,
+	"txpool_reserveNonce":    true,
+	"txpool_releaseNonce":    true,
+}
+
+// unlocked son los métodos que no leen la cadena y se atienden sin esperar a
+// quien tenga el candado (p. ej. durante una importación)
+var unlocked = map[string]bool{
+	"eth_syncing": true,
+}
+
+// hexUint codifica un número en el formato "0x..." de Ethereum
+func hexUint(n uint64) string {
+	return "0x" + strconv.FormatUint(n, 16)
+}
+
+// hexBig codifica una cantidad en hexadecimal ("0x0" si es nil)
+func hexBig(n *big.Int) string {
+	if n == nil {
+		return "0x0"
+	}
+	return "0x" + n.Text(16)
+}
+
+// parseAddress normaliza una dirección: se admite con o sin 0x
+func parseAddress(address string) string {
+	return strings.ToLower(strings.TrimPrefix(address, "0x"))
+}
+
+// parseHash normaliza un hash de bloque o transacción: se admite con o sin 0x
+func parseHash(hash string) string {
+	return strings.ToLower(strings.TrimPrefix(hash, "0x"))
+}
+
+// parseBlockNumber interpreta un número de bloque o una etiqueta
+// Devuelve la altura pedida, que puede ser mayor que la de la cabeza
+func (s *Server) parseBlockNumber(tag string) (int, error) {
+	head := len(s.bc.Blocks) - 1
+	switch tag {
+	case "", "latest", "pending", "safe", "finalized":
+		return head, nil
+	case "earliest":
+		return 0, nil
+	}
+
+	if !strings.HasPrefix(tag, "0x") {
+		return 0, invalidParams("rpc.blockNumberFormat", tag)
+	}
+	number, err := strconv.ParseUint(tag[2:], 16, 63)
+	if err != nil {
+		return 0, invalidParams("rpc.invalidBlockNumber", tag)
+	}
+	if number > uint64(head) {
+		return head + 1, nil // No existe (todavía)
+	}
+	return int(number), nil
+}
+
+// checkLatestState comprueba que se pide el estado de la cabeza
+// Solo se guarda el estado actual: no se pueden consultar bloques anteriores
+func (s *Server) checkLatestState(tag string) error {
+	number, err := s.parseBlockNumber(tag)
+	if err != nil {
+		return err
+	}
+	if number != len(s.bc.Blocks)-1 {
+		return &Error{Code: CodeServer, Message: i18n.T("rpc.latestStateOnly")}
+	}
+	return nil
+}
+
+// ethGetBalance: [dirección, bloque] -> saldo en unidades base
+func ethGetBalance(s *Server, params []json.RawMessage) (interface{}, error) {
+	var address, tag string
+	if err := decodeParams(params, 1, &address, &tag); err != nil {
+		return nil, err
+	}
+	if err := s.checkLatestState(tag); err != nil {
+		return nil, err
+	}
+	return hexBig(s.bc.GetBalance(parseAddress(address))), nil
+}
+
+// ethGetTransactionCount: [dirección, bloque] -> nonce
+// Con "pending" cuenta también las transacciones que esperan en el mempool
+func ethGetTransactionCount(s *Server, params []json.RawMessage) (interface{}, error) {
+	var address, tag string
+	if err := decodeParams(params, 1, &address, &tag); err != nil {
+		return nil, err
+	}
+	if tag == "pending" {
+		return hexUint(uint64(s.bc.PendingNonce(parseAddress(address)))), nil
+	}
+	if err := s.checkLatestState(tag); err != nil {
+		return nil, err
+	}
+	return hexUint(uint64(s.bc.GetNonce(parseAddress(address)))), nil
+}
+
+// ethGetCode: [dirección, bloque] -> bytecode ("0x" si no es un contrato)
+func ethGetCode(s *Server, params []json.RawMessage) (interface{}, error) {
+	var address, tag string
+	if err := decodeParams(params, 1, &address, &tag); err != nil {
+		return nil, err
+	}
+	if err := s.checkLatestState(tag); err != nil {
+		return nil, err
+	}
+	code, err := s.bc.GetCode(parseAddress(address))
+	if err != nil {
+		return "0x", nil
+	}
+	return "0x" + hex.EncodeToString(code), nil
+}
+
+// blockWithHashes es un bloque con solo los hashes de sus transacciones
+// El campo Transactions tapa al de BlockJSON al serializar
+type blockWithHashes struct {
+	*blockchain.BlockJSON
+	Transactions []string `json:"transactions"`
+}
+
+// ethGetBlockByNumber: [bloque, transacciones completas] -> bloque (null si no existe)
+func ethGetBlockByNumber(s *Server, params []json.RawMessage) (interface{}, error) {
+	var tag string
+	var full bool
+	if err := decodeParams(params, 1, &tag, &full); err != nil {
+		return nil, err
+	}
+	number, err := s.parseBlockNumber(tag)
+	if err != nil {
+		return nil, err
+	}
+	if number >= len(s.bc.Blocks) {
+		return nil, nil
+	}
+
+	return blockToJSON(s.bc.Blocks[number], full), nil
+}
+
+// ethGetBlockByHash: [hash, transacciones completas] -> bloque (null si no existe)
+func ethGetBlockByHash(s *Server, params []json.RawMessage) (interface{}, error) {
+	var hash string
+	var full bool
+	if err := decodeParams(params, 1, &hash, &full); err != nil {
+		return nil, err
+	}
+	block, ok := s.bc.GetBlockByHash(parseHash(hash))
+	if !ok {
+		return nil, nil
+	}
+	return blockToJSON(block, full), nil
+}
+
+// blockToJSON convierte un bloque con las transacciones completas o solo sus hashes
+func blockToJSON(block *blockchain.Block, full bool) interface{} {
+	if full {
+		return block.ToAPI()
+	}
+
+	out := &blockWithHashes{BlockJSON: block.ToAPI(), Transactions: []string{}}
+	for _, tx := range block.Transactions {
+		out.Transactions = append(out.Transactions, tx.Hash())
+	}
+	return out
+}
+
+// ethGetTransactionByHash: [hash] -> transacción confirmada o pendiente (null si no se conoce)
+func ethGetTransactionByHash(s *Server, params []json.RawMessage) (interface{}, error) {
+	var hash string
+	if err := decodeParams(params, 1, &hash); err != nil {
+		return nil, err
+	}
+	if tx := s.lookupTx(parseHash(hash)); tx != nil {
+		return tx, nil
+	}
+	return nil, nil
+}
+
+// ethGetTransactionReceipt: [hash] -> recibo (null si no está en la cadena)
+func ethGetTransactionReceipt(s *Server, params []json.RawMessage) (interface{}, error) {
+	var hash string
+	if err := decodeParams(params, 1, &hash); err != nil {
+		return nil, err
+	}
+	receipt, err := s.bc.GetReceipt(parseHash(hash))
+	if err != nil {
+		return nil, nil
+	}
+	return receipt.ToAPI(), nil
+}
+
+// ethSendRawTransaction: [transacción raw en hex] -> hash
+func ethSendRawTransaction(s *Server, params []json.RawMessage) (interface{}, error) {
+	var raw string
+	if err := decodeParams(params, 1, &raw); err != nil {
+		return nil, err
+	}
+	return s.bc.SendRawTransaction(raw)
+}
+
+// callArgs es la llamada de eth_call (los campos que se usan de una transacción)
+type callArgs struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Gas   string `json:"gas"`
+	Data  string `json:"data"`
+	Input string `json:"input"` // Sinónimo de data que usan algunos clientes
+}
+
+// ethCall: [llamada, bloque] -> datos devueltos por el contrato
+// Se ejecuta sobre el estado actual y se deshace: no crea transacción ni gasta nada
+// El gas por defecto y el máximo es el límite de gas del bloque
+func ethCall(s *Server, params []json.RawMessage) (interface{}, error) {
+	var args callArgs
+	var tag string
+	if err := decodeParams(params, 1, &args, &tag); err != nil {
+		return nil, err
+	}
+	if err := s.checkLatestState(tag); err != nil {
+		return nil, err
+	}
+
+	input := args.Data
+	if input == "" {
+		input = args.Input
+	}
+	calldata, err := hex.DecodeString(strings.TrimPrefix(input, "0x"))
+	if err != nil {
+		return nil, invalidParams("rpc.invalidData", err)
+	}
+
+	gas := s.bc.GasLimit
+	if args.Gas != "" {
+		if gas, err = strconv.ParseUint(strings.TrimPrefix(args.Gas, "0x"), 16, 64); err != nil {
+			return nil, invalidParams("rpc.invalidGas", args.Gas)
+		}
+	}
+	// Como una transacción, no puede gastar más que un bloque entero
+	if gas > s.bc.GasLimit {
+		return nil, invalidParams("rpc.gasAboveLimit", gas, s.bc.GasLimit)
+	}
+
+	result, err := s.bc.Call(parseAddress(args.From), parseAddress(args.To), calldata, gas)
+	if err != nil {
+		return "0x", nil // Sin contrato en la dirección no hay nada que ejecutar
+	}
+	if result.RevertReason != "" {
+		rpcErr := &Error{Code: CodeExecution, Message: result.RevertReason}
+		if result.ReturnData != nil {
+			rpcErr.Data = "0x" + hex.EncodeToString(result.ReturnData) // Los datos del REVERT, para decodificarlos
+		}
+		return nil, rpcErr
+	}
+	return "0x" + hex.EncodeToString(result.ReturnData), nil
+}
+
+// filterArgs es el filtro de eth_getLogs
+type filterArgs struct {
+	FromBlock string            `json:"fromBlock"`
+	ToBlock   string            `json:"toBlock"`
+	Address   json.RawMessage   `json:"address"` // Una dirección o una lista
+	Topics    []json.RawMessage `json:"topics"`  // Por posición: null, un topic o una lista
+	BlockHash string            `json:"blockHash"`
+}
+
+// stringOrList decodifica un valor que puede ser null, una cadena o una lista de cadenas
+func stringOrList(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return []string{single}, nil
+	}
+	var list []string
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// ethGetLogs: [filtro] -> logs que coinciden, en orden de la cadena
+func ethGetLogs(s *Server, params []json.RawMessage) (interface{}, error) {
+	var args filterArgs
+	if err := decodeParams(params, 1, &args); err != nil {
+		return nil, err
+	}
+
+	var query blockchain.FilterQuery
+	var err error
+	if args.BlockHash != "" {
+		// Un solo bloque: excluye fromBlock y toBlock
+		if args.FromBlock != "" || args.ToBlock != "" {
+			return nil, invalidParams("rpc.blockHashWithRange")
+		}
+		number, ok := s.bc.ReadHeaderNumber(parseHash(args.BlockHash))
+		if !ok {
+			return nil, &Error{Code: CodeUnknownBlock, Message: i18n.T("rpc.unknownBlock", args.BlockHash)}
+		}
+		query.FromBlock, query.ToBlock = number, number
+	} else {
+		if query.FromBlock, err = s.parseBlockNumber(args.FromBlock); err != nil {
+			return nil, err
+		}
+		if query.ToBlock, err = s.parseBlockNumber(args.ToBlock); err != nil {
+			return nil, err
+		}
+	}
+
+	addresses, err := stringOrList(args.Address)
+	if err != nil {
+		return nil, invalidParams("rpc.invalidAddressList", err)
+	}
+	for _, address := range addresses {
+		query.Addresses = append(query.Addresses, parseAddress(address))
+	}
+	for i, raw := range args.Topics {
+		topics, err := stringOrList(raw)
+		if err != nil {
+			return nil, invalidParams("rpc.invalidTopics", i, err)
+		}
+		// Los logs guardan los topics en minúsculas con 0x
+		for j, topic := range topics {
+			topics[j] = "0x" + parseHash(topic)
+		}
+		query.Topics = append(query.Topics, topics)
+	}
+
+	logs, err := s.bc.FilterLogs(query)
+	if err != nil {
+		return nil, &Error{Code: CodeInvalidParams, Message: err.Error()}
+	}
+
+	out := make([]*blockchain.LogJSON, len(logs))
+	for i, log := range logs {
+		out[i] = log.ToAPI()
+	}
+	return out, nil
+}
+
+// leafProofJSON es una prueba de Merkle de una hoja del estado
+type leafProofJSON struct {
+	Leaf     string   `json:"leaf"`
+	Index    string   `json:"index"`
+	Siblings []string `json:"siblings"`
+}
+
+// storageProofJSON es el valor de una clave de storage y su prueba
+type storageProofJSON struct {
+	Key   string         `json:"key"`
+	Value string         `json:"value"`
+	Proof *leafProofJSON `json:"proof"` // null si el valor es 0
+}
+
+// proofJSON es la respuesta de eth_getProof
+type proofJSON struct {
+	Address      string              `json:"address"`
+	Balance      string              `json:"balance"`
+	Nonce        string              `json:"nonce"`
+	StateRoot    string              `json:"stateRoot"`
+	AccountProof *leafProofJSON      `json:"accountProof"` // null si la cuenta está vacía
+	CodeProof    *leafProofJSON      `json:"codeProof,omitempty"`
+	StorageProof []*storageProofJSON `json:"storageProof"`
+}
+
+// toLeafProofJSON convierte una prueba (nil se queda en nil)
+func toLeafProofJSON(p *blockchain.LeafProof) *leafProofJSON {
+	if p == nil {
+		return nil
+	}
+	out := &leafProofJSON{
+		Leaf:     "0x" + hex.EncodeToString(p.Leaf),
+		Index:    hexUint(uint64(p.Index)),
+		Siblings: make([]string, len(p.Siblings)),
+	}
+	for i, sibling := range p.Siblings {
+		out.Siblings[i] = "0x" + hex.EncodeToString(sibling)
+	}
+	return out
+}
+
+// ethGetProof: [dirección, claves de storage, bloque] -> pruebas de Merkle
+func ethGetProof(s *Server, params []json.RawMessage) (interface{}, error) {
+	var address, tag string
+	var keys []string
+	if err := decodeParams(params, 1, &address, &keys, &tag); err != nil {
+		return nil, err
+	}
+	if err := s.checkLatestState(tag); err != nil {
+		return nil, err
+	}
+
+	storageKeys := make([]*big.Int, len(keys))
+	for i, key := range keys {
+		value, ok := new(big.Int).SetString(strings.TrimPrefix(key, "0x"), 16)
+		if !ok || value.Sign() < 0 {
+			return nil, invalidParams("rpc.invalidStorageKey", key)
+		}
+		storageKeys[i] = value
+	}
+
+	proof, err := s.bc.GetProof(parseAddress(address), storageKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &proofJSON{
+		Address:      proof.Address,
+		Balance:      hexBig(proof.Balance),
+		Nonce:        hexUint(uint64(proof.Nonce)),
+		StateRoot:    proof.StateRoot,
+		AccountProof: toLeafProofJSON(proof.AccountProof),
+		CodeProof:    toLeafProofJSON(proof.CodeProof),
+		StorageProof: []*storageProofJSON{},
+	}
+	for _, sp := range proof.StorageProofs {
+		out.StorageProof = append(out.StorageProof, &storageProofJSON{
+			Key:   hexBig(sp.Key),
+			Value: hexBig(sp.Value),
+			Proof: toLeafProofJSON(sp.Proof),
+		})
+	}
+	return out, nil
+}
+
+// nodeInfoJSON es la respuesta de admin_nodeInfo
+type nodeInfoJSON struct {
+	Name      string `json:"name"`
+	ChainID   string `json:"chainId"`
+	Genesis   string `json:"genesis"`
+	Head      string `json:"head"`
+	Number    string `json:"number"`
+	Consensus string `json:"consensus"`
+	Coinbase  string `json:"coinbase"`
+	Pending   int    `json:"pending"`
+}
+
+// adminNodeInfo: [] -> identidad del nodo y de su cadena
+func adminNodeInfo(s *Server, params []json.RawMessage) (interface{}, error) {
+	head := s.bc.Blocks[len(s.bc.Blocks)-1]
+	return &nodeInfoJSON{
+		Name:      ClientVersion,
+		ChainID:   hexUint(s.bc.ChainID),
+		Genesis:   s.bc.Blocks[0].Hash,
+		Head:      head.Hash,
+		Number:    hexUint(uint64(head.Index)),
+		Consensus: s.bc.Engine.Name(),
+		Coinbase:  s.bc.Coinbase,
+		Pending:   s.bc.Mempool.Len(),
+	}, nil
+}
+
+// workJSON es un trabajo para un minero externo (ver blockchain.Work)
+type workJSON struct {
+	ID     string `json:"id"`
+	Number string `json:"number"`
+	Header string `json:"header"`
+	Target string `json:"target"`
+}
+
+// ethGetWork: [] -> bloque candidato para minar fuera del nodo
+func ethGetWork(s *Server, params []json.RawMessage) (interface{}, error) {
+	work, err := s.bc.GetWork()
+	if err != nil {
+		return nil, err
+	}
+	return &workJSON{
+		ID:     work.ID,
+		Number: hexUint(uint64(work.Index)),
+		Header: work.Header,
+		Target: "0x" + work.Target,
+	}, nil
+}
+
+// ethSubmitWork: [id del trabajo, nonce] -> true si el bloque entró en la cadena
+func ethSubmitWork(s *Server, params []json.RawMessage) (interface{}, error) {
+	var id, nonce string
+	if err := decodeParams(params, 2, &id, &nonce); err != nil {
+		return nil, err
+	}
+	value, err := strconv.ParseInt(strings.TrimPrefix(nonce, "0x"), 16, 64)
+	if err != nil || value < 0 || uint64(value) > uint64(maxInt) {
+		return nil, invalidParams("rpc.invalidNonce", nonce)
+	}
+
+	if _, err := s.bc.SubmitWork(id, int(value)); err != nil {
+		return nil, err
+	}
+	return true, nil
+}
+
+// maxInt es el mayor int de la plataforma
+const maxInt = int(^uint(0) >> 1)
@@ -0,0 +1,37 @@
+package rpc
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultExpireInterval es cada cuánto se buscan pendientes caducadas en el mempool
+const DefaultExpireInterval = time.Minute
+
+// StartExpiry descarta periódicamente las transacciones del mempool que
+// superaron su vida máxima (Lifetime), aunque no se mine ningún bloque
+// Las descartadas se consultan con txpool_dropped.
+func (s *Server) StartExpiry() {
+	interval := s.ExpireInterval
+	if interval <= 0 {
+		interval = DefaultExpireInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			s.expireOnce()
+		}
+	}()
+}
+
+// expireOnce hace una pasada con el candado de la cadena tomado
+func (s *Server) expireOnce() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if dropped := s.bc.Mempool.Expire(); dropped > 0 {
+		fmt.Printf("🗑️  %d transacciones pendientes caducadas o ya no válidas se descartan\n", dropped)
+	}
+}
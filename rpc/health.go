@@ -0,0 +1,86 @@
+package rpc
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// DefaultMaxSyncLag son los bloques que el nodo puede ir por detrás del más
+// alto conocido y seguir contando como listo
+const DefaultMaxSyncLag = 2
+
+// healthCheck es una comprobación de /health
+type healthCheck struct {
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail"`
+}
+
+// healthJSON es la respuesta de /health
+type healthJSON struct {
+	Live   bool                    `json:"live"`
+	Ready  bool                    `json:"ready"`
+	Checks map[string]*healthCheck `json:"checks,omitempty"`
+}
+
+// handleLive: GET /health/live -> 200 mientras el proceso atienda peticiones
+func (s *Server) handleLive(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		methodNotAllowed(w, "GET, HEAD")
+		return
+	}
+	writeJSON(w, &healthJSON{Live: true, Ready: s.ready()})
+}
+
+// handleHealth: GET /health -> comprobaciones de disponibilidad; 503 si el
+// nodo no está listo para atender (p. ej. a mitad de una importación)
+//
+// No toma el candado de la cadena, así que responde aunque el nodo esté
+// ocupado. El nodo no tiene base de datos (el estado vive en memoria) ni red
+// P2P, así que no hay nada que comprobar de ellas: solo se mira la
+// sincronización.
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/health" {
+		notFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		methodNotAllowed(w, "GET, HEAD")
+		return
+	}
+
+	checks := map[string]*healthCheck{"sync": s.checkSync()}
+	out := &healthJSON{Live: true, Ready: true, Checks: checks}
+	for _, check := range checks {
+		out.Ready = out.Ready && check.OK
+	}
+
+	if !out.Ready {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	writeJSON(w, out)
+}
+
+// ready dice si pasan todas las comprobaciones de /health
+func (s *Server) ready() bool {
+	return s.checkSync().OK
+}
+
+// checkSync comprueba que la cabeza está a menos de MaxSyncLag bloques del
+// más alto conocido
+func (s *Server) checkSync() *healthCheck {
+	progress := s.bc.SyncProgress()
+	lag := progress.HighestBlock - progress.CurrentBlock
+
+	check := &healthCheck{OK: lag <= s.MaxSyncLag}
+	switch {
+	case !progress.Syncing:
+		check.Detail = fmt.Sprintf("cabeza #%d", progress.CurrentBlock)
+	case check.OK:
+		check.Detail = fmt.Sprintf("importando: #%d de #%d", progress.CurrentBlock, progress.HighestBlock)
+	default:
+		check.Detail = fmt.Sprintf("importando: #%d de #%d (%d por detrás, máximo %d)",
+			progress.CurrentBlock, progress.HighestBlock, lag, s.MaxSyncLag)
+	}
+	return check
+}
@@ -0,0 +1,104 @@
+package rpc
+
+import (
+	"minichain/i18n"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Límites por defecto del servidor
+const (
+	DefaultMaxBodyBytes = 5 * 1024 * 1024 // Cabe un lote de transacciones raw que llene el mempool
+	DefaultMaxBatchSize = 100             // Peticiones por lote JSON-RPC
+	DefaultRateLimit    = 50              // Peticiones por segundo por IP
+	DefaultRateBurst    = 100             // Peticiones seguidas antes de aplicar el ritmo
+)
+
+// limiterSweep es cada cuánto se olvidan las IPs que ya no llaman
+const limiterSweep = time.Minute
+
+// rateLimiter reparte peticiones por IP con un cubo de fichas por cada una
+//
+// Cada IP empieza con burst fichas, gasta una por petición y recupera rate por
+// segundo hasta volver a burst. Sin fichas la petición se rechaza.
+type rateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// bucket son las fichas de una IP
+type bucket struct {
+	tokens float64
+	last   time.Time // Última vez que se recargó
+}
+
+// allow gasta una ficha de ip si le queda alguna
+func (l *rateLimiter) allow(ip string, rate float64, burst int, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.buckets == nil {
+		l.buckets = make(map[string]*bucket)
+	}
+	l.sweep(rate, burst, now)
+
+	b, exists := l.buckets[ip]
+	if !exists {
+		b = &bucket{tokens: float64(burst), last: now}
+		l.buckets[ip] = b
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * rate
+	if b.tokens > float64(burst) {
+		b.tokens = float64(burst)
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// sweep olvida las IPs con el cubo ya lleno: es como si no hubieran llamado nunca
+func (l *rateLimiter) sweep(rate float64, burst int, now time.Time) {
+	if now.Sub(l.lastSweep) < limiterSweep {
+		return
+	}
+	l.lastSweep = now
+
+	for ip, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*rate >= float64(burst) {
+			delete(l.buckets, ip)
+		}
+	}
+}
+
+// clientIP es la IP de quien hace la petición
+// No se mira X-Forwarded-For: cualquiera podría falsearla para saltarse el límite
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// limit aplica el límite de ritmo por IP y el de tamaño del cuerpo
+// Devuelve false si la petición ya está respondida
+func (s *Server) limit(w http.ResponseWriter, r *http.Request) bool {
+	if s.RateLimit > 0 && !s.limiter.allow(clientIP(r), s.RateLimit, s.RateBurst, time.Now()) {
+		w.Header().Set("Retry-After", "1")
+		writeError(w, http.StatusTooManyRequests, &Error{Code: CodeLimitExceeded, Message: i18n.T("rpc.tooManyRequests")})
+		return false
+	}
+
+	if s.MaxBodyBytes > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, s.MaxBodyBytes)
+	}
+	return true
+}
@@ -0,0 +1,174 @@
+package rpc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultMineInterval es cada cuánto mira el minero si hay transacciones pendientes
+const DefaultMineInterval = time.Second
+
+// StartMining arranca el minero del nodo: mientras esté en marcha, cada
+// MineInterval mina un bloque si hay transacciones pendientes
+//
+// Minar toma el candado de la cadena, así que las peticiones esperan a que se
+// selle el bloque. Sin transacciones no se mina (la cadena no avanza en vacío).
+// Devuelve false si ya estaba minando.
+func (s *Server) StartMining() bool {
+	s.minerMu.Lock()
+	defer s.minerMu.Unlock()
+	if s.stopMining != nil {
+		return false
+	}
+
+	stop := make(chan struct{})
+	s.stopMining = stop
+	go s.mineLoop(stop)
+	return true
+}
+
+// StopMining para el minero del nodo (el bloque en curso se termina)
+// Devuelve false si no estaba minando.
+func (s *Server) StopMining() bool {
+	s.minerMu.Lock()
+	defer s.minerMu.Unlock()
+	if s.stopMining == nil {
+		return false
+	}
+
+	close(s.stopMining)
+	s.stopMining = nil
+	return true
+}
+
+// Mining dice si el minero del nodo está en marcha
+func (s *Server) Mining() bool {
+	s.minerMu.Lock()
+	defer s.minerMu.Unlock()
+	return s.stopMining != nil
+}
+
+// mineLoop mina bloques hasta que se cierra stop o falla la producción de un
+// bloque; en ese caso el minero se para y el servidor sigue atendiendo
+func (s *Server) mineLoop(stop chan struct{}) {
+	interval := s.MineInterval
+	if interval <= 0 {
+		interval = DefaultMineInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.mineOnce(); err != nil {
+			fmt.Printf("\n❌ Minero detenido: %v\n", err)
+			s.minerMu.Lock()
+			if s.stopMining == stop { // No parar un minero arrancado después
+				close(stop)
+				s.stopMining = nil
+			}
+			s.minerMu.Unlock()
+			return
+		}
+	}
+}
+
+// mineOnce mina un bloque si hay transacciones pendientes
+//
+// Un panic al producir el bloque (p. ej. un fallo en la EVM) se devuelve como
+// error: en una goroutine sin recover tiraría el proceso entero, servidor HTTP
+// incluido.
+func (s *Server) mineOnce() (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic al producir el bloque: %v", r)
+		}
+	}()
+
+	if len(s.bc.Mempool.Pending()) > 0 {
+		s.bc.MineBlock()
+	}
+	return nil
+}
+
+// minerStart: [hilos] -> true; hilos (opcional) son las goroutines que buscan
+// el nonce en proof of work
+func minerStart(s *Server, params []json.RawMessage) (interface{}, error) {
+	var threads *int
+	if err := decodeParams(params, 0, &threads); err != nil {
+		return nil, err
+	}
+	if threads != nil {
+		if *threads < 1 {
+			return nil, invalidParams("rpc.invalidThreads", *threads)
+		}
+		s.bc.MinerThreads = *threads
+	}
+
+	s.StartMining()
+	return true, nil
+}
+
+// minerStop: [] -> true
+func minerStop(s *Server, params []json.RawMessage) (interface{}, error) {
+	if err := decodeParams(params, 0); err != nil {
+		return nil, err
+	}
+	s.StopMining()
+	return true, nil
+}
+
+// minerSetCoinbase: [dirección] -> true; "" deja los bloques sin recompensa
+func minerSetCoinbase(s *Server, params []json.RawMessage) (interface{}, error) {
+	var address string
+	if err := decodeParams(params, 1, &address); err != nil {
+		return nil, err
+	}
+
+	address = parseAddress(address)
+	if address != "" {
+		if _, err := hex.DecodeString(address); err != nil || len(address) != 40 {
+			return nil, invalidParams("rpc.invalidAddress", address)
+		}
+	}
+
+	s.bc.Coinbase = address
+	return true, nil
+}
+
+// minerSetGasLimit: [gas] -> true; gas máximo que el minero mete en cada bloque
+//
+// Es una preferencia local: no puede superar el límite de la red (el del
+// génesis), que es regla de consenso y fija el objetivo del base fee. "0x0"
+// vuelve a llenar los bloques hasta ese límite.
+func minerSetGasLimit(s *Server, params []json.RawMessage) (interface{}, error) {
+	var quantity string
+	if err := decodeParams(params, 1, &quantity); err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(quantity, "0x") {
+		return nil, invalidParams("rpc.gasFormat", quantity)
+	}
+	gas, err := strconv.ParseUint(quantity[2:], 16, 64)
+	if err != nil {
+		return nil, invalidParams("rpc.invalidGas", quantity)
+	}
+	if gas > s.bc.GasLimit {
+		return nil, invalidParams("rpc.gasAboveLimit", gas, s.bc.GasLimit)
+	}
+
+	s.bc.MinerGasLimit = gas
+	return true, nil
+}
@@ -0,0 +1,294 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"minichain/blockchain"
+	"minichain/i18n"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Códigos de error de JSON-RPC 2.0
+const (
+	CodeParseError     = -32700 // El cuerpo no es JSON
+	CodeInvalidRequest = -32600 // JSON válido pero no es una petición
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternal       = -32603
+	CodeServer         = -32000 // La cadena rechazó la operación (ver Message)
+	CodeUnauthorized   = -32001 // Falta el token para un método que cambia el estado
+	CodeExecution      = 3      // La ejecución del contrato falló (como en geth)
+
+	// Rechazos de una transacción (ver toError)
+	CodeInvalidSignature  = -32002 // Sin firma o con una firma que no corresponde
+	CodeNonceTooLow       = -32003 // El nonce ya se usó
+	CodeNonceTooHigh      = -32004 // Hay un hueco antes de este nonce
+	CodeInsufficientFunds = -32005 // El saldo no cubre importe más gas
+	CodeAlreadyKnown      = -32006 // Ya está en el mempool o en la cadena
+
+	// Errores de la API HTTP
+	CodeUnknownBlock  = -32010 // El bloque pedido no existe
+	CodeUnknownTx     = -32011 // La transacción pedida no existe
+	CodeForbidden     = -32012 // Origen de navegador no permitido (CORS)
+	CodeLimitExceeded = -32013 // Demasiadas peticiones o cuerpo demasiado grande
+)
+
+// SchemaHeader es la cabecera con la que toda respuesta indica la versión del
+// JSON de bloques y transacciones (blockchain.APISchemaVersion): un cliente
+// puede comprobarla antes de interpretar la respuesta
+const SchemaHeader = "X-Minichain-Schema"
+
+// Error es un error de JSON-RPC: se devuelve tal cual al cliente
+// La API REST responde sus errores con el mismo objeto (ver writeError)
+type Error struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// invalidParams crea un error de parámetros con el mensaje key del catálogo
+// (en el idioma del nodo) y sus argumentos
+func invalidParams(key string, args ...interface{}) *Error {
+	return &Error{Code: CodeInvalidParams, Message: i18n.T(key, args...)}
+}
+
+// request es una petición JSON-RPC 2.0
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"` // Sin id es una notificación: no lleva respuesta
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+// response es la respuesta a una petición (Result o Error, nunca los dos)
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// handler atiende un método con sus parámetros posicionales
+type handler func(s *Server, params []json.RawMessage) (interface{}, error)
+
+// Server expone una blockchain por HTTP: JSON-RPC 2.0 en POST / y una API
+// REST de solo lectura bajo /api/
+//
+// Los métodos siguen el espacio de nombres eth_* de Ethereum para que las
+// herramientas habituales (curl, ethers.js) funcionen sin adaptar. La cadena no
+// es segura para usarla desde varias goroutines, así que las peticiones se
+// atienden de una en una.
+type Server struct {
+	// Orígenes desde los que un navegador puede llamar al servidor ("*" = todos)
+	// Vacío: ninguno (las peticiones que no vienen de un navegador no se ven afectadas)
+	CORSDomains []string
+
+	// Token que exigen los métodos que cambian el estado ("" = sin autenticación)
+	// Se envía como "Authorization: Bearer TOKEN" o "X-API-Key: TOKEN"
+	AuthToken string
+
+	MaxBodyBytes int64   // Tamaño máximo del cuerpo de una petición (0 = sin límite)
+	MaxBatchSize int     // Peticiones máximas en un lote JSON-RPC (0 = sin límite)
+	RateLimit    float64 // Peticiones por segundo por IP (0 = sin límite)
+	RateBurst    int     // Peticiones seguidas que se permiten a una IP
+
+	MaxSyncLag int // Bloques por detrás del más alto conocido con los que /health da 503
+
+	MineInterval   time.Duration // Cada cuánto mira el minero el mempool (0 = DefaultMineInterval)
+	ExpireInterval time.Duration // Cada cuánto se buscan pendientes caducadas (0 = DefaultExpireInterval)
+
+	bc      *blockchain.Blockchain
+	mu      sync.Mutex // Serializa el acceso a bc
+	mux     *http.ServeMux
+	limiter rateLimiter
+
+	minerMu    sync.Mutex
+	stopMining chan struct{} // nil si el minero está parado
+}
+
+// NewServer crea un servidor para bc
+func NewServer(bc *blockchain.Blockchain) *Server {
+	s := &Server{
+		MaxBodyBytes: DefaultMaxBodyBytes,
+		MaxBatchSize: DefaultMaxBatchSize,
+		RateLimit:    DefaultRateLimit,
+		RateBurst:    DefaultRateBurst,
+		MaxSyncLag:   DefaultMaxSyncLag,
+		bc:           bc,
+		mux:          http.NewServeMux(),
+	}
+	s.mux.HandleFunc("/", s.serveRPC)
+	s.mux.HandleFunc("/api/tx/", s.locked(s.handleTx))
+	s.mux.HandleFunc("/api/mempool", s.locked(s.handleMempool))
+	s.mux.HandleFunc("/api/block/", s.locked(s.handleBlock))
+	s.mux.HandleFunc("/api/sync", s.handleSync)
+	s.mux.HandleFunc("/health", s.handleHealth)
+	s.mux.HandleFunc("/health/live", s.handleLive)
+	return s
+}
+
+// ServeHTTP marca la versión del esquema, aplica CORS y los límites y reparte
+// la petición entre JSON-RPC y la API REST
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(SchemaHeader, blockchain.APISchemaVersion)
+	if !s.cors(w, r) || !s.limit(w, r) {
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// locked ejecuta un handler HTTP con el candado de la cadena tomado
+func (s *Server) locked(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		h(w, r)
+	}
+}
+
+// serveRPC atiende una petición o un lote de peticiones (un array JSON)
+func (s *Server) serveRPC(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		notFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, http.MethodPost)
+		return
+	}
+
+	authorized := s.authorized(r)
+
+	var body json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, &Error{Code: CodeLimitExceeded,
+				Message: i18n.T("rpc.bodyTooLarge", tooLarge.Limit)})
+			return
+		}
+		writeJSON(w, &response{JSONRPC: "2.0", ID: json.RawMessage("null"),
+			Error: &Error{Code: CodeParseError, Message: i18n.T("rpc.invalidJSON", err)}})
+		return
+	}
+
+	// Lote: se responde con un array, sin las notificaciones
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(body, &batch); err != nil || len(batch) == 0 {
+			writeJSON(w, &response{JSONRPC: "2.0", ID: json.RawMessage("null"),
+				Error: &Error{Code: CodeInvalidRequest, Message: i18n.T("rpc.invalidBatch")}})
+			return
+		}
+		if s.MaxBatchSize > 0 && len(batch) > s.MaxBatchSize {
+			writeJSON(w, &response{JSONRPC: "2.0", ID: json.RawMessage("null"),
+				Error: &Error{Code: CodeInvalidRequest, Message: i18n.T(
+					"rpc.batchTooLarge", len(batch), s.MaxBatchSize)}})
+			return
+		}
+
+		responses := []*response{}
+		for _, raw := range batch {
+			if resp := s.handle(raw, authorized); resp != nil {
+				responses = append(responses, resp)
+			}
+		}
+		if len(responses) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSON(w, responses)
+		return
+	}
+
+	resp := s.handle(body, authorized)
+	if resp == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, resp)
+}
+
+// handle ejecuta una petición (nil si es una notificación)
+// authorized dice si la petición HTTP traía el token (ver AuthToken)
+func (s *Server) handle(raw json.RawMessage, authorized bool) *response {
+	var req request
+	if err := json.Unmarshal(raw, &req); err != nil || req.JSONRPC != "2.0" || req.Method == "" {
+		return &response{JSONRPC: "2.0", ID: json.RawMessage("null"),
+			Error: &Error{Code: CodeInvalidRequest, Message: i18n.T("rpc.invalidRequest")}}
+	}
+
+	result, err := s.call(req.Method, req.Params, authorized)
+	if len(req.ID) == 0 {
+		return nil
+	}
+
+	resp := &response{JSONRPC: "2.0", ID: req.ID}
+	if err != nil {
+		resp.Error = toError(err)
+		return resp
+	}
+
+	resp.Result = result
+	if result == nil {
+		resp.Result = json.RawMessage("null") // "result" es obligatorio aunque no haya nada
+	}
+	return resp
+}
+
+// call busca el método y lo ejecuta con el candado de la cadena tomado (salvo
+// los de unlocked)
+func (s *Server) call(method string, rawParams json.RawMessage, authorized bool) (interface{}, error) {
+	h, exists := methods[method]
+	if !exists {
+		return nil, &Error{Code: CodeMethodNotFound, Message: i18n.T("rpc.unknownMethod", method)}
+	}
+	if mutating[method] && !authorized {
+		return nil, &Error{Code: CodeUnauthorized, Message: i18n.T("rpc.authRequired", method)}
+	}
+
+	var params []json.RawMessage
+	if len(rawParams) > 0 && string(rawParams) != "null" {
+		if err := json.Unmarshal(rawParams, &params); err != nil {
+			return nil, invalidParams("rpc.paramsNotArray")
+		}
+	}
+
+	if !unlocked[method] {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+
+	return h(s, params)
+}
+
+// writeJSON escribe v como respuesta JSON
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// decodeParams decodifica los parámetros posicionales en targets
+// Los primeros required son obligatorios; los demás, si faltan, se dejan como están
+func decodeParams(params []json.RawMessage, required int, targets ...interface{}) error {
+	if len(params) < required {
+		return invalidParams("rpc.tooFewParams", required, len(params))
+	}
+	if len(params) > len(targets) {
+		return invalidParams("rpc.tooManyParams", len(targets), len(params))
+	}
+
+	for i, param := range params {
+		if err := json.Unmarshal(param, targets[i]); err != nil {
+			return invalidParams("rpc.invalidParam", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package rpc
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// ImportChain importa un archivo exportado con el candado de la cadena tomado
+//
+// Pensado para llamarse en otra goroutine con el servidor ya escuchando: las
+// peticiones que leen la cadena esperan a que termine, pero /api/sync y
+// eth_syncing responden durante la importación.
+func (s *Server) ImportChain(r io.Reader) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bc.ImportChain(r)
+}
+
+// syncJSON es la respuesta de /api/sync
+type syncJSON struct {
+	Syncing         bool    `json:"syncing"`
+	StartingBlock   int     `json:"startingBlock"`
+	CurrentBlock    int     `json:"currentBlock"`
+	HighestBlock    int     `json:"highestBlock"` // Sin red P2P: el último del archivo que se importa
+	BlocksPerSecond float64 `json:"blocksPerSecond"`
+}
+
+// handleSync: GET /api/sync -> estado de la importación de bloques
+// No toma el candado de la cadena (ver blockchain.SyncProgress)
+func (s *Server) handleSync(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	progress := s.bc.SyncProgress()
+	writeJSON(w, &syncJSON{
+		Syncing:         progress.Syncing,
+		StartingBlock:   progress.StartingBlock,
+		CurrentBlock:    progress.CurrentBlock,
+		HighestBlock:    progress.HighestBlock,
+		BlocksPerSecond: progress.BlocksPerSecond,
+	})
+}
+
+// ethSyncingJSON es el progreso de eth_syncing mientras se importa
+type ethSyncingJSON struct {
+	StartingBlock string `json:"startingBlock"`
+	CurrentBlock  string `json:"currentBlock"`
+	HighestBlock  string `json:"highestBlock"`
+}
+
+// ethSyncing: [] -> false, o el progreso si hay una importación en curso
+func ethSyncing(s *Server, params []json.RawMessage) (interface{}, error) {
+	progress := s.bc.SyncProgress()
+	if !progress.Syncing {
+		return false, nil
+	}
+	return &ethSyncingJSON{
+		StartingBlock: hexUint(uint64(progress.StartingBlock)),
+		CurrentBlock:  hexUint(uint64(progress.CurrentBlock)),
+		HighestBlock:  hexUint(uint64(progress.HighestBlock)),
+	}, nil
+}
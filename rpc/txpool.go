@@ -0,0 +1,83 @@
+package rpc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"minichain/mempool"
+	"strconv"
+	"strings"
+)
+
+// txpoolReserveNonce: [dirección] -> nonce reservado para esa cuenta
+//
+// Para varios procesos que firman con la misma cuenta: cada llamada devuelve un
+// nonce distinto aunque las transacciones anteriores aún no hayan llegado al
+// nodo. La reserva caduca a los blockchain.NonceReservationTTL si no se usa;
+// txpool_releaseNonce la devuelve antes.
+func txpoolReserveNonce(s *Server, params []json.RawMessage) (interface{}, error) {
+	var address string
+	if err := decodeParams(params, 1, &address); err != nil {
+		return nil, err
+	}
+	account, err := parseAccount(address)
+	if err != nil {
+		return nil, err
+	}
+	return hexUint(uint64(s.bc.ReserveNonce(account))), nil
+}
+
+// txpoolReleaseNonce: [dirección, nonce] -> true si estaba reservado
+func txpoolReleaseNonce(s *Server, params []json.RawMessage) (interface{}, error) {
+	var address, quantity string
+	if err := decodeParams(params, 2, &address, &quantity); err != nil {
+		return nil, err
+	}
+	account, err := parseAccount(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(quantity, "0x") {
+		return nil, invalidParams("rpc.nonceFormat", quantity)
+	}
+	nonce, err := strconv.ParseUint(quantity[2:], 16, 31)
+	if err != nil {
+		return nil, invalidParams("rpc.invalidNonce", quantity)
+	}
+	return s.bc.ReleaseNonce(account, int(nonce)), nil
+}
+
+// txpoolDropped: [remitente] -> transacciones que salieron del mempool sin
+// minarse, de la más reciente a la más antigua
+// Sin remitente (o null) devuelve las de todos. Cada una dice por qué se
+// descartó (caducada, inválida, desplazada): la cartera sabe que tiene que
+// reenviarla.
+func txpoolDropped(s *Server, params []json.RawMessage) (interface{}, error) {
+	var sender *string
+	if err := decodeParams(params, 0, &sender); err != nil {
+		return nil, err
+	}
+
+	account := ""
+	if sender != nil {
+		var err error
+		if account, err = parseAccount(*sender); err != nil {
+			return nil, err
+		}
+	}
+
+	dropped := s.bc.Mempool.Dropped(account)
+	if dropped == nil {
+		dropped = []mempool.Dropped{} // [] en lugar de null
+	}
+	return dropped, nil
+}
+
+// parseAccount normaliza una dirección que tiene que ser válida (40 caracteres hex)
+func parseAccount(address string) (string, error) {
+	account := parseAddress(address)
+	if _, err := hex.DecodeString(account); err != nil || len(account) != 40 {
+		return "", invalidParams("rpc.invalidAddress", address)
+	}
+	return account, nil
+}
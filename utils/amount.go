@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Decimals es cuántos decimales tiene el MTC: 1 MTC = 10^18 unidades base
+// Todas las cantidades (saldos, montos, gas, comisiones) se guardan en unidades
+// base como *big.Int, así que la aritmética es exacta
+const Decimals = 18
+
+// unitsPerMTC son las unidades base de 1 MTC
+var unitsPerMTC = new(big.Int).Exp(big.NewInt(10), big.NewInt(Decimals), nil)
+
+// MTC convierte una cantidad entera de MTC a unidades base
+func MTC(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), unitsPerMTC)
+}
+
+// ParseMTC lee una cantidad en MTC escrita con decimales ("1.5", "0.000021")
+// y la convierte a unidades base. No admite negativos ni más de 18 decimales.
+func ParseMTC(s string) (*big.Int, error) {
+	s = strings.TrimSpace(s)
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" {
+		return nil, fmt.Errorf("cantidad vacía")
+	}
+	if len(frac) > Decimals {
+		return nil, fmt.Errorf("demasiados decimales en %q (máximo %d)", s, Decimals)
+	}
+
+	digits := whole + frac + strings.Repeat("0", Decimals-len(frac))
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return nil, fmt.Errorf("cantidad inválida: %q", s)
+		}
+	}
+
+	units, _ := new(big.Int).SetString(digits, 10)
+	return units, nil
+}
+
+// FormatMTC escribe una cantidad en unidades base como MTC
+// Muestra al menos 2 decimales y los necesarios para no perder precisión
+func FormatMTC(units *big.Int) string {
+	if units == nil {
+		return "0.00"
+	}
+
+	sign := ""
+	abs := new(big.Int).Set(units)
+	if abs.Sign() < 0 {
+		sign = "-"
+		abs.Neg(abs)
+	}
+
+	whole, frac := new(big.Int).QuoRem(abs, unitsPerMTC, new(big.Int))
+	decimals := fmt.Sprintf("%0*s", Decimals, frac.String())
+	decimals = strings.TrimRight(decimals, "0")
+	for len(decimals) < 2 {
+		decimals += "0"
+	}
+
+	return sign + whole.String() + "." + decimals
+}
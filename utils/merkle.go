@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// EmptyRoot es la raíz de un árbol sin hojas (32 bytes a cero)
+var EmptyRoot = hex.EncodeToString(make([]byte, 32))
+
+// MerkleRoot calcula la raíz de un árbol de Merkle binario sobre las hojas
+//
+// Cada hoja se hashea con SHA-256 y cada nivel combina los hashes por parejas.
+// Si un nivel tiene un número impar de nodos, el último se empareja consigo mismo
+// (como en Bitcoin). Cambiar cualquier hoja, o su orden, cambia la raíz.
+func MerkleRoot(leaves [][]byte) string {
+	if len(leaves) == 0 {
+		return EmptyRoot
+	}
+
+	level := make([][]byte, len(leaves))
+	for i, leaf := range leaves {
+		hash := sha256.Sum256(leaf)
+		level[i] = hash[:]
+	}
+
+	for len(level) > 1 {
+		level = nextMerkleLevel(level)
+	}
+
+	return hex.EncodeToString(level[0])
+}
+
+// nextMerkleLevel combina los nodos de un nivel por parejas
+func nextMerkleLevel(level [][]byte) [][]byte {
+	var next [][]byte
+	for i := 0; i < len(level); i += 2 {
+		left := level[i]
+		right := left
+		if i+1 < len(level) {
+			right = level[i+1]
+		}
+		hash := sha256.Sum256(append(append([]byte{}, left...), right...))
+		next = append(next, hash[:])
+	}
+	return next
+}
+
+// MerkleProof devuelve la prueba de inclusión de la hoja index: el hash hermano
+// de cada nivel, de abajo arriba (ver VerifyMerkleProof)
+func MerkleProof(leaves [][]byte, index int) [][]byte {
+	if index < 0 || index >= len(leaves) {
+		return nil
+	}
+
+	level := make([][]byte, len(leaves))
+	for i, leaf := range leaves {
+		hash := sha256.Sum256(leaf)
+		level[i] = hash[:]
+	}
+
+	var proof [][]byte
+	for len(level) > 1 {
+		sibling := index ^ 1
+		if sibling >= len(level) {
+			sibling = index // Nodo impar: se empareja consigo mismo
+		}
+		proof = append(proof, level[sibling])
+		level = nextMerkleLevel(level)
+		index /= 2
+	}
+	return proof
+}
+
+// VerifyMerkleProof comprueba que leaf es la hoja index del árbol con raíz root
+// Solo necesita la hoja y los hermanos, no el resto de hojas
+func VerifyMerkleProof(root string, leaf []byte, index int, proof [][]byte) bool {
+	if index < 0 {
+		return false
+	}
+
+	hash := sha256.Sum256(leaf)
+	node := hash[:]
+	for _, sibling := range proof {
+		if index%2 == 0 {
+			hash = sha256.Sum256(append(append([]byte{}, node...), sibling...))
+		} else {
+			hash = sha256.Sum256(append(append([]byte{}, sibling...), node...))
+		}
+		node = hash[:]
+		index /= 2
+	}
+	return index == 0 && hex.EncodeToString(node) == root
+}
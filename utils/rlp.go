@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// RLPEncode codifica un valor con RLP (Recursive Length Prefix), el formato de Ethereum
+//
+// Admite []byte, string, enteros sin signo (uint64, int >= 0, *big.Int >= 0) y
+// listas ([]interface{}) de cualquiera de ellos. Los enteros se codifican en
+// big-endian sin ceros a la izquierda (el 0 es la cadena vacía).
+func RLPEncode(item interface{}) []byte {
+	switch v := item.(type) {
+	case []byte:
+		return rlpEncodeBytes(v)
+	case string:
+		return rlpEncodeBytes([]byte(v))
+	case uint64:
+		return rlpEncodeBytes(new(big.Int).SetUint64(v).Bytes())
+	case int:
+		if v < 0 {
+			panic(fmt.Sprintf("RLP: entero negativo %d", v))
+		}
+		return rlpEncodeBytes(big.NewInt(int64(v)).Bytes())
+	case *big.Int:
+		if v == nil {
+			return rlpEncodeBytes(nil)
+		}
+		if v.Sign() < 0 {
+			panic(fmt.Sprintf("RLP: entero negativo %s", v))
+		}
+		return rlpEncodeBytes(v.Bytes())
+	case []interface{}:
+		var payload []byte
+		for _, element := range v {
+			payload = append(payload, RLPEncode(element)...)
+		}
+		return append(rlpHeader(0xc0, len(payload)), payload...)
+	default:
+		panic(fmt.Sprintf("RLP: tipo no soportado %T", item))
+	}
+}
+
+// rlpEncodeBytes codifica una cadena de bytes
+// Un único byte menor que 0x80 se codifica como él mismo
+func rlpEncodeBytes(data []byte) []byte {
+	if len(data) == 1 && data[0] < 0x80 {
+		return []byte{data[0]}
+	}
+	return append(rlpHeader(0x80, len(data)), data...)
+}
+
+// rlpHeader es el prefijo de longitud (offset 0x80 para cadenas, 0xc0 para listas)
+func rlpHeader(offset byte, length int) []byte {
+	if length < 56 {
+		return []byte{offset + byte(length)}
+	}
+	size := big.NewInt(int64(length)).Bytes()
+	return append([]byte{offset + 55 + byte(len(size))}, size...)
+}
+
+// RLPDecode decodifica un valor RLP completo
+//
+// Devuelve []byte para las cadenas y []interface{} para las listas (con los
+// elementos decodificados igual). Solo acepta la codificación canónica y exige
+// que no sobren bytes, así que cada valor tiene una única representación.
+func RLPDecode(data []byte) (interface{}, error) {
+	item, rest, err := rlpDecodeItem(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("RLP: %d bytes sobrantes", len(rest))
+	}
+	return item, nil
+}
+
+// rlpDecodeItem decodifica el primer valor de data y devuelve lo que queda detrás
+func rlpDecodeItem(data []byte) (interface{}, []byte, error) {
+	if len(data) == 0 {
+		return nil, nil, fmt.Errorf("RLP: datos vacíos")
+	}
+
+	prefix := data[0]
+	switch {
+	case prefix < 0x80:
+		return []byte{prefix}, data[1:], nil
+
+	case prefix < 0xc0:
+		content, rest, err := rlpSplit(data, 0x80)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(content) == 1 && content[0] < 0x80 {
+			return nil, nil, fmt.Errorf("RLP: byte %#x codificado como cadena", content[0])
+		}
+		return content, rest, nil
+
+	default:
+		content, rest, err := rlpSplit(data, 0xc0)
+		if err != nil {
+			return nil, nil, err
+		}
+		list := []interface{}{}
+		for len(content) > 0 {
+			var element interface{}
+			element, content, err = rlpDecodeItem(content)
+			if err != nil {
+				return nil, nil, err
+			}
+			list = append(list, element)
+		}
+		return list, rest, nil
+	}
+}
+
+// rlpSplit separa el contenido de una cadena o lista (según offset) del resto
+func rlpSplit(data []byte, offset byte) ([]byte, []byte, error) {
+	length := int(data[0] - offset)
+	header := 1
+
+	if length > 55 {
+		// Forma larga: el prefijo dice cuántos bytes ocupa la longitud
+		size := length - 55
+		if len(data) < 1+size {
+			return nil, nil, fmt.Errorf("RLP: longitud truncada")
+		}
+		if data[1] == 0 {
+			return nil, nil, fmt.Errorf("RLP: longitud con ceros a la izquierda")
+		}
+		if size > 4 {
+			return nil, nil, fmt.Errorf("RLP: longitud demasiado grande")
+		}
+		length = int(new(big.Int).SetBytes(data[1 : 1+size]).Int64())
+		if length < 56 {
+			return nil, nil, fmt.Errorf("RLP: longitud %d en forma larga", length)
+		}
+		header += size
+	}
+
+	if len(data) < header+length {
+		return nil, nil, fmt.Errorf("RLP: faltan %d bytes", header+length-len(data))
+	}
+	return data[header : header+length], data[header+length:], nil
+}
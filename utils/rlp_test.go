@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRLPRoundTrip(t *testing.T) {
+	long := strings.Repeat("x", 56) // Primera longitud en forma larga
+	cases := []struct {
+		name  string
+		item  interface{}
+		want  interface{} // Lo que devuelve RLPDecode
+		bytes []byte      // Codificación esperada (nil = no se comprueba)
+	}{
+		{"cadena vacía", "", []byte{}, []byte{0x80}},
+		{"byte bajo", []byte{0x05}, []byte{0x05}, []byte{0x05}},
+		{"byte alto", []byte{0x80}, []byte{0x80}, []byte{0x81, 0x80}},
+		{"cero", 0, []byte{}, []byte{0x80}},
+		{"entero", uint64(1024), []byte{0x04, 0x00}, []byte{0x82, 0x04, 0x00}},
+		{"big.Int", new(big.Int).Lsh(big.NewInt(1), 64), []byte{1, 0, 0, 0, 0, 0, 0, 0, 0}, nil},
+		{"55 bytes", long[:55], []byte(long[:55]), append([]byte{0xb7}, long[:55]...)},
+		{"56 bytes", long, []byte(long), append([]byte{0xb8, 56}, long...)},
+		{"lista vacía", []interface{}{}, []interface{}{}, []byte{0xc0}},
+		{
+			"listas anidadas",
+			[]interface{}{"cat", []interface{}{1, []interface{}{}}, long},
+			[]interface{}{[]byte("cat"), []interface{}{[]byte{1}, []interface{}{}}, []byte(long)},
+			nil,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			encoded := RLPEncode(c.item)
+			if c.bytes != nil && !bytes.Equal(encoded, c.bytes) {
+				t.Fatalf("RLPEncode = %x, esperaba %x", encoded, c.bytes)
+			}
+			decoded, err := RLPDecode(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(decoded, c.want) {
+				t.Fatalf("RLPDecode = %#v, esperaba %#v", decoded, c.want)
+			}
+		})
+	}
+}
+
+// TestRLPDecodeMalformed: solo se acepta la codificación canónica y completa
+func TestRLPDecodeMalformed(t *testing.T) {
+	cases := map[string][]byte{
+		"vacío":                       {},
+		"byte bajo como cadena":       {0x81, 0x05},
+		"cadena truncada":             {0x83, 'c', 'a'},
+		"lista truncada":              {0xc3, 0x01, 0x02},
+		"elemento truncado en lista":  {0xc2, 0x82, 0x01},
+		"bytes sobrantes":             {0x01, 0x02},
+		"forma larga innecesaria":     {0xb8, 0x05, 1, 2, 3, 4, 5},
+		"longitud con cero delante":   {0xb9, 0x00, 0x40},
+		"longitud de la longitud":     {0xb9, 0x01},
+		"longitud de más de 4 bytes":  {0xbc, 1, 0, 0, 0, 0},
+		"lista con forma larga corta": {0xf8, 0x01, 0x01},
+	}
+	for name, data := range cases {
+		t.Run(name, func(t *testing.T) {
+			if decoded, err := RLPDecode(data); err == nil {
+				t.Fatalf("RLPDecode(%x) = %#v, esperaba error", data, decoded)
+			}
+		})
+	}
+}
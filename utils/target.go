@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"encoding/hex"
+	"math/big"
+)
+
+// MaxTarget es el objetivo más fácil posible: cualquier hash de 256 bits lo cumple
+var MaxTarget = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// MaxDifficulty es la dificultad antigua más alta con objetivo distinto de 0:
+// con 64 ceros hex el único hash válido sería el 0
+const MaxDifficulty = 63
+
+// TargetFromDifficulty convierte la dificultad antigua (N ceros hex al inicio)
+// al objetivo numérico equivalente: hash <= 2^(256-4N) - 1
+// Fuera de 1..MaxDifficulty se usa el extremo más cercano
+func TargetFromDifficulty(difficulty int) *big.Int {
+	if difficulty <= 0 {
+		return new(big.Int).Set(MaxTarget)
+	}
+	difficulty = min(difficulty, MaxDifficulty)
+	target := new(big.Int).Lsh(big.NewInt(1), uint(256-4*difficulty))
+	return target.Sub(target, big.NewInt(1))
+}
+
+// CompactToTarget decodifica el formato compacto "bits" (como Bitcoin)
+// El byte alto es el exponente (tamaño en bytes) y los otros 3 la mantisa
+func CompactToTarget(bits uint32) *big.Int {
+	exponent := uint(bits >> 24)
+	mantissa := big.NewInt(int64(bits & 0x007fffff))
+
+	if exponent <= 3 {
+		return mantissa.Rsh(mantissa, 8*(3-exponent))
+	}
+	return mantissa.Lsh(mantissa, 8*(exponent-3))
+}
+
+// TargetToCompact codifica un objetivo en formato compacto
+// Se pierde precisión: solo se guardan los 3 bytes más significativos
+func TargetToCompact(target *big.Int) uint32 {
+	size := uint32(len(target.Bytes()))
+
+	var mantissa uint32
+	if size <= 3 {
+		mantissa = uint32(target.Uint64()) << (8 * (3 - size))
+	} else {
+		mantissa = uint32(new(big.Int).Rsh(target, 8*uint(size-3)).Uint64())
+	}
+
+	// Si el bit de signo de la mantisa está activo, desplazamos un byte
+	if mantissa&0x00800000 != 0 {
+		mantissa >>= 8
+		size++
+	}
+
+	return size<<24 | mantissa
+}
+
+// MeetsNumericTarget verifica si un hash (hex) es menor o igual que el objetivo
+func MeetsNumericTarget(hash string, target *big.Int) bool {
+	hashBytes, err := hex.DecodeString(hash)
+	if err != nil || len(hashBytes) != 32 {
+		return false
+	}
+	return new(big.Int).SetBytes(hashBytes).Cmp(target) <= 0
+}
+
+// TargetDifficulty expresa un objetivo como "veces más difícil que el máximo"
+// Sirve para mostrar la dificultad de forma legible
+func TargetDifficulty(bits uint32) float64 {
+	target := CompactToTarget(bits)
+	if target.Sign() == 0 {
+		return 0
+	}
+	ratio, _ := new(big.Float).Quo(new(big.Float).SetInt(MaxTarget), new(big.Float).SetInt(target)).Float64()
+	return ratio
+}